@@ -1,22 +1,89 @@
 package api
 
 import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/color-game/api/colorapi"
 	"github.com/color-game/api/datastore"
+	"github.com/color-game/api/models"
+	"github.com/color-game/api/scheduler"
 )
 
+// DefaultJwtSecret is the placeholder secret used when JWT_SECRET is unset. It must never reach production.
+const DefaultJwtSecret = "your-secret-key-change-this"
+
+// MinJwtSecretLength is the shortest JWT secret accepted outside of dev mode
+const MinJwtSecretLength = 32
+
+// MaxColorCandidates caps COLOR_CANDIDATES, since every candidate is a color API call
+const MaxColorCandidates = 10
+
 type Config struct {
-	HTTPPort           string
-	DatabaseType       string
-	DatabaseUser       string
-	DatabasePassword   string
-	DatabaseName       string
-	SSLMode            string
-	JwtSecret          string
-	JwtAccessDuration  int // seconds
-	JwtRefreshDuration int // seconds
-	JwtDomain          string
-	AllowedOrigins     []string
-	DevMode            bool
+	HTTPPort            string
+	DatabaseType        string
+	DatabaseUser        string
+	DatabasePassword    string
+	DatabaseName        string
+	SSLMode             string
+	JwtSecret           string
+	JwtAccessDuration   int // seconds
+	JwtRefreshDuration  int // seconds
+	JwtDomain           string
+	JwtIssuer           string
+	JwtAudience         string
+	AllowedOrigins      []string
+	AllowedMethods      []string // CORS Access-Control-Allow-Methods
+	AllowedHeaders      []string // CORS Access-Control-Allow-Headers
+	DevMode             bool
+	SignupEnabled       bool
+	SignupInviteOnly    bool // signup requires an unused invite code
+	ColorAPIBaseURL     string
+	ColorSchemeMode     string
+	ColorSchemeCount    int
+	ColorSchemeRotation []string // modes cycled by day of week, Sunday first; empty uses ColorSchemeMode
+	ColorCandidates     int      // random colors sampled per daily color, keeping the best-named
+	LeaderboardMaxLimit int
+	ScoreRetentionDays  int
+	MaxFriends          int
+	// Friend requests a user may have pending from today before further requests get a 429; 0 disables
+	MaxDailyFriendRequests int
+	// Derive each daily color from its date instead of calling the color API
+	DeterministicDailyColor bool
+	// Days of history the color archive returns when no from date is given
+	DailyColorArchiveDays int
+	// Minutes after midnight that yesterday's color still accepts guesses sent with its date; 0 disables
+	ScoreGraceMinutes int
+	// Days back from deactivation that a purchase still qualifies for a deactivation refund
+	DeactivationRefundWindowDays int
+	// Minimum credit cost per extra attempt an extra_attempt powerup may grant
+	ExtraAttemptCreditCost int
+	// Credits and points every new account starts with
+	SignupCreditBonus int
+	SignupPointBonus  int
+	// Points and credits awarded per point of the day's best score
+	PointsPerScorePoint  float64
+	CreditsPerScorePoint float64
+	// Points needed to clear each level in turn; the last entry repeats. Empty means a flat 1000.
+	LevelCurve []int
+	// Levels at which the base daily attempts grow by one each, ascending. Empty keeps attempts flat.
+	LevelAttemptBonus []int
+	// Strength rules for new passwords
+	PasswordPolicy models.PasswordPolicy
+	// Pause gameplay at startup; the admin maintenance endpoint overrides it at runtime
+	MaintenanceMode bool
+	// Wrap every list response as {"data": [...], "total": N}; off keeps the legacy shapes
+	ResponseEnvelope bool
+	// Requests served at once before new ones get a 503; 0 disables the limit
+	MaxConcurrentRequests int
+	// Seconds a request may take before it is answered with a 503; 0 disables
+	RequestTimeout int
+	// HTTP server timeouts, in seconds
+	ServerReadTimeout       int
+	ServerReadHeaderTimeout int
+	ServerWriteTimeout      int
+	ServerIdleTimeout       int
 }
 
 type Application struct {
@@ -27,4 +94,150 @@ type Application struct {
 	DailyLeaderboardRepo datastore.DailyLeaderboardRepository
 	ShopRepo             datastore.ShopRepository
 	FriendRepo           datastore.FriendRepository
+	RewardEventRepo      datastore.RewardEventRepository
+	TradeRepo            datastore.TradeRepository
+	InviteCodeRepo       datastore.InviteCodeRepository
+	CuratedColorRepo     datastore.CuratedColorRepository
+	AuditLogRepo         datastore.AuditLogRepository
+	SettingsRepo         datastore.SettingsRepository
+	Scheduler            *scheduler.Scheduler
+	ColorAPI             colorapi.Service
+}
+
+// Validate reports every configuration problem that should stop the server from starting
+func (c Config) Validate() []error {
+	var problems []error
+
+	if c.HTTPPort == "" {
+		problems = append(problems, errors.New("HTTP_PORT must be set"))
+	}
+	if c.DatabaseType == "" {
+		problems = append(problems, errors.New("DB_TYPE must be set"))
+	}
+	if c.DatabaseUser == "" {
+		problems = append(problems, errors.New("DB_USER must be set"))
+	}
+	if c.DatabaseName == "" {
+		problems = append(problems, errors.New("DB_NAME must be set"))
+	}
+
+	if c.JwtSecret == "" {
+		problems = append(problems, errors.New("JWT_SECRET must be set"))
+	} else if !c.DevMode {
+		if c.JwtSecret == DefaultJwtSecret {
+			problems = append(problems, errors.New("JWT_SECRET is the default placeholder; set a real secret or enable DEV_MODE"))
+		} else if len(c.JwtSecret) < MinJwtSecretLength {
+			problems = append(problems, fmt.Errorf("JWT_SECRET must be at least %d bytes outside DEV_MODE, got %d", MinJwtSecretLength, len(c.JwtSecret)))
+		}
+	}
+	if c.JwtAccessDuration <= 0 {
+		problems = append(problems, fmt.Errorf("JWT_ACCESS_DURATION must be positive, got %d", c.JwtAccessDuration))
+	}
+	if c.JwtRefreshDuration <= 0 {
+		problems = append(problems, fmt.Errorf("JWT_REFRESH_DURATION must be positive, got %d", c.JwtRefreshDuration))
+	}
+
+	if len(c.AllowedMethods) == 0 {
+		problems = append(problems, errors.New("ALLOWED_METHODS must list at least one method"))
+	}
+	for _, method := range c.AllowedMethods {
+		if method = strings.TrimSpace(method); !isHTTPToken(method) || method != strings.ToUpper(method) {
+			problems = append(problems, fmt.Errorf("ALLOWED_METHODS contains invalid method %q", method))
+		}
+	}
+	for _, header := range c.AllowedHeaders {
+		if header = strings.TrimSpace(header); !isHTTPToken(header) {
+			problems = append(problems, fmt.Errorf("ALLOWED_HEADERS contains invalid header name %q", header))
+		}
+	}
+
+	for _, mode := range c.ColorSchemeRotation {
+		if !colorapi.IsValidMode(mode) {
+			problems = append(problems, fmt.Errorf("COLOR_SCHEME_ROTATION contains invalid mode %q", mode))
+		}
+	}
+	if c.ColorCandidates < 1 || c.ColorCandidates > MaxColorCandidates {
+		problems = append(problems, fmt.Errorf("COLOR_CANDIDATES must be between 1 and %d, got %d", MaxColorCandidates, c.ColorCandidates))
+	}
+
+	if c.PasswordPolicy.MinLength < 1 || c.PasswordPolicy.MinLength > models.MaxPasswordBytes {
+		problems = append(problems, fmt.Errorf("PASSWORD_MIN_LENGTH must be between 1 and %d, got %d", models.MaxPasswordBytes, c.PasswordPolicy.MinLength))
+	}
+
+	if c.DailyColorArchiveDays < 1 || c.DailyColorArchiveDays > maxDailyColorPageSize {
+		problems = append(problems, fmt.Errorf("DAILY_COLOR_ARCHIVE_DAYS must be between 1 and %d, got %d", maxDailyColorPageSize, c.DailyColorArchiveDays))
+	}
+	if c.LeaderboardMaxLimit <= 0 {
+		problems = append(problems, fmt.Errorf("LEADERBOARD_MAX_LIMIT must be positive, got %d", c.LeaderboardMaxLimit))
+	}
+	if c.ScoreGraceMinutes < 0 || c.ScoreGraceMinutes >= 24*60 {
+		problems = append(problems, fmt.Errorf("SCORE_GRACE_MINUTES must be between 0 and 1439, got %d", c.ScoreGraceMinutes))
+	}
+	if c.ScoreRetentionDays < 0 {
+		problems = append(problems, fmt.Errorf("SCORE_RETENTION_DAYS cannot be negative, got %d", c.ScoreRetentionDays))
+	}
+	if c.DeactivationRefundWindowDays < 0 {
+		problems = append(problems, fmt.Errorf("DEACTIVATION_REFUND_WINDOW_DAYS cannot be negative, got %d", c.DeactivationRefundWindowDays))
+	}
+	if c.ExtraAttemptCreditCost < 0 {
+		problems = append(problems, fmt.Errorf("EXTRA_ATTEMPT_CREDIT_COST cannot be negative, got %d", c.ExtraAttemptCreditCost))
+	}
+	if c.SignupCreditBonus < 0 {
+		problems = append(problems, fmt.Errorf("SIGNUP_CREDIT_BONUS cannot be negative, got %d", c.SignupCreditBonus))
+	}
+	if c.SignupPointBonus < 0 {
+		problems = append(problems, fmt.Errorf("SIGNUP_POINT_BONUS cannot be negative, got %d", c.SignupPointBonus))
+	}
+	if c.PointsPerScorePoint < 0 {
+		problems = append(problems, fmt.Errorf("POINTS_PER_SCORE_POINT cannot be negative, got %g", c.PointsPerScorePoint))
+	}
+	if c.CreditsPerScorePoint < 0 {
+		problems = append(problems, fmt.Errorf("CREDITS_PER_SCORE_POINT cannot be negative, got %g", c.CreditsPerScorePoint))
+	}
+	for i, cost := range c.LevelCurve {
+		if cost <= 0 {
+			problems = append(problems, fmt.Errorf("LEVEL_CURVE entries must be positive, got %d at position %d", cost, i+1))
+			break
+		}
+	}
+	for i, level := range c.LevelAttemptBonus {
+		if level <= 0 || (i > 0 && level <= c.LevelAttemptBonus[i-1]) {
+			problems = append(problems, fmt.Errorf("LEVEL_ATTEMPT_BONUS must be positive levels in ascending order, got %d at position %d", level, i+1))
+			break
+		}
+	}
+	if c.MaxFriends < 0 {
+		problems = append(problems, fmt.Errorf("MAX_FRIENDS cannot be negative, got %d", c.MaxFriends))
+	}
+	if c.MaxDailyFriendRequests < 0 {
+		problems = append(problems, fmt.Errorf("MAX_DAILY_FRIEND_REQUESTS cannot be negative, got %d", c.MaxDailyFriendRequests))
+	}
+	if c.MaxConcurrentRequests < 0 {
+		problems = append(problems, fmt.Errorf("MAX_CONCURRENT_REQUESTS cannot be negative, got %d", c.MaxConcurrentRequests))
+	}
+	if c.RequestTimeout < 0 {
+		problems = append(problems, fmt.Errorf("REQUEST_TIMEOUT cannot be negative, got %d", c.RequestTimeout))
+	} else if c.RequestTimeout > 0 && c.ServerWriteTimeout > 0 && c.RequestTimeout >= c.ServerWriteTimeout {
+		problems = append(problems, fmt.Errorf("REQUEST_TIMEOUT (%d) must be shorter than SERVER_WRITE_TIMEOUT (%d) or the 503 can't be written", c.RequestTimeout, c.ServerWriteTimeout))
+	}
+	if c.ServerReadTimeout < 0 || c.ServerReadHeaderTimeout < 0 || c.ServerWriteTimeout < 0 || c.ServerIdleTimeout < 0 {
+		problems = append(problems, errors.New("server timeouts cannot be negative"))
+	}
+
+	return problems
+}
+
+// Warnings reports unsafe settings that are tolerated in dev mode but would fail validation in production
+func (c Config) Warnings() []string {
+	var warnings []string
+
+	if c.DevMode {
+		if c.JwtSecret == DefaultJwtSecret {
+			warnings = append(warnings, "JWT_SECRET is the default placeholder; tokens can be forged by anyone. Never deploy this outside DEV_MODE")
+		} else if c.JwtSecret != "" && len(c.JwtSecret) < MinJwtSecretLength {
+			warnings = append(warnings, fmt.Sprintf("JWT_SECRET is shorter than %d bytes; this will be rejected outside DEV_MODE", MinJwtSecretLength))
+		}
+	}
+
+	return warnings
 }
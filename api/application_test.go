@@ -0,0 +1,122 @@
+package api
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/color-game/api/models"
+)
+
+// productionConfig returns a config that passes validation outside dev mode
+func productionConfig() Config {
+	return Config{
+		HTTPPort:                "8080",
+		DatabaseType:            "postgres",
+		DatabaseUser:            "colorgame",
+		DatabaseName:            "colorgame",
+		JwtSecret:               strings.Repeat("s", MinJwtSecretLength),
+		JwtAccessDuration:       900,
+		JwtRefreshDuration:      86400,
+		AllowedOrigins:          []string{"https://colorgame.example"},
+		AllowedMethods:          []string{"GET", "POST", "OPTIONS"},
+		AllowedHeaders:          []string{"Content-Type", "Authorization"},
+		ColorSchemeRotation:     []string{"monochrome", "analogic"},
+		ColorCandidates:         3,
+		PasswordPolicy:          models.PasswordPolicy{MinLength: 8},
+		DailyColorArchiveDays:   30,
+		LeaderboardMaxLimit:     100,
+		RequestTimeout:          10,
+		ServerReadTimeout:       15,
+		ServerReadHeaderTimeout: 5,
+		ServerWriteTimeout:      15,
+		ServerIdleTimeout:       60,
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(c *Config)
+		wantErr string // substring of the only expected problem; empty means valid
+	}{
+		{"production config is valid", func(c *Config) {}, ""},
+		{"empty JWT secret", func(c *Config) { c.JwtSecret = "" }, "JWT_SECRET must be set"},
+		{"empty JWT secret in dev mode", func(c *Config) { c.JwtSecret = ""; c.DevMode = true }, "JWT_SECRET must be set"},
+		{"default JWT secret in production", func(c *Config) { c.JwtSecret = DefaultJwtSecret }, "default placeholder"},
+		{"short JWT secret in production", func(c *Config) { c.JwtSecret = "short" }, "at least 32 bytes"},
+		{"default JWT secret in dev mode", func(c *Config) { c.JwtSecret = DefaultJwtSecret; c.DevMode = true }, ""},
+		{"short JWT secret in dev mode", func(c *Config) { c.JwtSecret = "short"; c.DevMode = true }, ""},
+		{"zero access duration", func(c *Config) { c.JwtAccessDuration = 0 }, "JWT_ACCESS_DURATION"},
+		{"negative request timeout", func(c *Config) { c.RequestTimeout = -1 }, "REQUEST_TIMEOUT cannot be negative"},
+		{"request timeout as long as the write timeout", func(c *Config) { c.RequestTimeout = 15 }, "must be shorter than SERVER_WRITE_TIMEOUT"},
+		{"request timeout with no write timeout", func(c *Config) { c.RequestTimeout = 60; c.ServerWriteTimeout = 0 }, ""},
+		{"request timeout disabled", func(c *Config) { c.RequestTimeout = 0 }, ""},
+		{"negative server timeout", func(c *Config) { c.ServerIdleTimeout = -1 }, "server timeouts cannot be negative"},
+		{"no CORS methods", func(c *Config) { c.AllowedMethods = nil }, "ALLOWED_METHODS must list at least one method"},
+		{"lowercase CORS method", func(c *Config) { c.AllowedMethods = []string{"GET", "post"} }, `invalid method "post"`},
+		{"CORS method with a space", func(c *Config) { c.AllowedMethods = []string{"GET POST"} }, `invalid method "GET POST"`},
+		{"CORS methods are trimmed", func(c *Config) { c.AllowedMethods = []string{" GET", "POST "} }, ""},
+		{"CORS header with a colon", func(c *Config) { c.AllowedHeaders = []string{"X-Token:"} }, `invalid header name "X-Token:"`},
+		{"empty CORS header", func(c *Config) { c.AllowedHeaders = []string{"Content-Type", ""} }, `invalid header name ""`},
+		{"no CORS headers", func(c *Config) { c.AllowedHeaders = nil }, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := productionConfig()
+			tt.modify(&c)
+			problems := c.Validate()
+
+			if tt.wantErr == "" {
+				if len(problems) != 0 {
+					t.Errorf("Validate() = %v, want no problems", problems)
+				}
+				return
+			}
+			if len(problems) != 1 || !strings.Contains(problems[0].Error(), tt.wantErr) {
+				t.Errorf("Validate() = %v, want one problem containing %q", problems, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfigValidateReportsEveryProblem(t *testing.T) {
+	if problems := (Config{}).Validate(); len(problems) < 5 {
+		t.Errorf("empty config gave %d problems, want every missing setting reported: %v", len(problems), problems)
+	}
+}
+
+func TestConfigWarnings(t *testing.T) {
+	tests := []struct {
+		name     string
+		modify   func(c *Config)
+		wantWarn string // substring of the only expected warning; empty means none
+	}{
+		{"production config", func(c *Config) {}, ""},
+		{"dev mode with a strong secret", func(c *Config) { c.DevMode = true }, ""},
+		{"dev mode with the default secret", func(c *Config) { c.DevMode = true; c.JwtSecret = DefaultJwtSecret }, "default placeholder"},
+		{"dev mode with a short secret", func(c *Config) { c.DevMode = true; c.JwtSecret = "short" }, "shorter than 32 bytes"},
+		{"dev mode with no secret", func(c *Config) { c.DevMode = true; c.JwtSecret = "" }, ""},
+		// Outside dev mode these are validation errors rather than warnings
+		{"default secret in production", func(c *Config) { c.JwtSecret = DefaultJwtSecret }, ""},
+		{"short secret in production", func(c *Config) { c.JwtSecret = "short" }, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := productionConfig()
+			tt.modify(&c)
+			warnings := c.Warnings()
+
+			if tt.wantWarn == "" {
+				if len(warnings) != 0 {
+					t.Errorf("Warnings() = %v, want none", warnings)
+				}
+				return
+			}
+			if len(warnings) != 1 || !strings.Contains(warnings[0], tt.wantWarn) {
+				t.Errorf("Warnings() = %v, want one warning containing %q", warnings, tt.wantWarn)
+			}
+		})
+	}
+}
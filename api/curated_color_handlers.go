@@ -0,0 +1,89 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/color-game/api/datastore"
+	"github.com/color-game/api/models"
+)
+
+// POST /v1/admin/colors/curated - Queue a curated daily color, optionally for a specific date (Admin only)
+func (app *Application) enqueueCuratedColor(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		app.requirePostMethod(w, r, ErrPOST)
+		return
+	}
+
+	admin, err := app.getUserFromToken(w, r)
+	if err != nil {
+		return
+	}
+
+	var req models.CuratedColorRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		app.badJSONRequest(w, r, err)
+		return
+	}
+
+	var v validator
+	v.check(strings.TrimSpace(req.ColorName) != "", "color_name", "color_name is required")
+	v.check(req.R >= 0 && req.R <= 255, "r", "must be between 0 and 255")
+	v.check(req.G >= 0 && req.G <= 255, "g", "must be between 0 and 255")
+	v.check(req.B >= 0 && req.B <= 255, "b", "must be between 0 and 255")
+
+	var scheduledFor *time.Time
+	if req.Date != "" {
+		now := time.Now()
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+		date, err := time.ParseInLocation("2006-01-02", req.Date, time.Local)
+		if err != nil {
+			v.check(false, "date", "date must be in YYYY-MM-DD format")
+		} else {
+			v.check(!date.Before(today), "date", "date must not be in the past")
+			scheduledFor = &date
+		}
+	}
+	if !v.valid() {
+		app.validationFailed(w, r, &v)
+		return
+	}
+
+	curatedColor, err := app.CuratedColorRepo.EnqueueCuratedColor(models.CuratedColor{
+		ColorName:    strings.TrimSpace(req.ColorName),
+		R:            req.R,
+		G:            req.G,
+		B:            req.B,
+		ScheduledFor: scheduledFor,
+		CreatedBy:    &admin.UserID,
+	})
+	if errors.Is(err, datastore.ErrCuratedDateTaken) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.writeJSON(w, http.StatusCreated, curatedColor)
+}
+
+// GET /v1/admin/colors/curated/all - List curated colors that haven't been used yet (Admin only)
+func (app *Application) getCuratedColors(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	colors, err := app.CuratedColorRepo.ListPendingCuratedColors()
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.writeList(w, "colors", colors, len(colors))
+}
@@ -0,0 +1,228 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/color-game/api/models"
+)
+
+const (
+	baseDailyAttempts = 5
+	maxDailyAttempts  = 10
+)
+
+// itemEffect is a registered consumable effect.
+// apply grants the effect for a user, uses being how many of the item are consumed at once.
+// preview describes what apply would do for the user right now, without changing anything.
+// validate checks an item's metadata and price before the item is saved.
+type itemEffect struct {
+	apply    func(app *Application, userID string, metadata map[string]any, uses int) (map[string]any, error)
+	preview  func(app *Application, userID string, metadata map[string]any) (string, map[string]any, error)
+	validate func(app *Application, metadata map[string]any, creditCost int) error
+}
+
+// itemEffects maps a shop item's metadata "effect_type" to the code that applies it
+var itemEffects = map[string]itemEffect{
+	"extra_attempt": {apply: applyExtraAttemptEffect, preview: previewExtraAttemptEffect, validate: validateExtraAttemptEffect},
+}
+
+// parseItemMetadata decodes a shop item's metadata, returning nil when there is none
+func parseItemMetadata(raw json.RawMessage) (map[string]any, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+
+	metadata := map[string]any{}
+	if err := json.Unmarshal(raw, &metadata); err != nil {
+		return nil, fmt.Errorf("failed to parse item metadata: %v", err)
+	}
+	return metadata, nil
+}
+
+// lookupItemEffect returns the registered effect for the item's metadata, if any
+func lookupItemEffect(metadata map[string]any) (itemEffect, bool) {
+	effectType, _ := metadata["effect_type"].(string)
+	effect, ok := itemEffects[effectType]
+	return effect, ok
+}
+
+// isAutoApply reports whether an item should be applied at purchase time instead of going to inventory.
+// Set with "auto_apply": true in the item's metadata; it only takes effect for registered effect types.
+func isAutoApply(metadata map[string]any) bool {
+	autoApply, _ := metadata["auto_apply"].(bool)
+	if !autoApply {
+		return false
+	}
+	_, ok := lookupItemEffect(metadata)
+	return ok
+}
+
+// maxPerUser returns how many of an item a user may hold, or 0 for no limit. Set with "max_per_user"
+// in the item's metadata; cosmetics default to 1 since owning several copies does nothing.
+func maxPerUser(itemType string, metadata map[string]any) int {
+	if limit, ok := metadata["max_per_user"].(float64); ok && limit >= 1 {
+		return int(limit)
+	}
+	switch itemType {
+	case models.ItemTypeBadge, models.ItemTypeAvatarHat, models.ItemTypeAvatarSkin:
+		return 1
+	}
+	return 0
+}
+
+// purchaseCooldown returns how long a user must wait between purchases of an item, or 0 for no
+// cooldown. Set with "purchase_cooldown_seconds" in the item's metadata.
+func purchaseCooldown(metadata map[string]any) time.Duration {
+	seconds, _ := metadata["purchase_cooldown_seconds"].(float64)
+	if seconds < 1 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// validateItemMetadata rejects malformed metadata and effects the item's price doesn't cover.
+// Items without an effect_type (badges, cosmetics) only need to be a JSON object.
+func (app *Application) validateItemMetadata(raw json.RawMessage, creditCost int) error {
+	metadata, err := parseItemMetadata(raw)
+	if err != nil {
+		return errors.New("metadata must be a JSON object")
+	}
+	if metadata == nil {
+		return nil
+	}
+
+	if autoApply, ok := metadata["auto_apply"]; ok {
+		if _, isBool := autoApply.(bool); !isBool {
+			return errors.New("metadata.auto_apply must be a boolean")
+		}
+	}
+
+	if rawCooldown, ok := metadata["purchase_cooldown_seconds"]; ok {
+		cooldown, isNumber := rawCooldown.(float64)
+		if !isNumber || cooldown != math.Trunc(cooldown) || cooldown < 1 {
+			return errors.New("metadata.purchase_cooldown_seconds must be a positive whole number")
+		}
+	}
+
+	if rawLimit, ok := metadata["max_per_user"]; ok {
+		limit, isNumber := rawLimit.(float64)
+		if !isNumber || limit != math.Trunc(limit) || limit < 1 {
+			return errors.New("metadata.max_per_user must be a positive whole number")
+		}
+	}
+
+	rawType, ok := metadata["effect_type"]
+	if !ok {
+		return nil
+	}
+	effectType, isString := rawType.(string)
+	if !isString {
+		return errors.New("metadata.effect_type must be a string")
+	}
+
+	effect, ok := itemEffects[effectType]
+	if !ok {
+		return fmt.Errorf("metadata.effect_type %q is not a known effect", effectType)
+	}
+	return effect.validate(app, metadata, creditCost)
+}
+
+// extraAttemptsFromMetadata reads the per-use attempt grant, defaulting to 1
+func extraAttemptsFromMetadata(metadata map[string]any) int {
+	extraAttempts := 1
+	if raw, ok := metadata["extra_attempts"]; ok {
+		switch v := raw.(type) {
+		case float64:
+			if attemptInt := int(v); attemptInt > 0 {
+				extraAttempts = attemptInt
+			}
+		case int:
+			if v > 0 {
+				extraAttempts = v
+			}
+		case string:
+			if parsed, err := strconv.Atoi(v); err == nil && parsed > 0 {
+				extraAttempts = parsed
+			}
+		}
+	}
+	return extraAttempts
+}
+
+// validateExtraAttemptEffect keeps a single item below the daily cap and priced per attempt granted
+func validateExtraAttemptEffect(app *Application, metadata map[string]any, creditCost int) error {
+	maxPerItem := maxDailyAttempts - baseDailyAttempts - 1
+
+	extraAttempts := 1
+	if raw, ok := metadata["extra_attempts"]; ok {
+		value, isNumber := raw.(float64)
+		if !isNumber || value != math.Trunc(value) {
+			return errors.New("metadata.extra_attempts must be a whole number")
+		}
+		extraAttempts = int(value)
+	}
+
+	if extraAttempts < 1 || extraAttempts > maxPerItem {
+		return fmt.Errorf("metadata.extra_attempts must be between 1 and %d", maxPerItem)
+	}
+
+	minCost := extraAttempts * app.Config.ExtraAttemptCreditCost
+	if creditCost < minCost {
+		return fmt.Errorf("creditCost must be at least %d for %d extra attempt(s)", minCost, extraAttempts)
+	}
+
+	return nil
+}
+
+// applyExtraAttemptEffect grants extra daily scan attempts for today
+func applyExtraAttemptEffect(app *Application, userID string, metadata map[string]any, uses int) (map[string]any, error) {
+	extraAttempts := extraAttemptsFromMetadata(metadata) * uses
+
+	now := time.Now()
+	normalizedDate := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	modifier, err := app.DailyScoreRepo.SetDailyAttemptModifier(userID, normalizedDate, extraAttempts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply extra attempts: %v", err)
+	}
+
+	maxAttempts, _, err := app.dailyAttemptAllowance(userID, normalizedDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load today's attempts: %v", err)
+	}
+
+	return map[string]any{
+		"extra_attempts_applied": extraAttempts,
+		"total_extra_attempts":   modifier.ExtraAttempts,
+		"max_attempts":           maxAttempts,
+	}, nil
+}
+
+// previewExtraAttemptEffect projects today's attempt allowance after one use. Attempts beyond
+// maxDailyAttempts are still recorded but can't be played, so those are called out as wasted.
+func previewExtraAttemptEffect(app *Application, userID string, metadata map[string]any) (string, map[string]any, error) {
+	extraAttempts := extraAttemptsFromMetadata(metadata)
+
+	now := time.Now()
+	normalizedDate := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	currentMax, _, err := app.dailyAttemptAllowance(userID, normalizedDate)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to load today's attempts: %v", err)
+	}
+
+	projectedMax := min(currentMax+extraAttempts, maxDailyAttempts)
+	description := fmt.Sprintf("+%d attempt(s) today, new max %d", extraAttempts, projectedMax)
+	if wasted := currentMax + extraAttempts - projectedMax; wasted > 0 {
+		description += fmt.Sprintf(" (%d over the daily cap of %d would be wasted)", wasted, maxDailyAttempts)
+	}
+
+	return description, map[string]any{
+		"extra_attempts":         extraAttempts,
+		"current_max_attempts":   currentMax,
+		"projected_max_attempts": projectedMax,
+	}, nil
+}
@@ -0,0 +1,36 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/color-game/api/models"
+)
+
+func TestMaxPerUser(t *testing.T) {
+	tests := []struct {
+		name     string
+		itemType string
+		metadata map[string]any
+		want     int
+	}{
+		{"powerups are unlimited by default", models.ItemTypePowerup, nil, 0},
+		{"badges default to one", models.ItemTypeBadge, nil, 1},
+		{"hats default to one", models.ItemTypeAvatarHat, map[string]any{}, 1},
+		{"skins default to one", models.ItemTypeAvatarSkin, nil, 1},
+		{"metadata sets a limit", models.ItemTypePowerup, map[string]any{"max_per_user": float64(3)}, 3},
+		{"metadata overrides the cosmetic default", models.ItemTypeBadge, map[string]any{"max_per_user": float64(5)}, 5},
+		{"a fractional limit is truncated", models.ItemTypePowerup, map[string]any{"max_per_user": 2.7}, 2},
+		{"a zero limit is ignored", models.ItemTypePowerup, map[string]any{"max_per_user": float64(0)}, 0},
+		{"a negative limit is ignored", models.ItemTypeBadge, map[string]any{"max_per_user": float64(-2)}, 1},
+		{"a non-numeric limit is ignored", models.ItemTypePowerup, map[string]any{"max_per_user": "3"}, 0},
+		{"unknown types are unlimited", "sticker", nil, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := maxPerUser(tt.itemType, tt.metadata); got != tt.want {
+				t.Errorf("maxPerUser(%q, %v) = %d, want %d", tt.itemType, tt.metadata, got, tt.want)
+			}
+		})
+	}
+}
@@ -1,11 +1,18 @@
 package api
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"log"
 	"net/http"
 	"path/filepath"
+	"reflect"
 	"runtime"
+
+	"github.com/color-game/api/colorapi"
 )
 
 // Helper function to get caller information
@@ -29,72 +36,133 @@ var ErrPOST = fmt.Errorf("POST method required for this endpoint")
 var ErrPUT = fmt.Errorf("PUT method required for this endpoint")
 var ErrInvalidPrivelege = fmt.Errorf("invalid authentication privileges")
 
+// writeJSON encodes v and writes it with the JSON Content-Type and status code.
+// v is marshalled before anything is written, so an encoding failure still returns a clean 500.
+func (app *Application) writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(v); err != nil {
+		log.Printf("failed to encode JSON response: %v", err)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(HandlerError{
+			ErrorName:        "Internal Server Error",
+			Description:      "failed to encode response",
+			PossibleSolution: "Internal Server Error requiring support",
+			CallerInfo:       getCallerInfo(),
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(buf.Bytes())
+}
+
+// writeList writes a 200 list response. With Config.ResponseEnvelope on, every list is
+// {"data": [...], "total": N}, where total counts every matching item, not just those in items when
+// the caller returns one page. Otherwise the legacy shape is kept: a bare array when key is empty,
+// or {key: [...]}. A nil slice is always written as [].
+func (app *Application) writeList(w http.ResponseWriter, key string, items interface{}, total int) {
+	if v := reflect.ValueOf(items); v.Kind() == reflect.Slice && v.IsNil() {
+		items = reflect.MakeSlice(v.Type(), 0, 0).Interface()
+	}
+
+	switch {
+	case app.Config.ResponseEnvelope:
+		app.writeJSON(w, http.StatusOK, map[string]interface{}{
+			"data":  items,
+			"total": total,
+		})
+	case key == "":
+		app.writeJSON(w, http.StatusOK, items)
+	default:
+		app.writeJSON(w, http.StatusOK, map[string]interface{}{
+			key: items,
+		})
+	}
+}
+
 func (app *Application) invalidCredentials(w http.ResponseWriter, r *http.Request, err error) {
-	w.WriteHeader(http.StatusUnauthorized)
 	errAuthorizingUser := HandlerError{
 		ErrorName:        "Error Authorizing User",
 		Description:      err.Error(),
 		PossibleSolution: "Retry with proper credentials",
 		CallerInfo:       getCallerInfo(),
 	}
-	json.NewEncoder(w).Encode(errAuthorizingUser)
+	app.writeJSON(w, http.StatusUnauthorized, errAuthorizingUser)
 }
 
 func (app *Application) invalidAuthorization(w http.ResponseWriter, r *http.Request, err error) {
-	w.WriteHeader(http.StatusUnauthorized)
 	errAuthorizingEndpoint := HandlerError{
 		ErrorName:        "Error Authenticating for Endpoint",
 		Description:      "Invalid Authentication",
 		PossibleSolution: "Check your headers and ensure you're submitting a valid token",
 		CallerInfo:       getCallerInfo(),
 	}
-	json.NewEncoder(w).Encode(errAuthorizingEndpoint)
+	app.writeJSON(w, http.StatusUnauthorized, errAuthorizingEndpoint)
 }
 
 func (app *Application) requirePostMethod(w http.ResponseWriter, r *http.Request, err error) {
 	w.Header().Set("Allow", http.MethodPost)
-	w.WriteHeader(http.StatusMethodNotAllowed)
 	postMethodRequired := HandlerError{
 		ErrorName:        "Post Method Required",
 		Description:      err.Error() + " you used: " + r.Method,
 		PossibleSolution: "Use POST method",
 		CallerInfo:       getCallerInfo(),
 	}
-	json.NewEncoder(w).Encode(postMethodRequired)
+	app.writeJSON(w, http.StatusMethodNotAllowed, postMethodRequired)
 }
 
 func (app *Application) requirePutMethod(w http.ResponseWriter, r *http.Request, err error) {
 	w.Header().Set("Allow", http.MethodPut)
-	w.WriteHeader(http.StatusMethodNotAllowed)
 	postMethodRequired := HandlerError{
 		ErrorName:        "PUT Method Required",
 		Description:      err.Error(),
 		PossibleSolution: "Use PUT method",
 		CallerInfo:       getCallerInfo(),
 	}
-	json.NewEncoder(w).Encode(postMethodRequired)
+	app.writeJSON(w, http.StatusMethodNotAllowed, postMethodRequired)
 }
 
+// badJSONRequest reports a body that couldn't be decoded. Decoder errors are translated so a
+// missing body, truncated or malformed JSON, and wrongly typed fields each get a clear message.
 func (app *Application) badJSONRequest(w http.ResponseWriter, r *http.Request, err error) {
-	w.WriteHeader(http.StatusBadRequest)
 	jsonErr := HandlerError{
 		ErrorName:        "Error Parsing JSON",
 		Description:      err.Error(),
 		PossibleSolution: "Double check your JSON formatting",
 		CallerInfo:       getCallerInfo(),
 	}
-	json.NewEncoder(w).Encode(jsonErr)
+
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.Is(err, io.EOF):
+		jsonErr.ErrorName = "Missing Request Body"
+		jsonErr.Description = "request body is required"
+		jsonErr.PossibleSolution = "Send a JSON object in the request body"
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		jsonErr.Description = "request body ends before the JSON is complete"
+	case errors.As(err, &syntaxErr):
+		jsonErr.Description = fmt.Sprintf("malformed JSON at byte %d: %v", syntaxErr.Offset, syntaxErr)
+	case errors.As(err, &typeErr) && typeErr.Field != "":
+		jsonErr.Description = fmt.Sprintf("field %q must be %s, got %s", typeErr.Field, typeErr.Type, typeErr.Value)
+		jsonErr.PossibleSolution = "Check the field types expected by this endpoint"
+	case errors.As(err, &typeErr):
+		jsonErr.Description = fmt.Sprintf("request body must be a JSON object, got %s", typeErr.Value)
+	}
+
+	app.writeJSON(w, http.StatusBadRequest, jsonErr)
 }
 
 func (app *Application) internalServerError(w http.ResponseWriter, r *http.Request, err error) {
-	w.WriteHeader(http.StatusInternalServerError)
 	errorStoringSessionToken := HandlerError{
 		ErrorName:        "Internal Server Error",
 		Description:      err.Error(),
 		PossibleSolution: "Internal Server Error requiring support",
 		CallerInfo:       getCallerInfo(),
 	}
-	json.NewEncoder(w).Encode(errorStoringSessionToken)
+	app.writeJSON(w, http.StatusInternalServerError, errorStoringSessionToken)
 }
 
 func (app *Application) userAlreadyExists(w http.ResponseWriter, r *http.Request, err error) {
@@ -104,8 +172,7 @@ func (app *Application) userAlreadyExists(w http.ResponseWriter, r *http.Request
 		PossibleSolution: "Advise user to login with their credentials",
 		CallerInfo:       getCallerInfo(),
 	}
-	w.WriteHeader(http.StatusConflict)
-	json.NewEncoder(w).Encode(userExists)
+	app.writeJSON(w, http.StatusConflict, userExists)
 }
 
 func (app *Application) badRequest(w http.ResponseWriter, r *http.Request, err error) {
@@ -115,6 +182,110 @@ func (app *Application) badRequest(w http.ResponseWriter, r *http.Request, err e
 		PossibleSolution: "Check your request parameters",
 		CallerInfo:       getCallerInfo(),
 	}
-	w.WriteHeader(http.StatusBadRequest)
-	json.NewEncoder(w).Encode(badRequest)
+	app.writeJSON(w, http.StatusBadRequest, badRequest)
+}
+
+func (app *Application) forbidden(w http.ResponseWriter, r *http.Request, err error) {
+	forbidden := HandlerError{
+		ErrorName:        "Forbidden",
+		Description:      err.Error(),
+		PossibleSolution: "This action isn't available in the current configuration",
+		CallerInfo:       getCallerInfo(),
+	}
+	app.writeJSON(w, http.StatusForbidden, forbidden)
+}
+
+func (app *Application) signupDisabled(w http.ResponseWriter, r *http.Request) {
+	signupClosed := HandlerError{
+		ErrorName:        "Signups Disabled",
+		Description:      "New account registration is currently closed",
+		PossibleSolution: "Try again later",
+		CallerInfo:       getCallerInfo(),
+	}
+	app.writeJSON(w, http.StatusForbidden, signupClosed)
+}
+
+// routeNotFound is the catch-all for paths that match no route, so clients always get JSON errors
+func (app *Application) routeNotFound(w http.ResponseWriter, r *http.Request) {
+	notFound := HandlerError{
+		ErrorName:        "Route Not Found",
+		Description:      fmt.Sprintf("no route matches %s %s", r.Method, r.URL.Path),
+		PossibleSolution: "Check the path and API version; see GET / for entry points",
+		CallerInfo:       getCallerInfo(),
+	}
+	app.writeJSON(w, http.StatusNotFound, notFound)
+}
+
+func (app *Application) serverBusy(w http.ResponseWriter, r *http.Request) {
+	busy := HandlerError{
+		ErrorName:        "Server Busy",
+		Description:      "Too many requests are being processed right now",
+		PossibleSolution: "Retry after the number of seconds in the Retry-After header",
+		CallerInfo:       getCallerInfo(),
+	}
+	w.Header().Set("Retry-After", "1")
+	app.writeJSON(w, http.StatusServiceUnavailable, busy)
+}
+
+func (app *Application) tooManyRequests(w http.ResponseWriter, r *http.Request, err error) {
+	limited := HandlerError{
+		ErrorName:        "Too Many Requests",
+		Description:      err.Error(),
+		PossibleSolution: "Wait for the limit to reset before trying again",
+		CallerInfo:       getCallerInfo(),
+	}
+	app.writeJSON(w, http.StatusTooManyRequests, limited)
+}
+
+// dailyColorNotReady reports that today's color hasn't been generated yet. It's a server-side
+// state that clears once generation succeeds, so clients are told to retry.
+func (app *Application) dailyColorNotReady(w http.ResponseWriter, r *http.Request) {
+	notReady := HandlerError{
+		ErrorName:        "Game Not Ready",
+		Description:      "Today's daily color hasn't been generated yet",
+		PossibleSolution: "Retry after the number of seconds in the Retry-After header",
+		CallerInfo:       getCallerInfo(),
+	}
+	w.Header().Set("Retry-After", "5")
+	app.writeJSON(w, http.StatusServiceUnavailable, notReady)
+}
+
+// underMaintenance reports that an operator has paused gameplay
+func (app *Application) underMaintenance(w http.ResponseWriter, r *http.Request) {
+	paused := HandlerError{
+		ErrorName:        "Under Maintenance",
+		Description:      "The game is paused for maintenance",
+		PossibleSolution: "Try again in a few minutes",
+		CallerInfo:       getCallerInfo(),
+	}
+	w.Header().Set("Retry-After", "60")
+	app.writeJSON(w, http.StatusServiceUnavailable, paused)
+}
+
+// colorAPIRetryAfter is the Retry-After hint, in seconds, sent when the color API fails
+const colorAPIRetryAfter = "30"
+
+// colorAPIError reports a color API failure as the upstream's fault: 503 when the service looks
+// temporarily unavailable, 502 when it answered with something unusable. Any other error is a 500.
+func (app *Application) colorAPIError(w http.ResponseWriter, r *http.Request, err error) {
+	var externalErr *colorapi.ExternalServiceError
+	if !errors.As(err, &externalErr) {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	status := http.StatusBadGateway
+	if externalErr.Unavailable() {
+		status = http.StatusServiceUnavailable
+	}
+	log.Printf("color API error: %v", err)
+
+	upstream := HandlerError{
+		ErrorName:        "Color Service Unavailable",
+		Description:      "The external color service failed to respond properly",
+		PossibleSolution: "Retry after the number of seconds in the Retry-After header",
+		CallerInfo:       getCallerInfo(),
+	}
+	w.Header().Set("Retry-After", colorAPIRetryAfter)
+	app.writeJSON(w, status, upstream)
 }
@@ -0,0 +1,108 @@
+package api
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestBadJSONRequest(t *testing.T) {
+	tests := []struct {
+		name            string
+		body            string
+		wantErrorName   string
+		wantDescription string
+	}{
+		{"missing body", "", "Missing Request Body", "request body is required"},
+		{"truncated body", `{"name": "Sea`, "Error Parsing JSON", "request body ends before the JSON is complete"},
+		{"malformed body", `{"name" "Sea Glass"}`, "Error Parsing JSON", "malformed JSON at byte 9"},
+		{"mistyped field", `{"quantity": "two"}`, "Error Parsing JSON", `field "quantity" must be int, got string`},
+		{"not an object", `["Sea Glass"]`, "Error Parsing JSON", "request body must be a JSON object, got array"},
+	}
+
+	app := &Application{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var req struct {
+				Name     string `json:"name"`
+				Quantity int    `json:"quantity"`
+			}
+			err := json.NewDecoder(strings.NewReader(tt.body)).Decode(&req)
+			if err == nil {
+				t.Fatalf("decoding %q succeeded, want an error", tt.body)
+			}
+
+			rec := httptest.NewRecorder()
+			app.badJSONRequest(rec, httptest.NewRequest(http.MethodPost, "/", nil), err)
+
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+			}
+			var got HandlerError
+			if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+				t.Fatalf("response isn't a HandlerError: %v", err)
+			}
+			if got.ErrorName != tt.wantErrorName {
+				t.Errorf("errorName = %q, want %q", got.ErrorName, tt.wantErrorName)
+			}
+			if !strings.HasPrefix(got.Description, tt.wantDescription) {
+				t.Errorf("description = %q, want it to start with %q", got.Description, tt.wantDescription)
+			}
+		})
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		value       interface{}
+		wantStatus  int
+		wantBody    string // exact body on success
+		wantMissing string // must not appear in the body on failure
+	}{
+		{"object", http.StatusCreated, map[string]int{"count": 3}, http.StatusCreated, "{\"count\":3}\n", ""},
+		{"nil", http.StatusOK, nil, http.StatusOK, "null\n", ""},
+		{"unsupported field after a valid one", http.StatusOK, struct {
+			Name string   `json:"name"`
+			Feed chan int `json:"feed"`
+		}{Name: "partial-value"}, http.StatusInternalServerError, "", "partial-value"},
+		{"NaN", http.StatusOK, map[string]interface{}{"label": "partial-value", "score": math.NaN()}, http.StatusInternalServerError, "", "partial-value"},
+	}
+
+	app := &Application{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			app.writeJSON(rec, tt.status, tt.value)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if got := rec.Header().Get("Content-Type"); got != "application/json" {
+				t.Errorf("Content-Type = %q, want application/json", got)
+			}
+
+			if tt.wantStatus == http.StatusInternalServerError {
+				body := rec.Body.String()
+				if strings.Contains(body, tt.wantMissing) {
+					t.Errorf("body leaked the partially encoded value: %s", body)
+				}
+				var got HandlerError
+				if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+					t.Fatalf("body isn't a single HandlerError: %v: %s", err, body)
+				}
+				if got.Description != "failed to encode response" {
+					t.Errorf("description = %q, want %q", got.Description, "failed to encode response")
+				}
+				return
+			}
+			if got := rec.Body.String(); got != tt.wantBody {
+				t.Errorf("body = %q, want %q", got, tt.wantBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,205 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/color-game/api/datastore"
+	"github.com/color-game/api/models"
+)
+
+// maxRewardMultiplier keeps a typo from flooding the economy
+const maxRewardMultiplier = 10.0
+
+// validateRewardEvent checks the fields shared by create and update
+func validateRewardEvent(event models.RewardEvent) error {
+	if strings.TrimSpace(event.Name) == "" {
+		return errors.New("name is required")
+	}
+	if event.Multiplier <= 0 || event.Multiplier > maxRewardMultiplier {
+		return errors.New("multiplier must be greater than 0 and at most 10")
+	}
+	if event.EndsOn.Before(event.StartsOn) {
+		return errors.New("endsOn must not be before startsOn")
+	}
+	return nil
+}
+
+func parseEventDate(field, value string) (time.Time, error) {
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, errors.New(field + " must be in YYYY-MM-DD format")
+	}
+	return date, nil
+}
+
+// parseEventID reads the event ID from query params
+func parseEventID(r *http.Request) (int, error) {
+	idStr := r.URL.Query().Get("id")
+	if idStr == "" {
+		return 0, errors.New("event ID is required")
+	}
+
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		return 0, errors.New("invalid event ID")
+	}
+
+	return id, nil
+}
+
+// POST /v1/admin/events - Create a reward event (Admin only)
+func (app *Application) createRewardEvent(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		app.requirePostMethod(w, r, ErrPOST)
+		return
+	}
+
+	var createReq models.CreateRewardEventRequest
+	if err := json.NewDecoder(r.Body).Decode(&createReq); err != nil {
+		app.badJSONRequest(w, r, err)
+		return
+	}
+
+	startsOn, err := parseEventDate("startsOn", createReq.StartsOn)
+	if err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+
+	endsOn, err := parseEventDate("endsOn", createReq.EndsOn)
+	if err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+
+	event := models.RewardEvent{
+		Name:       createReq.Name,
+		Multiplier: createReq.Multiplier,
+		StartsOn:   startsOn,
+		EndsOn:     endsOn,
+	}
+
+	if err := validateRewardEvent(event); err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+
+	createdEvent, err := app.RewardEventRepo.Create(event)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.writeJSON(w, http.StatusCreated, createdEvent)
+}
+
+// GET /v1/admin/events/all - Get all reward events (Admin only)
+func (app *Application) getRewardEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	events, err := app.RewardEventRepo.GetAll()
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.writeList(w, "events", events, len(events))
+}
+
+// PUT /v1/admin/events/update - Update a reward event (Admin only)
+func (app *Application) updateRewardEvent(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		app.requirePutMethod(w, r, ErrPUT)
+		return
+	}
+
+	eventID, err := parseEventID(r)
+	if err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+
+	var updateReq models.UpdateRewardEventRequest
+	if err := json.NewDecoder(r.Body).Decode(&updateReq); err != nil {
+		app.badJSONRequest(w, r, err)
+		return
+	}
+
+	event, err := app.RewardEventRepo.Get(eventID)
+	if err != nil {
+		if _, ok := err.(datastore.NoRowsError); ok {
+			http.Error(w, "Event not found", http.StatusNotFound)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if updateReq.Name != nil {
+		event.Name = *updateReq.Name
+	}
+	if updateReq.Multiplier != nil {
+		event.Multiplier = *updateReq.Multiplier
+	}
+	if updateReq.StartsOn != nil {
+		if event.StartsOn, err = parseEventDate("startsOn", *updateReq.StartsOn); err != nil {
+			app.badRequest(w, r, err)
+			return
+		}
+	}
+	if updateReq.EndsOn != nil {
+		if event.EndsOn, err = parseEventDate("endsOn", *updateReq.EndsOn); err != nil {
+			app.badRequest(w, r, err)
+			return
+		}
+	}
+
+	if err := validateRewardEvent(event); err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+
+	updatedEvent, err := app.RewardEventRepo.Update(event)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.writeJSON(w, http.StatusOK, updatedEvent)
+}
+
+// DELETE /v1/admin/events/delete - Delete a reward event (Admin only)
+func (app *Application) deleteRewardEvent(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	eventID, err := parseEventID(r)
+	if err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+
+	if err := app.RewardEventRepo.Delete(eventID); err != nil {
+		if _, ok := err.(datastore.NoRowsError); ok {
+			http.Error(w, "Event not found", http.StatusNotFound)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"message": "Event deleted successfully",
+		"eventId": eventID,
+	})
+}
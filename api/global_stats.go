@@ -0,0 +1,57 @@
+package api
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/color-game/api/models"
+)
+
+// globalStatsTTL is how long computed platform totals are served before they're recomputed
+const globalStatsTTL = 5 * time.Minute
+
+// globalStatsCache holds the most recently computed platform totals
+type globalStatsCache struct {
+	mu        sync.Mutex
+	stats     models.GlobalStats
+	expiresAt time.Time
+}
+
+// cachedGlobalStats is shared by all requests so the public endpoint can't be used to hammer the database
+var cachedGlobalStats globalStatsCache
+
+// get returns the cached stats, computing and storing them with load when stale
+func (c *globalStatsCache) get(load func() (models.GlobalStats, error)) (models.GlobalStats, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if time.Now().Before(c.expiresAt) {
+		return c.stats, nil
+	}
+
+	stats, err := load()
+	if err != nil {
+		return models.GlobalStats{}, err
+	}
+
+	c.stats = stats
+	c.expiresAt = time.Now().Add(globalStatsTTL)
+	return stats, nil
+}
+
+// GET /v1/stats/global - Platform-wide totals, recomputed at most every few minutes
+func (app *Application) getGlobalStats(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	stats, err := cachedGlobalStats.get(app.DailyLeaderboardRepo.GetGlobalStats)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.writeJSON(w, http.StatusOK, stats)
+}
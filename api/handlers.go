@@ -6,23 +6,43 @@ import (
 	"fmt"
 	"log"
 	"math"
-	"math/rand"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/color-game/api/datastore"
 	"github.com/color-game/api/models"
+	"github.com/color-game/api/version"
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// apiVersion is the path prefix shared by every versioned route
+const apiVersion = "v1"
+
 // GET /
 func (app *Application) home(w http.ResponseWriter, r *http.Request) {
-	if r.URL.Path != "/" {
-		http.NotFound(w, r)
+	app.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"service": "Color Game API",
+		"version": apiVersion,
+		"links": map[string]string{
+			"dailyColor":  "/" + apiVersion + "/colors/daily",
+			"leaderboard": "/" + apiVersion + "/leaderboard",
+			"shopItems":   "/" + apiVersion + "/shop/items",
+		},
+	})
+}
+
+// GET /v1/version - Build metadata of the running server
+func (app *Application) getVersion(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
-	w.WriteHeader(http.StatusOK)
-	fmt.Fprintf(w, "Color Game API")
+
+	app.writeJSON(w, http.StatusOK, version.Get())
 }
 
 // POST /v1/auth/signup
@@ -32,6 +52,11 @@ func (app *Application) signup(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !app.Config.SignupEnabled {
+		app.signupDisabled(w, r)
+		return
+	}
+
 	userSignup := &models.UserSignupRequest{}
 	errParsingJson := json.NewDecoder(r.Body).Decode(userSignup)
 	if errParsingJson != nil {
@@ -39,27 +64,35 @@ func (app *Application) signup(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Validate username doesn't contain spaces
-	if len(userSignup.Username) == 0 {
-		app.badRequest(w, r, errors.New("username is required"))
-		return
+	// Collect every problem so the client can fix the whole form at once
+	var v validator
+	v.check(userSignup.Username != "", "username", "username is required")
+	v.check(!strings.Contains(userSignup.Username, " "), "username", "username cannot contain spaces")
+	v.checkErr("password", models.ValidatePassword(userSignup.Password, app.Config.PasswordPolicy))
+	if app.Config.SignupInviteOnly {
+		v.check(strings.TrimSpace(userSignup.InviteCode) != "", "inviteCode", "an invite code is required to sign up")
 	}
-
-	// Check for spaces in username
-	for _, char := range userSignup.Username {
-		if char == ' ' {
-			app.badRequest(w, r, errors.New("username cannot contain spaces"))
-			return
-		}
+	if !v.valid() {
+		app.validationFailed(w, r, &v)
+		return
 	}
 
 	// Create new user
 	newUser, newUserErr := models.NewUser(*userSignup)
+	if errors.Is(newUserErr, models.ErrPasswordTooLong) {
+		app.badRequest(w, r, models.ErrPasswordTooLong)
+		return
+	}
 	if newUserErr != nil {
 		app.internalServerError(w, r, newUserErr)
 		return
 	}
 
+	// Welcome stipend so new players can afford something in the shop; a points bonus may start them past level 1
+	newUser.Credits = app.Config.SignupCreditBonus
+	newUser.Points = app.Config.SignupPointBonus
+	newUser.Level = app.levelCurve().LevelForPoints(newUser.Points)
+
 	// Check if email already exists
 	_, getErr := app.UserRepo.GetUserByEmail(newUser.Email)
 	if getErr == nil {
@@ -74,15 +107,24 @@ func (app *Application) signup(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Store new user in database
-	storedUser, errStoringNewUser := app.UserRepo.Create(newUser)
+	// Store new user in database, spending their invite code in the same transaction when required
+	var storedUser models.User
+	var errStoringNewUser error
+	if app.Config.SignupInviteOnly {
+		storedUser, errStoringNewUser = app.UserRepo.CreateWithInviteCode(newUser, strings.TrimSpace(userSignup.InviteCode))
+	} else {
+		storedUser, errStoringNewUser = app.UserRepo.Create(newUser)
+	}
+	if errors.Is(errStoringNewUser, datastore.ErrInviteCodeInvalid) {
+		app.badRequest(w, r, errStoringNewUser)
+		return
+	}
 	if errStoringNewUser != nil {
 		app.internalServerError(w, r, errStoringNewUser)
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(storedUser)
+	app.writeJSON(w, http.StatusOK, storedUser)
 }
 
 // POST /v1/auth/login
@@ -143,6 +185,9 @@ func (app *Application) login(w http.ResponseWriter, r *http.Request) {
 		Scope:             "authentication",
 		TokenType:         models.JWT.ACCESS_COOKIE_NAME,
 		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    app.Config.JwtIssuer,
+			Audience:  app.jwtAudience(),
+			ID:        models.NewTokenID(),
 			ExpiresAt: jwt.NewNumericDate(accessExpiry),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
@@ -182,6 +227,9 @@ func (app *Application) login(w http.ResponseWriter, r *http.Request) {
 		Scope:             "refresh",
 		TokenType:         models.JWT.REFRESH_COOKIE_NAME,
 		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    app.Config.JwtIssuer,
+			Audience:  app.jwtAudience(),
+			ID:        models.NewTokenID(),
 			ExpiresAt: jwt.NewNumericDate(refreshExpiry),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
@@ -209,407 +257,1304 @@ func (app *Application) login(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 }
 
-// GET /v1/users/me - Get current authenticated user
-func (app *Application) getCurrentUser(w http.ResponseWriter, r *http.Request) {
-	user, err := app.getUserFromToken(w, r)
-	if err != nil {
+// POST /v1/auth/logout - Revoke the caller's tokens and device
+func (app *Application) logout(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		app.requirePostMethod(w, r, ErrPOST)
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(user)
-}
-
-// PUT /v1/users/me - Update current authenticated user
-func (app *Application) updateCurrentUser(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPut {
-		app.requirePutMethod(w, r, ErrPUT)
+	tokenString, err := accessTokenFromRequest(r)
+	if err != nil {
+		app.invalidAuthorization(w, r, err)
 		return
 	}
 
-	// Get current user from token
-	currentUser, err := app.getUserFromToken(w, r)
+	claims, err := models.ValidateJWTToken(tokenString, app.Config.JwtSecret, app.Config.JwtIssuer, app.Config.JwtAudience)
 	if err != nil {
+		app.invalidAuthorization(w, r, err)
 		return
 	}
 
-	// Parse update request
-	updateReq := &models.UserUpdateRequest{}
-	if err := json.NewDecoder(r.Body).Decode(updateReq); err != nil {
-		app.badJSONRequest(w, r, err)
-		return
+	// Denylist the access token so it stops working before it expires
+	if claims.ID != "" && claims.ExpiresAt != nil {
+		if err := app.UserRepo.RevokeToken(claims.ID, claims.UserID, claims.ExpiresAt.Time); err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
 	}
 
-	// Update user fields
-	currentUser.Username = updateReq.Username
-	currentUser.Email = updateReq.Email
-	currentUser.UpdatedAt = time.Now()
+	// Denylist the refresh token too, if it was sent
+	if cookie, err := r.Cookie(models.JWT.REFRESH_COOKIE_NAME); err == nil {
+		refreshClaims, err := models.ValidateJWTToken(cookie.Value, app.Config.JwtSecret, app.Config.JwtIssuer, app.Config.JwtAudience)
+		if err == nil && refreshClaims.ID != "" && refreshClaims.ExpiresAt != nil {
+			if err := app.UserRepo.RevokeToken(refreshClaims.ID, refreshClaims.UserID, refreshClaims.ExpiresAt.Time); err != nil {
+				app.internalServerError(w, r, err)
+				return
+			}
+		}
+	}
 
-	// Save to database
-	updatedUser, updateErr := app.UserRepo.Update(currentUser)
-	if updateErr != nil {
-		app.internalServerError(w, r, updateErr)
-		return
+	// Remove the device so its tokens can't be reissued
+	if device, err := app.UserRepo.GetDeviceByFingerprint(claims.UserID, claims.DeviceFingerprint); err == nil {
+		if err := app.UserRepo.DeleteDevice(device.ID); err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
 	}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(updatedUser)
+	sameSite := http.SameSiteStrictMode
+	if app.Config.JwtDomain == "" {
+		sameSite = http.SameSiteNoneMode
+	}
+
+	for _, name := range []string{models.JWT.ACCESS_COOKIE_NAME, models.JWT.REFRESH_COOKIE_NAME} {
+		http.SetCookie(w, &http.Cookie{
+			Name:     name,
+			Value:    "",
+			HttpOnly: true,
+			Secure:   true,
+			SameSite: sameSite,
+			Path:     "/",
+			Domain:   app.Config.JwtDomain,
+			MaxAge:   -1,
+		})
+	}
+
+	app.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"message": "Logged out",
+	})
 }
 
-// GET /v1/users - Get all users
-func (app *Application) getAllUsers(w http.ResponseWriter, r *http.Request) {
-	users, retrieveErr := app.UserRepo.GetAllUsers()
-	if retrieveErr != nil {
-		app.internalServerError(w, r, retrieveErr)
+// GET /v1/users/me - Get current authenticated user
+func (app *Application) getCurrentUser(w http.ResponseWriter, r *http.Request) {
+	user, err := app.getUserFromToken(w, r)
+	if err != nil {
 		return
 	}
 
-	json.NewEncoder(w).Encode(users)
+	app.writeJSON(w, http.StatusOK, user)
 }
 
-// GET /v1/colors/random - Get a random color palette
-func (app *Application) getRandomColor(w http.ResponseWriter, r *http.Request) {
+// GET /v1/users/me/export - Download everything stored about the current user as one JSON document
+func (app *Application) exportUserData(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	// Generate random RGB values
-	r1 := rand.Intn(256)
-	g := rand.Intn(256)
-	b := rand.Intn(256)
+	user, err := app.getUserFromToken(w, r)
+	if err != nil {
+		return
+	}
 
-	// Build the URL for thecolorapi.com
-	url := fmt.Sprintf("https://www.thecolorapi.com/scheme?rgb=%d,%d,%d&mode=analogic&count=6&format=json", r1, g, b)
+	export := models.UserDataExport{
+		ExportedAt:  time.Now().UTC(),
+		Profile:     user,
+		Friendships: []models.ExportedFriendship{},
+	}
 
-	// Make HTTP request to the color API
-	resp, err := http.Get(url)
-	if err != nil {
+	// A limit of 0 returns the full history
+	if export.Scores, err = app.DailyScoreRepo.GetUserScoreHistory(user.UserID, nil, 0); err != nil {
 		app.internalServerError(w, r, err)
 		return
 	}
-	defer resp.Body.Close()
-
-	// Check status code
-	if resp.StatusCode != http.StatusOK {
-		app.internalServerError(w, r, fmt.Errorf("color API returned status: %d", resp.StatusCode))
+	if export.ScoreSummaries, err = app.DailyScoreRepo.GetUserScoreSummaries(user.UserID); err != nil {
+		app.internalServerError(w, r, err)
 		return
 	}
-
-	// Parse the response
-	var colorResponse models.ColorAPIResponse
-	if err := json.NewDecoder(resp.Body).Decode(&colorResponse); err != nil {
+	if export.Leaderboard, err = app.DailyLeaderboardRepo.GetUserHistory(user.UserID); err != nil {
 		app.internalServerError(w, r, err)
 		return
 	}
-
-	// Return the color palette
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(colorResponse)
-}
-
-// GET /v1/colors/daily - Get today's daily color
-func (app *Application) getDailyColor(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	if export.Activity, err = app.FriendRepo.GetUserActivityHistory(user.UserID); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if export.Purchases, err = app.ShopRepo.GetUserPurchaseHistory(user.UserID, nil, 0); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if export.Inventory, err = app.ShopRepo.GetUserInventory(user.UserID); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if export.Devices, err = app.UserRepo.GetDevicesByUser(user.UserID); err != nil {
+		app.internalServerError(w, r, err)
 		return
 	}
 
-	// Get today's color from database
-	dailyColor, err := app.DailyColorRepo.GetToday()
+	friends, err := app.FriendRepo.ListFriends(user.UserID)
 	if err != nil {
 		app.internalServerError(w, r, err)
 		return
 	}
+	for _, friend := range friends {
+		export.Friendships = append(export.Friendships, models.ExportedFriendship{
+			FriendshipID: friend.FriendshipID,
+			UserID:       friend.Friend.UserID,
+			Username:     friend.Friend.Username,
+			Status:       friend.Status,
+			CreatedAt:    friend.CreatedAt,
+			RespondedAt:  friend.RespondedAt,
+		})
+	}
 
-	// Format response
-	response := models.DailyColorResponse{
-		Date:      dailyColor.Date.Format("2006-01-02"),
-		ColorName: dailyColor.ColorName,
-		RGB:       fmt.Sprintf("rgb(%d,%d,%d)", dailyColor.R, dailyColor.G, dailyColor.B),
-		Hex:       fmt.Sprintf("#%02X%02X%02X", dailyColor.R, dailyColor.G, dailyColor.B),
+	requests, err := app.FriendRepo.ListFriendRequests(user.UserID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	for _, request := range requests {
+		export.Friendships = append(export.Friendships, models.ExportedFriendship{
+			FriendshipID: request.FriendshipID,
+			UserID:       request.User.UserID,
+			Username:     request.User.Username,
+			Status:       request.Status,
+			Direction:    request.Direction,
+			CreatedAt:    request.CreatedAt,
+		})
 	}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(response)
+	export.Purchases = publicPurchases(export.Purchases)
+	export.Inventory = publicInventory(export.Inventory)
+
+	filename := fmt.Sprintf("color-game-export-%s.json", export.ExportedAt.Format("2006-01-02"))
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	app.writeJSON(w, http.StatusOK, export)
 }
 
-// GET /v1/colors/daily/all - Get all daily colors
-func (app *Application) getAllDailyColors(w http.ResponseWriter, r *http.Request) {
+// GET /v1/users/me/level - Get the current user's progress toward their next level
+func (app *Application) getLevelProgress(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	// Get all colors from database
-	dailyColors, err := app.DailyColorRepo.GetAll()
+	user, err := app.getUserFromToken(w, r)
 	if err != nil {
-		app.internalServerError(w, r, err)
 		return
 	}
 
-	// Format response
-	var responses []models.DailyColorResponse
-	for _, dc := range dailyColors {
-		responses = append(responses, models.DailyColorResponse{
-			Date:      dc.Date.Format("2006-01-02"),
-			ColorName: dc.ColorName,
-			RGB:       fmt.Sprintf("rgb(%d,%d,%d)", dc.R, dc.G, dc.B),
-			Hex:       fmt.Sprintf("#%02X%02X%02X", dc.R, dc.G, dc.B),
-		})
-	}
-
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(responses)
+	app.writeJSON(w, http.StatusOK, app.levelCurve().levelProgress(user.Points))
 }
 
-// calculateColorScore calculates a score (0-100) based on color similarity
-// Uses Euclidean distance in RGB space, normalized to 0-100
-func calculateColorScore(targetR, targetG, targetB, submittedR, submittedG, submittedB int) int {
-	// Calculate Euclidean distance
-	distance := math.Sqrt(
-		math.Pow(float64(targetR-submittedR), 2) +
-			math.Pow(float64(targetG-submittedG), 2) +
-			math.Pow(float64(targetB-submittedB), 2),
-	)
-
-	// Maximum possible distance in RGB space is sqrt(255^2 + 255^2 + 255^2) ≈ 441.67
-	maxDistance := 441.67
-
-	// Convert distance to score (0-100, where 100 is perfect match)
-	score := int(math.Round((1 - (distance / maxDistance)) * 100))
+// GET /v1/users/me/best - Get the user's best-ever daily score and its rank on that day
+func (app *Application) getPersonalBest(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
 
-	// Ensure score is within bounds
-	if score < 0 {
-		score = 0
+	user, err := app.getUserFromToken(w, r)
+	if err != nil {
+		return
 	}
-	if score > 100 {
-		score = 100
+
+	best, err := app.DailyLeaderboardRepo.GetUserPersonalBest(user.UserID)
+	if err != nil {
+		if _, ok := err.(datastore.NoRowsError); ok {
+			http.Error(w, "No scores recorded yet", http.StatusNotFound)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
 	}
 
-	return score
+	app.writeJSON(w, http.StatusOK, best)
 }
 
-// POST /v1/scores/submit - Submit a score attempt
-func (app *Application) submitScore(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		app.requirePostMethod(w, r, ErrPOST)
+// PUT /v1/users/me - Update current authenticated user
+func (app *Application) updateCurrentUser(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		app.requirePutMethod(w, r, ErrPUT)
 		return
 	}
 
 	// Get current user from token
-	user, err := app.getUserFromToken(w, r)
+	currentUser, err := app.getUserFromToken(w, r)
 	if err != nil {
 		return
 	}
 
-	// Parse submission
-	var submission models.ScoreSubmissionRequest
-	if err := json.NewDecoder(r.Body).Decode(&submission); err != nil {
+	// Parse update request
+	updateReq := &models.UserUpdateRequest{}
+	if err := json.NewDecoder(r.Body).Decode(updateReq); err != nil {
 		app.badJSONRequest(w, r, err)
 		return
 	}
 
-	// Validate RGB values
-	if submission.SubmittedColorR < 0 || submission.SubmittedColorR > 255 ||
-		submission.SubmittedColorG < 0 || submission.SubmittedColorG > 255 ||
-		submission.SubmittedColorB < 0 || submission.SubmittedColorB > 255 {
-		app.badJSONRequest(w, r, errors.New("RGB values must be between 0 and 255"))
+	// Update user fields
+	currentUser.Username = updateReq.Username
+	currentUser.Email = updateReq.Email
+	currentUser.UpdatedAt = time.Now()
+
+	// Save to database
+	updatedUser, updateErr := app.UserRepo.Update(currentUser)
+	if updateErr != nil {
+		app.internalServerError(w, r, updateErr)
 		return
 	}
 
-	// Get today's color
-	today := time.Now()
-	normalizedToday := time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, today.Location())
+	app.writeJSON(w, http.StatusOK, updatedUser)
+}
 
-	dailyColor, err := app.DailyColorRepo.GetToday()
-	if err != nil {
-		app.internalServerError(w, r, errors.New("no daily color available for today"))
+// maxAvatarURLLength matches the avatar_url column
+const maxAvatarURLLength = 2048
+
+// validateAvatarURL accepts an absolute http(s) URL, or an empty string to clear the avatar
+func validateAvatarURL(raw string) error {
+	if raw == "" {
+		return nil
+	}
+	if len(raw) > maxAvatarURLLength {
+		return fmt.Errorf("avatarUrl must be at most %d characters", maxAvatarURLLength)
+	}
+	parsed, err := url.Parse(raw)
+	if err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Host == "" {
+		return errors.New("avatarUrl must be an absolute http or https URL")
+	}
+	if parsed.User != nil {
+		return errors.New("avatarUrl must not contain credentials")
+	}
+	return nil
+}
+
+// PUT /v1/users/me/avatar - Set or clear the current user's avatar image URL
+func (app *Application) updateAvatar(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		app.requirePutMethod(w, r, ErrPUT)
 		return
 	}
 
-	// Check how many attempts the user has made today
-	attemptCount, err := app.DailyScoreRepo.GetUserAttemptCount(user.UserID, normalizedToday)
+	currentUser, err := app.getUserFromToken(w, r)
 	if err != nil {
-		app.internalServerError(w, r, err)
 		return
 	}
 
-	extraAttempts := 0
-	modifier, err := app.DailyScoreRepo.GetDailyAttemptModifier(user.UserID, normalizedToday)
-	if err == nil {
-		extraAttempts = modifier.ExtraAttempts
-	} else if _, ok := err.(datastore.NoRowsError); !ok {
-		app.internalServerError(w, r, err)
+	req := models.AvatarUpdateRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		app.badJSONRequest(w, r, err)
 		return
 	}
 
-	maxAttempts := 5 + extraAttempts
-	if maxAttempts > 10 {
-		maxAttempts = 10
+	avatarURL := strings.TrimSpace(req.AvatarURL)
+	if err := validateAvatarURL(avatarURL); err != nil {
+		app.badRequest(w, r, err)
+		return
 	}
 
-	if attemptCount >= maxAttempts {
-		http.Error(w, fmt.Sprintf("Maximum attempts (%d) reached for today", maxAttempts), http.StatusBadRequest)
+	if err := app.UserRepo.SetAvatarURL(currentUser.UserID, avatarURL); err != nil {
+		app.internalServerError(w, r, err)
 		return
 	}
 
-	// Calculate score
-	score := calculateColorScore(
-		dailyColor.R, dailyColor.G, dailyColor.B,
-		submission.SubmittedColorR, submission.SubmittedColorG, submission.SubmittedColorB,
-	)
+	currentUser.AvatarURL = avatarURL
+	currentUser.UpdatedAt = time.Now()
+	app.writeJSON(w, http.StatusOK, currentUser)
+}
 
-	// Create daily score entry
-	dailyScore := models.DailyScore{
-		UserID:          user.UserID,
-		Date:            normalizedToday,
-		AttemptNumber:   attemptCount + 1,
-		Score:           score,
-		SubmittedColorR: submission.SubmittedColorR,
-		SubmittedColorG: submission.SubmittedColorG,
-		SubmittedColorB: submission.SubmittedColorB,
-		TargetColorR:    dailyColor.R,
-		TargetColorG:    dailyColor.G,
-		TargetColorB:    dailyColor.B,
-		CreatedAt:       time.Now(),
+// PUT /v1/users/me/privacy - Update the current user's privacy preferences
+func (app *Application) updatePrivacy(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		app.requirePutMethod(w, r, ErrPUT)
+		return
 	}
 
-	// Save the score
-	savedScore, err := app.DailyScoreRepo.Create(dailyScore)
+	currentUser, err := app.getUserFromToken(w, r)
 	if err != nil {
-		app.internalServerError(w, r, err)
 		return
 	}
 
-	// Get user's best score for today
-	existingLeaderboard, err := app.DailyLeaderboardRepo.GetByUserAndDate(user.UserID, normalizedToday)
-	hasExistingLeaderboard := true
-	if err != nil {
-		if _, ok := err.(datastore.NoRowsError); ok {
-			hasExistingLeaderboard = false
-		} else {
-			app.internalServerError(w, r, err)
-			return
-		}
+	req := models.PrivacyUpdateRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		app.badJSONRequest(w, r, err)
+		return
 	}
 
-	isNewBest := false
-	bestScore := score
-	bestAttemptsUsed := savedScore.AttemptNumber
-
-	if !hasExistingLeaderboard {
-		isNewBest = true
-	} else {
-		bestScore = existingLeaderboard.BestScore
-		bestAttemptsUsed = existingLeaderboard.AttemptsUsed
+	if err := app.UserRepo.SetHideFromActivityFeed(currentUser.UserID, req.HideFromActivityFeed); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	currentUser.HideFromFeed = req.HideFromActivityFeed
+	currentUser.UpdatedAt = time.Now()
+	app.writeJSON(w, http.StatusOK, currentUser)
+}
+
+// GET /v1/users - Get all users
+func (app *Application) getAllUsers(w http.ResponseWriter, r *http.Request) {
+	users, retrieveErr := app.UserRepo.GetAllUsers()
+	if retrieveErr != nil {
+		app.internalServerError(w, r, retrieveErr)
+		return
+	}
+
+	app.writeList(w, "", users, len(users))
+}
+
+// POST /v1/admin/users/recalculate-levels - Recompute every user's level from their points (Admin only, audited)
+func (app *Application) recalculateUserLevels(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		app.requirePostMethod(w, r, ErrPOST)
+		return
+	}
+
+	admin, err := app.getUserFromToken(w, r)
+	if err != nil {
+		return
+	}
+
+	// A run that fails partway has still fixed some users, so it is audited either way
+	result, repairErr := app.recalculateLevels()
+
+	changes := result.Changes
+	if changes == nil {
+		changes = []levelChange{}
+	}
+	details, _ := json.Marshal(map[string]interface{}{
+		"usersChecked": result.UsersChecked,
+		"usersFixed":   result.UsersFixed,
+		"changes":      changes,
+	})
+	if _, err := app.AuditLogRepo.RecordAuditEntry(models.AuditEntry{
+		AdminID: admin.UserID,
+		Action:  models.AuditActionRepairLevels,
+		Details: details,
+	}); err != nil {
+		log.Printf("Failed to audit level repair by %s: %v", admin.UserID, err)
+	}
+
+	if repairErr != nil {
+		app.internalServerError(w, r, repairErr)
+		return
+	}
+
+	log.Printf("level repair: checked %d users, fixed %d", result.UsersChecked, result.UsersFixed)
+	app.writeJSON(w, http.StatusOK, result)
+}
+
+// GET|DELETE /v1/admin/users/{id}/devices - List or revoke all of a user's devices (Admin only)
+func (app *Application) adminUserDevices(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	targetUserID := r.PathValue("id")
+	if _, err := app.UserRepo.Get(targetUserID); err != nil {
+		if _, ok := err.(datastore.NoRowsError); ok {
+			http.Error(w, "User not found", http.StatusNotFound)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		devices, err := app.UserRepo.GetDevicesByUser(targetUserID)
+		if err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+
+		app.writeJSON(w, http.StatusOK, map[string]interface{}{
+			"userId":  targetUserID,
+			"devices": devices,
+		})
+		return
+	}
+
+	admin, err := app.getUserFromToken(w, r)
+	if err != nil {
+		return
+	}
+
+	// Deleting devices invalidates tokens immediately since getUserFromJWT checks the device
+	revoked, err := app.UserRepo.DeleteDevicesByUser(targetUserID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	log.Printf("Admin %s revoked all %d devices for user %s", admin.UserID, revoked, targetUserID)
+
+	app.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"message": "All devices revoked",
+		"userId":  targetUserID,
+		"revoked": revoked,
+	})
+}
+
+// DELETE /v1/admin/users/{id}/devices/{deviceId} - Revoke one of a user's devices (Admin only)
+func (app *Application) adminRevokeUserDevice(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	admin, err := app.getUserFromToken(w, r)
+	if err != nil {
+		return
+	}
 
-		if score > existingLeaderboard.BestScore {
-			isNewBest = true
-			bestScore = score
-			bestAttemptsUsed = savedScore.AttemptNumber
+	targetUserID := r.PathValue("id")
+	deviceID := r.PathValue("deviceId")
+
+	// Only delete the device if it belongs to the target user
+	devices, err := app.UserRepo.GetDevicesByUser(targetUserID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	found := false
+	for _, device := range devices {
+		if device.ID == deviceID {
+			found = true
+			break
 		}
 	}
+	if !found {
+		http.Error(w, "Device not found", http.StatusNotFound)
+		return
+	}
 
-	// Update leaderboard if this is the best score
-	if isNewBest {
+	if err := app.UserRepo.DeleteDevice(deviceID); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	log.Printf("Admin %s revoked device %s for user %s", admin.UserID, deviceID, targetUserID)
+
+	app.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"message":  "Device revoked",
+		"userId":   targetUserID,
+		"deviceId": deviceID,
+	})
+}
+
+// GET /v1/colors/random - Get a random color palette
+func (app *Application) getRandomColor(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Fetch a palette seeded with a random color
+	colorResponse, err := app.ColorAPI.GetRandomScheme()
+	if err != nil {
+		app.colorAPIError(w, r, err)
+		return
+	}
+
+	// Return the color palette
+	app.writeJSON(w, http.StatusOK, colorResponse)
+}
+
+// GET /v1/colors/daily - Get today's daily color
+func (app *Application) getDailyColor(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Get today's color from database
+	dailyColor, err := app.DailyColorRepo.GetToday()
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	// Format response
+	response := models.DailyColorResponse{
+		Date:       dailyColor.Date.Format("2006-01-02"),
+		ColorName:  dailyColor.ColorName,
+		RGB:        fmt.Sprintf("rgb(%d,%d,%d)", dailyColor.R, dailyColor.G, dailyColor.B),
+		Hex:        fmt.Sprintf("#%02X%02X%02X", dailyColor.R, dailyColor.G, dailyColor.B),
+		SchemeMode: dailyColor.SchemeMode,
+		Difficulty: dailyColor.Difficulty,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// answerHidden reports whether date's exact color must stay hidden from the user: the day still accepts
+// their guesses and they have attempts left. Which days accept guesses is decided by submissionDate, so
+// near midnight this agrees with submitScore about which day it is, grace window included.
+func (app *Application) answerHidden(userID string, date, now time.Time) (bool, error) {
+	if _, err := app.submissionDate(date.Format("2006-01-02"), now); err != nil {
+		return false, nil
+	}
+
+	attemptsUsed, err := app.DailyScoreRepo.GetUserAttemptCount(userID, date)
+	if err != nil {
+		return false, err
+	}
+	maxAttempts, _, err := app.dailyAttemptAllowance(userID, date)
+	if err != nil {
+		return false, err
+	}
+	return attemptsUsed < maxAttempts, nil
+}
+
+// GET /v1/colors/daily/answer - Reveal a day's exact color once the user has used all their attempts,
+// or for any day that no longer accepts guesses
+func (app *Application) getDailyColorAnswer(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	user, err := app.getUserFromToken(w, r)
+	if err != nil {
+		return
+	}
+
+	now := time.Now()
+	normalizedToday := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	date := normalizedToday
+	if value := r.URL.Query().Get("date"); value != "" {
+		parsed, err := parseEventDate("date", value)
+		if err != nil {
+			app.badRequest(w, r, err)
+			return
+		}
+		date = time.Date(parsed.Year(), parsed.Month(), parsed.Day(), 0, 0, 0, 0, now.Location())
+	}
+	if date.After(normalizedToday) {
+		app.badRequest(w, r, errors.New("date must not be in the future"))
+		return
+	}
+
+	hidden, err := app.answerHidden(user.UserID, date, now)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	if hidden {
+		app.forbidden(w, r, errors.New("finish your attempts first"))
+		return
+	}
+
+	dailyColor, err := app.DailyColorRepo.GetByDate(date)
+	if err != nil {
+		if _, ok := err.(datastore.NoRowsError); ok {
+			http.Error(w, "Daily color not found", http.StatusNotFound)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.writeJSON(w, http.StatusOK, models.DailyColorResponse{
+		Date:       dailyColor.Date.Format("2006-01-02"),
+		ColorName:  dailyColor.ColorName,
+		RGB:        fmt.Sprintf("rgb(%d,%d,%d)", dailyColor.R, dailyColor.G, dailyColor.B),
+		Hex:        fmt.Sprintf("#%02X%02X%02X", dailyColor.R, dailyColor.G, dailyColor.B),
+		SchemeMode: dailyColor.SchemeMode,
+		Difficulty: dailyColor.Difficulty,
+	})
+}
+
+// GET /v1/colors/daily/palette - Get the palette for today's color in the day's scheme mode
+func (app *Application) getDailyPalette(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	dailyColor, err := app.DailyColorRepo.GetToday()
+	if err != nil {
+		if _, ok := err.(datastore.NoRowsError); ok {
+			http.Error(w, "Daily color not found", http.StatusNotFound)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	palette, err := app.ColorAPI.GetSchemeWithMode(dailyColor.R, dailyColor.G, dailyColor.B, dailyColor.SchemeMode)
+	if err != nil {
+		app.colorAPIError(w, r, err)
+		return
+	}
+
+	app.writeJSON(w, http.StatusOK, palette)
+}
+
+// GET /v1/colors/daily/all - Get a page of past daily colors, newest first
+func (app *Application) getAllDailyColors(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	from, to, err := app.parseArchiveRange(r)
+	if err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+	limit, err := parseLimitParam(r, app.Config.DailyColorArchiveDays, maxDailyColorPageSize)
+	if err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+	offset, err := parseOffsetParam(r)
+	if err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+
+	dailyColors, total, err := app.DailyColorRepo.GetAll(from, to, limit, offset)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	// Format response
+	var responses []models.DailyColorResponse
+	for _, dc := range dailyColors {
+		responses = append(responses, models.DailyColorResponse{
+			Date:       dc.Date.Format("2006-01-02"),
+			ColorName:  dc.ColorName,
+			RGB:        fmt.Sprintf("rgb(%d,%d,%d)", dc.R, dc.G, dc.B),
+			Hex:        fmt.Sprintf("#%02X%02X%02X", dc.R, dc.G, dc.B),
+			Difficulty: dc.Difficulty,
+		})
+	}
+
+	w.Header().Set("X-Total-Count", strconv.Itoa(total))
+	app.writeList(w, "", responses, total)
+}
+
+// maxDailyColorPageSize caps how many archived colors one request can return
+const maxDailyColorPageSize = 366
+
+// parseArchiveRange reads the optional from/to dates for the color archive. Without from, the range
+// covers the DailyColorArchiveDays days ending at to, which itself defaults to today
+func (app *Application) parseArchiveRange(r *http.Request) (time.Time, time.Time, error) {
+	now := time.Now()
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	if value := r.URL.Query().Get("to"); value != "" {
+		parsed, err := parseEventDate("to", value)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		to = parsed
+	}
+
+	from := to.AddDate(0, 0, -(app.Config.DailyColorArchiveDays - 1))
+	if value := r.URL.Query().Get("from"); value != "" {
+		parsed, err := parseEventDate("from", value)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		from = parsed
+	}
+
+	if to.Before(from) {
+		return time.Time{}, time.Time{}, errors.New("to must not be before from")
+	}
+	return from, to, nil
+}
+
+// parseOffsetParam reads the optional offset query parameter, defaulting to 0
+func parseOffsetParam(r *http.Request) (int, error) {
+	value := r.URL.Query().Get("offset")
+	if value == "" {
+		return 0, nil
+	}
+	offset, err := strconv.Atoi(value)
+	if err != nil || offset < 0 {
+		return 0, errors.New("offset must be a non-negative integer")
+	}
+	return offset, nil
+}
+
+// colorScoring is the single source of the scoring parameters: calculateColorScore reads them and
+// GET /v1/game/scoring serves them, so clients always replicate the live formula
+var colorScoring = models.ScoringParameters{
+	Mode:  "euclidean_rgb",
+	Curve: "linear",
+	// Maximum possible distance in RGB space is sqrt(255^2 + 255^2 + 255^2) ≈ 441.67
+	MaxDistance: 441.67,
+	MaxScore:    100,
+	Formula:     "clamp(round((1 - sqrt(dr^2 + dg^2 + db^2) / max_distance) * max_score), 0, max_score)",
+}
+
+// calculateColorScore calculates a score (0-100) based on color similarity
+// Uses Euclidean distance in RGB space, normalized to 0-100
+func calculateColorScore(targetR, targetG, targetB, submittedR, submittedG, submittedB int) int {
+	// Calculate Euclidean distance
+	distance := math.Sqrt(
+		math.Pow(float64(targetR-submittedR), 2) +
+			math.Pow(float64(targetG-submittedG), 2) +
+			math.Pow(float64(targetB-submittedB), 2),
+	)
+
+	// Convert distance to score (0-100, where 100 is perfect match)
+	score := int(math.Round((1 - (distance / colorScoring.MaxDistance)) * float64(colorScoring.MaxScore)))
+
+	// Ensure score is within bounds
+	if score < 0 {
+		score = 0
+	}
+	if score > colorScoring.MaxScore {
+		score = colorScoring.MaxScore
+	}
+
+	return score
+}
+
+// GET /v1/game/scoring - The parameters of the scoring formula, for client-side score estimates
+func (app *Application) getScoringParameters(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	app.writeJSON(w, http.StatusOK, colorScoring)
+}
+
+// scoreMessage describes how close a score is to the target
+func scoreMessage(score int) string {
+	if score == 100 {
+		return "Perfect match! You got the exact color!"
+	} else if score >= 90 {
+		return "Excellent! Very close!"
+	} else if score >= 75 {
+		return "Great job! Pretty close!"
+	} else if score >= 50 {
+		return "Not bad! Keep trying!"
+	}
+	return "Keep practicing!"
+}
+
+// POST /v1/scores/preview - Score a color against today's target without using an attempt
+func (app *Application) previewScore(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		app.requirePostMethod(w, r, ErrPOST)
+		return
+	}
+
+	// Auth is required so anonymous clients can't probe today's target
+	if _, err := app.getUserFromToken(w, r); err != nil {
+		return
+	}
+
+	var submission models.ScoreSubmissionRequest
+	if err := json.NewDecoder(r.Body).Decode(&submission); err != nil {
+		app.badJSONRequest(w, r, err)
+		return
+	}
+
+	// Validate RGB values
+	var v validator
+	checkSubmittedColor(&v, submission)
+	if !v.valid() {
+		app.validationFailed(w, r, &v)
+		return
+	}
+
+	dailyColor, err := app.DailyColorRepo.GetToday()
+	if err != nil {
+		app.internalServerError(w, r, errors.New("no daily color available for today"))
+		return
+	}
+
+	// Nothing is written: no daily_score row, no attempt consumed, no leaderboard change
+	score := calculateColorScore(
+		dailyColor.R, dailyColor.G, dailyColor.B,
+		submission.SubmittedColorR, submission.SubmittedColorG, submission.SubmittedColorB,
+	)
+
+	app.writeJSON(w, http.StatusOK, models.ScorePreviewResponse{
+		Score:          score,
+		SubmittedColor: fmt.Sprintf("rgb(%d,%d,%d)", submission.SubmittedColorR, submission.SubmittedColorG, submission.SubmittedColorB),
+		Message:        scoreMessage(score),
+	})
+}
+
+// submissionDate returns the game day a score submission counts towards. An empty date means today.
+// Yesterday is accepted only within ScoreGraceMinutes of midnight, so players who started just before
+// rollover can finish; its attempts, leaderboard entry and rewards all stay on yesterday.
+func (app *Application) submissionDate(value string, now time.Time) (time.Time, error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	if value == "" {
+		return today, nil
+	}
+
+	date, err := time.ParseInLocation("2006-01-02", value, now.Location())
+	if err != nil {
+		return time.Time{}, errors.New("date must be in YYYY-MM-DD format")
+	}
+	if date.Equal(today) {
+		return today, nil
+	}
+
+	yesterday := today.AddDate(0, 0, -1)
+	graceEnds := today.Add(time.Duration(app.Config.ScoreGraceMinutes) * time.Minute)
+	if date.Equal(yesterday) && now.Before(graceEnds) {
+		return yesterday, nil
+	}
+	return time.Time{}, errors.New("guesses can only be submitted for today")
+}
+
+// dailyAttemptAllowance returns how many attempts a user has on date, and how many of those came from
+// extra attempt modifiers. Every handler that reports or enforces attempts goes through here so the
+// level bonus, purchased extras and the hard cap are always applied the same way.
+func (app *Application) dailyAttemptAllowance(userID string, date time.Time) (int, int, error) {
+	extraAttempts := 0
+	modifier, err := app.DailyScoreRepo.GetDailyAttemptModifier(userID, date)
+	if err == nil {
+		extraAttempts = modifier.ExtraAttempts
+	} else if _, ok := err.(datastore.NoRowsError); !ok {
+		return 0, 0, err
+	}
+
+	levelBonus := 0
+	if len(app.Config.LevelAttemptBonus) > 0 {
+		user, err := app.UserRepo.Get(userID)
+		if err != nil {
+			return 0, 0, err
+		}
+		levelBonus = levelAttemptBonus(app.Config.LevelAttemptBonus, user.Level)
+	}
+
+	maxAttempts := app.settingInt(models.SettingBaseDailyAttempts) + levelBonus + extraAttempts
+	if maxAttempts > maxDailyAttempts {
+		maxAttempts = maxDailyAttempts
+	}
+	return maxAttempts, extraAttempts, nil
+}
+
+// levelAttemptBonus returns how many extra base attempts a level has earned: one for each threshold
+// in levels it has reached
+func levelAttemptBonus(levels []int, level int) int {
+	bonus := 0
+	for _, threshold := range levels {
+		if level >= threshold {
+			bonus++
+		}
+	}
+	return bonus
+}
+
+// missingColorGeneration is held while an on-demand daily color generation is running, so a burst of
+// submissions against a missing color triggers one color API call rather than one per request
+var missingColorGeneration sync.Mutex
+
+// generateMissingDailyColor starts generating today's color in the background, unless a generation is
+// already running. The scheduler skips the work if the color exists by the time it runs.
+func (app *Application) generateMissingDailyColor() {
+	if app.Scheduler == nil || !missingColorGeneration.TryLock() {
+		return
+	}
+	go func() {
+		defer missingColorGeneration.Unlock()
+		if err := app.Scheduler.GenerateDailyColor(); err != nil {
+			log.Printf("On-demand daily color generation failed: %v", err)
+		}
+	}()
+}
+
+// POST /v1/scores/submit - Submit a score attempt
+func (app *Application) submitScore(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		app.requirePostMethod(w, r, ErrPOST)
+		return
+	}
+
+	// Get current user from token
+	user, err := app.getUserFromToken(w, r)
+	if err != nil {
+		return
+	}
+
+	// Parse submission
+	var submission models.ScoreSubmissionRequest
+	if err := json.NewDecoder(r.Body).Decode(&submission); err != nil {
+		app.badJSONRequest(w, r, err)
+		return
+	}
+
+	// Validate RGB values
+	var v validator
+	checkSubmittedColor(&v, submission)
+	if !v.valid() {
+		app.validationFailed(w, r, &v)
+		return
+	}
+
+	// Work out which day the guess is for; everything below is recorded under that day
+	now := time.Now()
+	normalizedToday := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	gameDate, err := app.submissionDate(submission.Date, now)
+	if err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+
+	dailyColor, err := app.DailyColorRepo.GetByDate(gameDate)
+	if err != nil {
+		if _, ok := err.(datastore.NoRowsError); ok {
+			// Only today's color is generated on demand; a missing past color won't appear
+			if gameDate.Before(normalizedToday) {
+				http.Error(w, "Daily color not found", http.StatusNotFound)
+				return
+			}
+			app.generateMissingDailyColor()
+			app.dailyColorNotReady(w, r)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	maxAttempts, _, err := app.dailyAttemptAllowance(user.UserID, gameDate)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	// Calculate score
+	score := calculateColorScore(
+		dailyColor.R, dailyColor.G, dailyColor.B,
+		submission.SubmittedColorR, submission.SubmittedColorG, submission.SubmittedColorB,
+	)
+
+	// Create daily score entry
+	dailyColorID := dailyColor.ID
+	dailyScore := models.DailyScore{
+		UserID:          user.UserID,
+		DailyColorID:    &dailyColorID,
+		Date:            gameDate,
+		Score:           score,
+		SubmittedColorR: submission.SubmittedColorR,
+		SubmittedColorG: submission.SubmittedColorG,
+		SubmittedColorB: submission.SubmittedColorB,
+		TargetColorR:    dailyColor.R,
+		TargetColorG:    dailyColor.G,
+		TargetColorB:    dailyColor.B,
+		CreatedAt:       time.Now(),
+	}
+
+	// Save the score as the next attempt; numbering and the cap are enforced atomically
+	savedScore, err := app.DailyScoreRepo.CreateNextAttempt(dailyScore, maxAttempts)
+	if err != nil {
+		if errors.Is(err, datastore.ErrMaxAttemptsReached) {
+			http.Error(w, fmt.Sprintf("Maximum attempts (%d) reached for today", maxAttempts), http.StatusBadRequest)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	// Get user's best score for today
+	existingLeaderboard, err := app.DailyLeaderboardRepo.GetByUserAndDate(user.UserID, gameDate)
+	hasExistingLeaderboard := true
+	if err != nil {
+		if _, ok := err.(datastore.NoRowsError); ok {
+			hasExistingLeaderboard = false
+		} else {
+			app.internalServerError(w, r, err)
+			return
+		}
+	}
+
+	// Only attempts scored against the current daily color count towards the best score,
+	// so a color regenerated mid-day can't leave a stale best on the leaderboard
+	bestAttempt, err := app.DailyScoreRepo.GetUserBestScoreForColor(user.UserID, gameDate, dailyColor.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	bestScore := bestAttempt.Score
+	bestAttemptsUsed := bestAttempt.AttemptNumber
+	isNewBest := bestAttempt.ID == savedScore.ID
+
+	isStaleLeaderboard := hasExistingLeaderboard &&
+		(existingLeaderboard.BestScore != bestScore || existingLeaderboard.AttemptsUsed != bestAttemptsUsed)
+	if isStaleLeaderboard && !isNewBest {
+		log.Printf("leaderboard entry for user %s on %s does not match daily color %d, recomputing",
+			user.UserID, gameDate.Format("2006-01-02"), dailyColor.ID)
+	}
+
+	// Update leaderboard if this is the best score or the existing entry is stale
+	if isNewBest || isStaleLeaderboard {
 		leaderboardEntry := models.DailyLeaderboard{
 			UserID:       user.UserID,
-			Date:         normalizedToday,
+			Date:         gameDate,
 			BestScore:    bestScore,
 			AttemptsUsed: bestAttemptsUsed,
 			CreatedAt:    time.Now(),
 			UpdatedAt:    time.Now(),
 		}
 
-		_, err = app.DailyLeaderboardRepo.CreateOrUpdate(leaderboardEntry)
+		_, err = app.DailyLeaderboardRepo.CreateOrUpdate(leaderboardEntry)
+		if err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+	}
+
+	if err := app.FriendRepo.RecordFriendActivity(user.UserID, gameDate, bestScore, bestAttemptsUsed); err != nil {
+		log.Printf("failed to record friend activity for user %s: %v", user.UserID, err)
+	}
+
+	// Build response
+	attemptsLeft := maxAttempts - savedScore.AttemptNumber
+	message := scoreMessage(score)
+
+	// Reward events boost what the day is worth
+	rewardMultiplier, rewardEventName, err := app.rewardMultiplier(gameDate)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	pointsAwarded := 0
+	creditsAwarded := 0
+	if attemptsLeft == 0 {
+		message += " No more attempts left for today."
+
+		rewards := calculateDailyRewards(user.Points, bestScore, rewardMultiplier, app.rewardRates(), app.levelCurve())
+		pointsAwarded = rewards.PointsAwarded
+		creditsAwarded = rewards.CreditsAwarded
+
+		user.Points += rewards.PointsAwarded
+		user.Level += rewards.LevelUps
+		user.Credits += rewards.CreditsAwarded
+		user.UpdatedAt = time.Now()
+
+		if _, err := app.UserRepo.Update(user); err != nil {
+			app.internalServerError(w, r, fmt.Errorf("failed to finalize daily rewards: %v", err))
+			return
+		}
+	}
+
+	response := models.ScoreSubmissionResponse{
+		Date:             gameDate.Format("2006-01-02"),
+		Score:            score,
+		AttemptNumber:    savedScore.AttemptNumber,
+		AttemptsLeft:     attemptsLeft,
+		MaxAttempts:      maxAttempts,
+		BestScore:        bestScore,
+		IsNewBest:        isNewBest,
+		SubmittedColor:   fmt.Sprintf("rgb(%d,%d,%d)", submission.SubmittedColorR, submission.SubmittedColorG, submission.SubmittedColorB),
+		TargetColor:      fmt.Sprintf("rgb(%d,%d,%d)", dailyColor.R, dailyColor.G, dailyColor.B),
+		Message:          message,
+		RewardMultiplier: rewardMultiplier,
+		RewardEvent:      rewardEventName,
+		PointsAwarded:    pointsAwarded,
+		CreditsAwarded:   creditsAwarded,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// defaultLeaderboardLimit is the number of leaderboard entries returned when no limit is given
+const defaultLeaderboardLimit = 100
+
+// parseLimitParam reads the ?limit query param, falling back to defaultLimit and clamping to [1, maxLimit]
+func parseLimitParam(r *http.Request, defaultLimit, maxLimit int) (int, error) {
+	limit := defaultLimit
+
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil {
+			return 0, errors.New("limit must be an integer")
+		}
+		limit = parsed
+	}
+
+	if limit < 1 {
+		limit = 1
+	}
+	if maxLimit > 0 && limit > maxLimit {
+		limit = maxLimit
+	}
+
+	return limit, nil
+}
+
+// GET /v1/leaderboard - Get today's leaderboard, or a past day's final standings with ?date=
+func (app *Application) getLeaderboard(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	limit, err := parseLimitParam(r, defaultLeaderboardLimit, app.Config.LeaderboardMaxLimit)
+	if err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	date := today
+	if value := r.URL.Query().Get("date"); value != "" {
+		parsed, err := parseEventDate("date", value)
+		if err != nil {
+			app.badRequest(w, r, err)
+			return
+		}
+		date = time.Date(parsed.Year(), parsed.Month(), parsed.Day(), 0, 0, 0, 0, now.Location())
+	}
+	if date.After(today) {
+		app.badRequest(w, r, errors.New("date must not be in the future"))
+		return
+	}
+
+	// Past days come from their frozen snapshot; a day that missed its rollover falls back to the live table
+	var leaderboard []models.LeaderboardEntry
+	final := false
+	if date.Before(today) {
+		leaderboard, final, err = app.DailyLeaderboardRepo.GetFinalLeaderboardByDate(date, limit)
 		if err != nil {
 			app.internalServerError(w, r, err)
 			return
 		}
 	}
-
-	if err := app.FriendRepo.RecordFriendActivity(user.UserID, normalizedToday, bestScore, bestAttemptsUsed); err != nil {
-		log.Printf("failed to record friend activity for user %s: %v", user.UserID, err)
+	if !final {
+		leaderboard, err = app.DailyLeaderboardRepo.GetLeaderboardByDate(date, limit)
+		if err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
 	}
 
-	// Build response
-	attemptsLeft := maxAttempts - savedScore.AttemptNumber
-	message := ""
-
-	if score == 100 {
-		message = "Perfect match! You got the exact color!"
-	} else if score >= 90 {
-		message = "Excellent! Very close!"
-	} else if score >= 75 {
-		message = "Great job! Pretty close!"
-	} else if score >= 50 {
-		message = "Not bad! Keep trying!"
-	} else {
-		message = "Keep practicing!"
+	// Percentiles are relative to everyone who played that day, not just this page
+	totalPlayers, err := app.DailyLeaderboardRepo.CountPlayersByDate(date, final)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	for i := range leaderboard {
+		leaderboard[i].Percentile = rankPercentile(leaderboard[i].Rank, totalPlayers)
 	}
 
-	if attemptsLeft == 0 {
-		message += " No more attempts left for today."
-
-		pointsAward := bestScore
-		newTotalPoints := user.Points + pointsAward
-		prevMilestones := user.Points / 1000
-		newMilestones := newTotalPoints / 1000
-		levelUps := newMilestones - prevMilestones
-		if levelUps < 0 {
-			levelUps = 0
+	// Attach equipped hats and skins so the UI can render avatars, in one lookup for the whole page
+	userIDs := make([]string, 0, len(leaderboard))
+	for _, entry := range leaderboard {
+		userIDs = append(userIDs, entry.UserID)
+	}
+	cosmetics, err := app.ShopRepo.GetEquippedCosmeticsForUsers(userIDs)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	for i := range leaderboard {
+		leaderboard[i].Cosmetics = cosmetics[leaderboard[i].UserID]
+		if leaderboard[i].Cosmetics == nil {
+			leaderboard[i].Cosmetics = []models.EquippedCosmetic{}
 		}
+	}
 
-		if levelUps > 0 {
-			user.Level += levelUps
-		}
+	w.Header().Set("X-Leaderboard-Limit", strconv.Itoa(limit))
+	w.Header().Set("X-Leaderboard-Final", strconv.FormatBool(final))
+	w.Header().Set("X-Total-Count", strconv.Itoa(totalPlayers))
+	app.writeList(w, "", leaderboard, totalPlayers)
+}
 
-		user.Points = newTotalPoints
+const (
+	// recentActivityMinScore is the lowest score worth announcing in the activity feed
+	recentActivityMinScore     = 90
+	defaultRecentActivityLimit = 20
+	maxRecentActivityLimit     = 50
+)
 
-		creditAward := int(math.Ceil(float64(bestScore) / 2.0))
-		user.Credits += creditAward
-		user.UpdatedAt = time.Now()
+// GET /v1/activity/recent - Today's newest high scores, excluding users who opted out
+func (app *Application) getRecentActivity(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
 
-		if _, err := app.UserRepo.Update(user); err != nil {
-			app.internalServerError(w, r, fmt.Errorf("failed to finalize daily rewards: %v", err))
-			return
-		}
+	limit, err := parseLimitParam(r, defaultRecentActivityLimit, maxRecentActivityLimit)
+	if err != nil {
+		app.badRequest(w, r, err)
+		return
 	}
 
-	response := models.ScoreSubmissionResponse{
-		Score:          score,
-		AttemptNumber:  savedScore.AttemptNumber,
-		AttemptsLeft:   attemptsLeft,
-		MaxAttempts:    maxAttempts,
-		BestScore:      bestScore,
-		IsNewBest:      isNewBest,
-		SubmittedColor: fmt.Sprintf("rgb(%d,%d,%d)", submission.SubmittedColorR, submission.SubmittedColorG, submission.SubmittedColorB),
-		TargetColor:    fmt.Sprintf("rgb(%d,%d,%d)", dailyColor.R, dailyColor.G, dailyColor.B),
-		Message:        message,
+	scores, err := app.DailyScoreRepo.GetRecentHighScores(time.Now(), recentActivityMinScore, limit)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(response)
+	app.writeList(w, "activity", scores, len(scores))
 }
 
-// GET /v1/leaderboard - Get today's leaderboard
-func (app *Application) getLeaderboard(w http.ResponseWriter, r *http.Request) {
+// perfectScore is the highest score calculateColorScore can return
+const perfectScore = 100
+
+// GET /v1/game/status - Whether the user can play today, with their attempts and best score so far
+func (app *Application) getGameStatus(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	// Get today's leaderboard (top 100)
-	today := time.Now()
-	leaderboard, err := app.DailyLeaderboardRepo.GetLeaderboardByDate(today, 100)
+	user, err := app.getUserFromToken(w, r)
+	if err != nil {
+		return
+	}
+
+	// The game day rolls over at local midnight, when the scheduler generates the next color
+	now := time.Now()
+	normalizedToday := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	nextRollover := normalizedToday.AddDate(0, 0, 1)
+
+	colorAvailable := true
+	if _, err := app.DailyColorRepo.GetByDate(normalizedToday); err != nil {
+		if _, ok := err.(datastore.NoRowsError); !ok {
+			app.internalServerError(w, r, err)
+			return
+		}
+		colorAvailable = false
+	}
+
+	attemptsUsed, err := app.DailyScoreRepo.GetUserAttemptCount(user.UserID, normalizedToday)
 	if err != nil {
 		app.internalServerError(w, r, err)
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(leaderboard)
+	maxAttempts, _, err := app.dailyAttemptAllowance(user.UserID, normalizedToday)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	bestScore := 0
+	leaderboardEntry, err := app.DailyLeaderboardRepo.GetByUserAndDate(user.UserID, normalizedToday)
+	if err == nil {
+		bestScore = leaderboardEntry.BestScore
+	} else if _, ok := err.(datastore.NoRowsError); !ok {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	attemptsLeft := maxAttempts - attemptsUsed
+	if attemptsLeft < 0 {
+		attemptsLeft = 0
+	}
+
+	app.writeJSON(w, http.StatusOK, models.GameStatus{
+		Date:                 normalizedToday.Format("2006-01-02"),
+		ColorAvailable:       colorAvailable,
+		CanPlay:              colorAvailable && attemptsLeft > 0,
+		AttemptsUsed:         attemptsUsed,
+		AttemptsLeft:         attemptsLeft,
+		MaxAttempts:          maxAttempts,
+		BestScore:            bestScore,
+		HasPerfectScore:      bestScore >= perfectScore,
+		SecondsUntilRollover: int(math.Ceil(nextRollover.Sub(now).Seconds())),
+	})
 }
 
 // GET /v1/scores/history - Get user's score history
@@ -651,20 +1596,12 @@ func (app *Application) getUserScoreHistory(w http.ResponseWriter, r *http.Reque
 		}
 	}
 
-	extraAttempts := 0
-	modifier, err := app.DailyScoreRepo.GetDailyAttemptModifier(user.UserID, normalizedToday)
-	if err == nil {
-		extraAttempts = modifier.ExtraAttempts
-	} else if _, ok := err.(datastore.NoRowsError); !ok {
+	maxAttempts, extraAttempts, err := app.dailyAttemptAllowance(user.UserID, normalizedToday)
+	if err != nil {
 		app.internalServerError(w, r, err)
 		return
 	}
 
-	maxAttempts := 5 + extraAttempts
-	if maxAttempts > 10 {
-		maxAttempts = 10
-	}
-
 	attemptsLeft := maxAttempts - attemptsUsed
 	if attemptsLeft < 0 {
 		attemptsLeft = 0
@@ -680,8 +1617,139 @@ func (app *Application) getUserScoreHistory(w http.ResponseWriter, r *http.Reque
 		MaxAttempts:   maxAttempts,
 	}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(response)
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GET /v1/scores/attempts - Page through all of the user's attempts, newest first
+func (app *Application) getScoreAttempts(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	user, err := app.getUserFromToken(w, r)
+	if err != nil {
+		return
+	}
+
+	limit, after, _, err := parsePageParams(r)
+	if err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+	if after != nil {
+		if _, err := strconv.Atoi(after.ID); err != nil {
+			app.badRequest(w, r, errors.New("cursor is invalid"))
+			return
+		}
+	}
+
+	scores, err := app.DailyScoreRepo.GetUserScoreHistory(user.UserID, after, limit+1)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	total, err := app.DailyScoreRepo.CountUserScores(user.UserID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	writePage(app, w, scores, limit, total, func(s models.DailyScore) models.PageCursor {
+		return models.PageCursor{At: s.CreatedAt, ID: strconv.Itoa(s.ID)}
+	})
+}
+
+// GET /v1/scores/attempts/{id}/breakdown - How far off each RGB channel of one of the user's attempts was.
+// Attempts on a day that can still be played stay hidden until the user is out of attempts for it.
+func (app *Application) getAttemptBreakdown(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	user, err := app.getUserFromToken(w, r)
+	if err != nil {
+		return
+	}
+
+	scoreID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		app.badRequest(w, r, errors.New("attempt id must be a number"))
+		return
+	}
+
+	score, err := app.DailyScoreRepo.GetUserScoreByID(user.UserID, scoreID)
+	if err != nil {
+		if _, ok := err.(datastore.NoRowsError); ok {
+			http.Error(w, "Attempt not found", http.StatusNotFound)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	// The deltas give the target away, so they follow the same rule as the daily answer
+	if gameDate, err := app.submissionDate(score.Date.Format("2006-01-02"), time.Now()); err == nil {
+		attemptsUsed, err := app.DailyScoreRepo.GetUserAttemptCount(user.UserID, gameDate)
+		if err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+		maxAttempts, _, err := app.dailyAttemptAllowance(user.UserID, gameDate)
+		if err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+		if attemptsUsed < maxAttempts {
+			app.forbidden(w, r, errors.New("finish your attempts first"))
+			return
+		}
+	}
+
+	app.writeJSON(w, http.StatusOK, attemptBreakdown(score))
+}
+
+// attemptBreakdown computes the per-channel deltas of an attempt. Ties for the worst channel go to
+// the first in R, G, B order.
+func attemptBreakdown(score models.DailyScore) models.AttemptBreakdown {
+	breakdown := models.AttemptBreakdown{
+		AttemptID:     score.ID,
+		Date:          score.Date.Format("2006-01-02"),
+		AttemptNumber: score.AttemptNumber,
+		Score:         score.Score,
+	}
+
+	channels := []struct {
+		name              string
+		submitted, target int
+	}{
+		{"r", score.SubmittedColorR, score.TargetColorR},
+		{"g", score.SubmittedColorG, score.TargetColorG},
+		{"b", score.SubmittedColorB, score.TargetColorB},
+	}
+
+	worst := 0
+	for _, c := range channels {
+		delta := c.submitted - c.target
+		absDelta := delta
+		if absDelta < 0 {
+			absDelta = -absDelta
+		}
+		breakdown.Channels = append(breakdown.Channels, models.ChannelDelta{
+			Channel:   c.name,
+			Submitted: c.submitted,
+			Target:    c.target,
+			Delta:     delta,
+			AbsDelta:  absDelta,
+		})
+		if absDelta > worst {
+			worst = absDelta
+			breakdown.WorstChannel = c.name
+		}
+	}
+
+	return breakdown
 }
 
 type resetAttemptsRequest struct {
@@ -729,35 +1797,71 @@ func (app *Application) resetUserDailyAttempts(w http.ResponseWriter, r *http.Re
 
 	normalizedDate := time.Date(targetDate.Year(), targetDate.Month(), targetDate.Day(), 0, 0, 0, 0, targetDate.Location())
 
-	scoresDeleted, err := app.DailyScoreRepo.DeleteUserScoresByDate(req.UserID, normalizedDate)
+	response, err := app.resetDailyAttempts(req.UserID, normalizedDate)
 	if err != nil {
 		app.internalServerError(w, r, err)
 		return
 	}
 
-	leaderboardRows, err := app.DailyLeaderboardRepo.DeleteByUserAndDate(req.UserID, normalizedDate)
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// POST /v1/scores/reset - Reset the caller's own attempts for today (dev mode only)
+func (app *Application) resetOwnDailyAttempts(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		app.requirePostMethod(w, r, ErrPOST)
+		return
+	}
+
+	// Replaying the day is a QA convenience; in production it would let players farm best scores
+	if !app.Config.DevMode {
+		app.forbidden(w, r, errors.New("resetting your own attempts is only available in dev mode"))
+		return
+	}
+
+	user, err := app.getUserFromToken(w, r)
+	if err != nil {
+		return
+	}
+
+	today := time.Now()
+	normalizedToday := time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, today.Location())
+
+	response, err := app.resetDailyAttempts(user.UserID, normalizedToday)
 	if err != nil {
 		app.internalServerError(w, r, err)
 		return
 	}
 
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// resetDailyAttempts clears a user's scores, leaderboard entry and friend activity for a date
+func (app *Application) resetDailyAttempts(userID string, date time.Time) (resetAttemptsResponse, error) {
+	scoresDeleted, err := app.DailyScoreRepo.DeleteUserScoresByDate(userID, date)
+	if err != nil {
+		return resetAttemptsResponse{}, err
+	}
+
+	leaderboardRows, err := app.DailyLeaderboardRepo.DeleteByUserAndDate(userID, date)
+	if err != nil {
+		return resetAttemptsResponse{}, err
+	}
+
 	friendActivityReset := false
-	if err := app.FriendRepo.RecordFriendActivity(req.UserID, normalizedDate, 0, 0); err == nil {
+	if err := app.FriendRepo.RecordFriendActivity(userID, date, 0, 0); err == nil {
 		friendActivityReset = true
 	} else {
-		log.Printf("failed to reset friend activity for user %s: %v", req.UserID, err)
+		log.Printf("failed to reset friend activity for user %s: %v", userID, err)
 	}
 
-	response := resetAttemptsResponse{
-		UserID:              req.UserID,
-		Date:                normalizedDate.Format("2006-01-02"),
+	return resetAttemptsResponse{
+		UserID:              userID,
+		Date:                date.Format("2006-01-02"),
 		ScoresDeleted:       scoresDeleted,
 		LeaderboardCleared:  leaderboardRows > 0,
 		FriendActivityReset: friendActivityReset,
-	}
-
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(response)
+	}, nil
 }
 
 // POST /v1/admin/colors/generate - Manually generate today's color (Admin only)
@@ -776,81 +1880,144 @@ func (app *Application) generateDailyColor(w http.ResponseWriter, r *http.Reques
 	if err == nil && existingColor.ID != 0 {
 		// Color already exists, return it
 		response := models.DailyColorResponse{
-			Date:      existingColor.Date.Format("2006-01-02"),
-			ColorName: existingColor.ColorName,
-			RGB:       fmt.Sprintf("rgb(%d,%d,%d)", existingColor.R, existingColor.G, existingColor.B),
-			Hex:       fmt.Sprintf("#%02X%02X%02X", existingColor.R, existingColor.G, existingColor.B),
+			Date:       existingColor.Date.Format("2006-01-02"),
+			ColorName:  existingColor.ColorName,
+			RGB:        fmt.Sprintf("rgb(%d,%d,%d)", existingColor.R, existingColor.G, existingColor.B),
+			Hex:        fmt.Sprintf("#%02X%02X%02X", existingColor.R, existingColor.G, existingColor.B),
+			Difficulty: existingColor.Difficulty,
 		}
 
-		w.WriteHeader(http.StatusOK)
-		json.NewEncoder(w).Encode(map[string]interface{}{
+		app.writeJSON(w, http.StatusOK, map[string]interface{}{
 			"message": "Daily color already exists for today",
 			"color":   response,
 		})
 		return
 	}
 
-	// Generate random RGB values
-	r1 := rand.Intn(256)
-	g := rand.Intn(256)
-	b := rand.Intn(256)
+	if app.Scheduler == nil {
+		app.internalServerError(w, r, errors.New("scheduler is not configured"))
+		return
+	}
+
+	// Chosen exactly as the nightly run would: curated, then deterministic or random
+	savedColor, err := app.Scheduler.CreateColorForDate(normalizedToday)
+	if err != nil {
+		app.colorAPIError(w, r, err)
+		return
+	}
+
+	// Format response
+	response := models.DailyColorResponse{
+		Date:       savedColor.Date.Format("2006-01-02"),
+		ColorName:  savedColor.ColorName,
+		RGB:        fmt.Sprintf("rgb(%d,%d,%d)", savedColor.R, savedColor.G, savedColor.B),
+		Hex:        fmt.Sprintf("#%02X%02X%02X", savedColor.R, savedColor.G, savedColor.B),
+		Difficulty: savedColor.Difficulty,
+	}
+
+	message := "Successfully generated daily color"
+	if savedColor.Source == models.DailyColorSourceCurated {
+		message = "Successfully generated daily color from the curated queue"
+	}
+
+	app.writeJSON(w, http.StatusCreated, map[string]interface{}{
+		"message": message,
+		"color":   response,
+	})
+}
+
+// maxBackfillDays caps a single backfill so one request can't tie up the color API for hours
+const maxBackfillDays = 366
 
-	// Build the URL for thecolorapi.com
-	url := fmt.Sprintf("https://www.thecolorapi.com/scheme?rgb=%d,%d,%d&mode=analogic&count=6&format=json", r1, g, b)
+// POST /v1/admin/colors/backfill - Generate daily colors for missing days in a date range (Admin only)
+func (app *Application) backfillDailyColors(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		app.requirePostMethod(w, r, ErrPOST)
+		return
+	}
 
-	// Make HTTP request to the color API
-	resp, httpErr := http.Get(url)
-	if httpErr != nil {
-		app.internalServerError(w, r, httpErr)
+	var req models.DailyColorBackfillRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		app.badJSONRequest(w, r, err)
 		return
 	}
-	defer resp.Body.Close()
 
-	// Check status code
-	if resp.StatusCode != http.StatusOK {
-		app.internalServerError(w, r, fmt.Errorf("color API returned status: %d", resp.StatusCode))
+	from, err := time.ParseInLocation("2006-01-02", req.From, time.Local)
+	if err != nil {
+		app.badRequest(w, r, errors.New("from must be in YYYY-MM-DD format"))
+		return
+	}
+	to, err := time.ParseInLocation("2006-01-02", req.To, time.Local)
+	if err != nil {
+		app.badRequest(w, r, errors.New("to must be in YYYY-MM-DD format"))
 		return
 	}
 
-	// Parse the response
-	var colorResponse models.ColorAPIResponse
-	if err := json.NewDecoder(resp.Body).Decode(&colorResponse); err != nil {
-		app.internalServerError(w, r, err)
+	today := time.Now()
+	normalizedToday := time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, today.Location())
+	if from.After(to) {
+		app.badRequest(w, r, errors.New("from must not be after to"))
+		return
+	}
+	if to.After(normalizedToday) {
+		app.badRequest(w, r, errors.New("cannot backfill colors for future dates"))
+		return
+	}
+	if days := int(to.Sub(from).Hours()/24) + 1; days > maxBackfillDays {
+		app.badRequest(w, r, fmt.Errorf("range covers %d days, at most %d can be backfilled at once", days, maxBackfillDays))
+		return
+	}
+
+	if app.Scheduler == nil {
+		app.internalServerError(w, r, errors.New("scheduler is not configured"))
 		return
 	}
 
-	// Use the seed color (the original random color)
-	seedColor := colorResponse.Seed
-	colorName := seedColor.Name.Value
+	// Color API calls are rate limited, so a long range can outlast the server write timeout
+	_ = disableWriteDeadline(w)
+
+	result := app.Scheduler.BackfillDailyColors(from, to)
+
+	app.writeJSON(w, http.StatusOK, result)
+}
 
-	// Create daily color entry
-	dailyColor := models.DailyColor{
-		Date:      normalizedToday,
-		ColorName: colorName,
-		R:         seedColor.RGB.R,
-		G:         seedColor.RGB.G,
-		B:         seedColor.RGB.B,
-		CreatedAt: time.Now(),
+// GET /v1/admin/colors/status - Get today's color generation status (Admin only)
+func (app *Application) getDailyColorStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
 	}
 
-	// Save to database
-	savedColor, saveErr := app.DailyColorRepo.Create(dailyColor)
-	if saveErr != nil {
-		app.internalServerError(w, r, saveErr)
+	today := time.Now()
+	normalizedToday := time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, today.Location())
+
+	status := models.DailyColorStatus{
+		Date: normalizedToday.Format("2006-01-02"),
+	}
+
+	dailyColor, err := app.DailyColorRepo.GetToday()
+	if err == nil {
+		status.Exists = true
+		status.ColorName = dailyColor.ColorName
+		status.Source = dailyColor.Source
+		status.CreatedAt = &dailyColor.CreatedAt
+	} else if _, ok := err.(datastore.NoRowsError); !ok {
+		app.internalServerError(w, r, err)
 		return
 	}
 
-	// Format response
-	response := models.DailyColorResponse{
-		Date:      savedColor.Date.Format("2006-01-02"),
-		ColorName: savedColor.ColorName,
-		RGB:       fmt.Sprintf("rgb(%d,%d,%d)", savedColor.R, savedColor.G, savedColor.B),
-		Hex:       fmt.Sprintf("#%02X%02X%02X", savedColor.R, savedColor.G, savedColor.B),
+	if app.Scheduler != nil {
+		schedulerStatus := app.Scheduler.Status()
+		if !schedulerStatus.NextRunAt.IsZero() {
+			status.NextGenerationAt = &schedulerStatus.NextRunAt
+		}
+		if !schedulerStatus.LastRunAt.IsZero() {
+			status.LastRunAt = &schedulerStatus.LastRunAt
+		}
+		if schedulerStatus.LastError != nil {
+			status.LastRunError = schedulerStatus.LastError.Error()
+		}
 	}
 
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"message": "Successfully generated daily color",
-		"color":   response,
-	})
+	app.writeJSON(w, http.StatusOK, status)
 }
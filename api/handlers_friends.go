@@ -1,15 +1,63 @@
 package api
 
 import (
+	"database/sql"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/color-game/api/datastore"
 	"github.com/color-game/api/models"
 )
 
+// maxFriendSearchQueryLength matches the username column width
+const maxFriendSearchQueryLength = 100
+
+// friendLimitReached writes a 400 and returns true when the user already has Config.MaxFriends friends.
+// A MaxFriends of 0 disables the limit.
+func (app *Application) friendLimitReached(w http.ResponseWriter, r *http.Request, userID string) bool {
+	if app.Config.MaxFriends <= 0 {
+		return false
+	}
+
+	count, err := app.FriendRepo.CountFriends(userID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return true
+	}
+
+	if count >= app.Config.MaxFriends {
+		app.badRequest(w, r, fmt.Errorf("friend limit of %d reached", app.Config.MaxFriends))
+		return true
+	}
+	return false
+}
+
+// friendRequestLimitReached writes a 429 and returns true when the user has already sent
+// Config.MaxDailyFriendRequests requests today that are still pending. 0 disables the limit.
+func (app *Application) friendRequestLimitReached(w http.ResponseWriter, r *http.Request, userID string) bool {
+	if app.Config.MaxDailyFriendRequests <= 0 {
+		return false
+	}
+
+	now := time.Now()
+	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	count, err := app.FriendRepo.CountOutgoingPendingRequests(userID, startOfDay)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return true
+	}
+
+	if count >= app.Config.MaxDailyFriendRequests {
+		app.tooManyRequests(w, r, fmt.Errorf("daily limit of %d pending friend requests reached", app.Config.MaxDailyFriendRequests))
+		return true
+	}
+	return false
+}
+
 // GET /v1/friends
 func (app *Application) getFriends(w http.ResponseWriter, r *http.Request) {
 	user, err := app.getUserFromToken(w, r)
@@ -23,10 +71,20 @@ func (app *Application) getFriends(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"friends": friends,
-	})
+	userIDs := make([]string, 0, len(friends))
+	for _, friend := range friends {
+		userIDs = append(userIDs, friend.Friend.UserID)
+	}
+	cosmetics, err := app.ShopRepo.GetEquippedCosmeticsForUsers(userIDs)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	for i := range friends {
+		friends[i].Friend.Cosmetics = cosmetics[friends[i].Friend.UserID]
+	}
+
+	app.writeList(w, "friends", friends, len(friends))
 }
 
 // GET /v1/friends/requests
@@ -42,10 +100,7 @@ func (app *Application) getFriendRequests(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"requests": requests,
-	})
+	app.writeList(w, "requests", requests, len(requests))
 }
 
 // POST /v1/friends/search
@@ -73,6 +128,10 @@ func (app *Application) searchFriends(w http.ResponseWriter, r *http.Request) {
 		app.badRequest(w, r, errors.New("search query must be at least 2 characters"))
 		return
 	}
+	if len(query) > maxFriendSearchQueryLength {
+		app.badRequest(w, r, fmt.Errorf("search query must be at most %d characters", maxFriendSearchQueryLength))
+		return
+	}
 
 	results, err := app.FriendRepo.SearchUsersForFriend(user.UserID, query, 20)
 	if err != nil {
@@ -80,10 +139,7 @@ func (app *Application) searchFriends(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"results": results,
-	})
+	app.writeList(w, "results", results, len(results))
 }
 
 // POST /v1/friends/request
@@ -111,10 +167,27 @@ func (app *Application) createFriendRequest(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	// Ensure target exists
-	if _, err := app.UserRepo.Get(payload.TargetUserID); err != nil {
+	switch result, err := app.friendRequestTargetResult(user.UserID, payload.TargetUserID); {
+	case err != nil:
+		app.internalServerError(w, r, err)
+		return
+	case result == models.FriendRequestResultInvalid:
+		app.badRequest(w, r, errors.New("cannot send a friend request to yourself"))
+		return
+	case result == models.FriendRequestResultNotFound:
 		app.badRequest(w, r, errors.New("user not found"))
 		return
+	case result == models.FriendRequestResultDuplicate:
+		http.Error(w, "A friend request or friendship with this user already exists", http.StatusConflict)
+		return
+	}
+
+	if app.friendLimitReached(w, r, user.UserID) {
+		return
+	}
+
+	if app.friendRequestLimitReached(w, r, user.UserID) {
+		return
 	}
 
 	friendship, err := app.FriendRepo.CreateFriendRequest(user.UserID, payload.TargetUserID)
@@ -123,8 +196,140 @@ func (app *Application) createFriendRequest(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(friendship)
+	app.writeJSON(w, http.StatusCreated, friendship)
+}
+
+// maxFriendRequestBatch caps how many targets one batch friend request may name
+const maxFriendRequestBatch = 50
+
+// friendRequestTargetResult checks whether userID may send targetID a friend request. It returns an empty
+// result when the request can go ahead, or the result explaining why not.
+func (app *Application) friendRequestTargetResult(userID, targetID string) (string, error) {
+	if targetID == userID {
+		return models.FriendRequestResultInvalid, nil
+	}
+
+	// Ensure target exists
+	if _, err := app.UserRepo.Get(targetID); err != nil {
+		return models.FriendRequestResultNotFound, nil
+	}
+
+	// One friendship row exists per pair, whatever its status
+	if _, err := app.FriendRepo.GetFriendshipBetween(userID, targetID); err == nil {
+		return models.FriendRequestResultDuplicate, nil
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return "", err
+	}
+	return "", nil
+}
+
+// POST /v1/friends/request/batch - Send friend requests to many users at once, with a result per target.
+// Bad targets don't fail the batch; once the friend or daily request limit is hit, the rest are blocked.
+func (app *Application) createFriendRequestBatch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		app.requirePostMethod(w, r, ErrPOST)
+		return
+	}
+
+	user, err := app.getUserFromToken(w, r)
+	if err != nil {
+		return
+	}
+
+	var payload struct {
+		TargetUserIDs []string `json:"targetUserIds"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		app.badJSONRequest(w, r, err)
+		return
+	}
+
+	var v validator
+	v.check(len(payload.TargetUserIDs) > 0, "targetUserIds", "at least one target is required")
+	v.check(len(payload.TargetUserIDs) <= maxFriendRequestBatch, "targetUserIds", fmt.Sprintf("at most %d targets per batch", maxFriendRequestBatch))
+	if !v.valid() {
+		app.validationFailed(w, r, &v)
+		return
+	}
+
+	// Work out the limits once; every request created in the loop uses up one pending slot
+	friendLimitHit := false
+	if app.Config.MaxFriends > 0 {
+		count, err := app.FriendRepo.CountFriends(user.UserID)
+		if err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+		friendLimitHit = count >= app.Config.MaxFriends
+	}
+
+	remainingRequests := -1
+	if app.Config.MaxDailyFriendRequests > 0 {
+		now := time.Now()
+		startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+		pending, err := app.FriendRepo.CountOutgoingPendingRequests(user.UserID, startOfDay)
+		if err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+		remainingRequests = max(app.Config.MaxDailyFriendRequests-pending, 0)
+	}
+
+	results := make([]models.FriendRequestBatchResult, 0, len(payload.TargetUserIDs))
+	seen := make(map[string]bool, len(payload.TargetUserIDs))
+	created := 0
+	for _, targetID := range payload.TargetUserIDs {
+		targetID = strings.TrimSpace(targetID)
+		result := models.FriendRequestBatchResult{TargetUserID: targetID}
+
+		if targetID == "" {
+			result.Result = models.FriendRequestResultInvalid
+			result.Reason = "target user ID is empty"
+			results = append(results, result)
+			continue
+		}
+		if seen[targetID] {
+			result.Result = models.FriendRequestResultDuplicate
+			result.Reason = "listed more than once in this batch"
+			results = append(results, result)
+			continue
+		}
+		seen[targetID] = true
+
+		outcome, err := app.friendRequestTargetResult(user.UserID, targetID)
+		if err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+		switch {
+		case outcome != "":
+			result.Result = outcome
+		case friendLimitHit:
+			result.Result = models.FriendRequestResultBlocked
+			result.Reason = fmt.Sprintf("friend limit of %d reached", app.Config.MaxFriends)
+		case remainingRequests == 0:
+			result.Result = models.FriendRequestResultBlocked
+			result.Reason = fmt.Sprintf("daily limit of %d pending friend requests reached", app.Config.MaxDailyFriendRequests)
+		default:
+			friendship, err := app.FriendRepo.CreateFriendRequest(user.UserID, targetID)
+			if err != nil {
+				app.internalServerError(w, r, err)
+				return
+			}
+			result.Result = models.FriendRequestResultCreated
+			result.Friendship = &friendship
+			created++
+			if remainingRequests > 0 {
+				remainingRequests--
+			}
+		}
+		results = append(results, result)
+	}
+
+	app.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"created": created,
+		"results": results,
+	})
 }
 
 // POST /v1/friends/respond
@@ -165,6 +370,10 @@ func (app *Application) respondToFriendRequest(w http.ResponseWriter, r *http.Re
 		return
 	}
 
+	if newStatus == models.FriendshipStatusAccepted && app.friendLimitReached(w, r, user.UserID) {
+		return
+	}
+
 	friendship, err := app.FriendRepo.UpdateFriendshipStatus(payload.FriendshipID, newStatus)
 	if err != nil {
 		app.internalServerError(w, r, err)
@@ -177,8 +386,7 @@ func (app *Application) respondToFriendRequest(w http.ResponseWriter, r *http.Re
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(friendship)
+	app.writeJSON(w, http.StatusOK, friendship)
 }
 
 // POST /v1/friends/remove
@@ -216,8 +424,7 @@ func (app *Application) removeFriend(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]interface{}{
+	app.writeJSON(w, http.StatusOK, map[string]interface{}{
 		"removedFriendship": friendship,
 	})
 }
@@ -235,8 +442,112 @@ func (app *Application) getFriendActivity(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"activity": activities,
-	})
+	app.writeList(w, "activity", activities, len(activities))
+}
+
+// GET /v1/friends/today - Which friends have played today's game, with their best score
+func (app *Application) getFriendsToday(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	user, err := app.getUserFromToken(w, r)
+	if err != nil {
+		return
+	}
+
+	today := time.Now()
+	entries, err := app.FriendRepo.ListFriendsPlayedOn(user.UserID, today)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	userIDs := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		userIDs = append(userIDs, entry.Friend.UserID)
+	}
+	cosmetics, err := app.ShopRepo.GetEquippedCosmeticsForUsers(userIDs)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	response := models.FriendsTodayResponse{
+		Date:        today.Format("2006-01-02"),
+		FriendCount: len(entries),
+		Played:      []models.FriendDayEntry{},
+		NotPlayed:   []models.FriendDayEntry{},
+	}
+	for _, entry := range entries {
+		entry.Friend.Cosmetics = cosmetics[entry.Friend.UserID]
+		if entry.Played {
+			response.Played = append(response.Played, entry)
+		} else {
+			response.NotPlayed = append(response.NotPlayed, entry)
+		}
+	}
+	response.PlayedCount = len(response.Played)
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// defaultHeadToHeadDays is how far back a head-to-head record looks when no from date is given
+const defaultHeadToHeadDays = 30
+
+// GET /v1/friends/{id}/head-to-head - Compare the caller's daily best scores with a friend's
+func (app *Application) getHeadToHead(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	user, err := app.getUserFromToken(w, r)
+	if err != nil {
+		return
+	}
+
+	friendID := r.PathValue("id")
+	friendship, err := app.FriendRepo.GetFriendshipBetween(user.UserID, friendID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && friendship.Status != models.FriendshipStatusAccepted) {
+		http.Error(w, "Friend not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	now := time.Now()
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	if value := r.URL.Query().Get("to"); value != "" {
+		parsed, err := parseEventDate("to", value)
+		if err != nil {
+			app.badRequest(w, r, err)
+			return
+		}
+		to = time.Date(parsed.Year(), parsed.Month(), parsed.Day(), 0, 0, 0, 0, now.Location())
+	}
+	from := to.AddDate(0, 0, -(defaultHeadToHeadDays - 1))
+	if value := r.URL.Query().Get("from"); value != "" {
+		parsed, err := parseEventDate("from", value)
+		if err != nil {
+			app.badRequest(w, r, err)
+			return
+		}
+		from = time.Date(parsed.Year(), parsed.Month(), parsed.Day(), 0, 0, 0, 0, now.Location())
+	}
+	if to.Before(from) {
+		app.badRequest(w, r, errors.New("to must not be before from"))
+		return
+	}
+
+	record, err := app.DailyLeaderboardRepo.GetHeadToHead(user.UserID, friendID, from, to)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.writeJSON(w, http.StatusOK, record)
 }
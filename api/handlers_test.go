@@ -0,0 +1,579 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/color-game/api/datastore"
+	"github.com/color-game/api/models"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestLevelAttemptBonus(t *testing.T) {
+	thresholds := []int{10, 25, 50}
+
+	tests := []struct {
+		name   string
+		levels []int
+		level  int
+		want   int
+	}{
+		{"no thresholds configured", nil, 99, 0},
+		{"below the first threshold", thresholds, 9, 0},
+		{"exactly at a threshold counts", thresholds, 10, 1},
+		{"between thresholds", thresholds, 30, 2},
+		{"past every threshold", thresholds, 80, 3},
+		{"level one", thresholds, 1, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := levelAttemptBonus(tt.levels, tt.level); got != tt.want {
+				t.Errorf("levelAttemptBonus(%v, %d) = %d, want %d", tt.levels, tt.level, got, tt.want)
+			}
+		})
+	}
+}
+
+// estimateScore is what a client does with the parameters from GET /v1/game/scoring
+func estimateScore(params models.ScoringParameters, dr, dg, db int) int {
+	distance := math.Sqrt(float64(dr*dr + dg*dg + db*db))
+	score := math.Round((1 - distance/params.MaxDistance) * float64(params.MaxScore))
+	return int(math.Max(0, math.Min(score, float64(params.MaxScore))))
+}
+
+func TestCalculateColorScoreMatchesPublishedParameters(t *testing.T) {
+	tests := []struct {
+		name              string
+		target, submitted [3]int
+		want              int
+	}{
+		{"exact match scores the maximum", [3]int{120, 45, 200}, [3]int{120, 45, 200}, 100},
+		{"opposite corners score zero", [3]int{0, 0, 0}, [3]int{255, 255, 255}, 0},
+		{"one channel off by 10", [3]int{100, 100, 100}, [3]int{110, 100, 100}, 98},
+		{"half the maximum distance", [3]int{0, 0, 0}, [3]int{128, 128, 128}, 50},
+		{"direction doesn't matter", [3]int{110, 100, 100}, [3]int{100, 100, 100}, 98},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := calculateColorScore(tt.target[0], tt.target[1], tt.target[2], tt.submitted[0], tt.submitted[1], tt.submitted[2])
+			if got != tt.want {
+				t.Errorf("calculateColorScore = %d, want %d", got, tt.want)
+			}
+		})
+	}
+
+	// A client following the published formula must agree with the server everywhere
+	for dr := -255; dr <= 255; dr += 15 {
+		for dg := -255; dg <= 255; dg += 15 {
+			for db := -255; db <= 255; db += 15 {
+				tr, tg, tb := max(0, -dr), max(0, -dg), max(0, -db)
+				server := calculateColorScore(tr, tg, tb, tr+dr, tg+dg, tb+db)
+				if client := estimateScore(colorScoring, dr, dg, db); client != server {
+					t.Fatalf("delta (%d, %d, %d): server scored %d, published formula gives %d", dr, dg, db, server, client)
+				}
+			}
+		}
+	}
+}
+
+func TestGetScoringParametersServesTheLiveValues(t *testing.T) {
+	app := &Application{}
+	rec := httptest.NewRecorder()
+	app.getScoringParameters(rec, httptest.NewRequest(http.MethodGet, "/v1/game/scoring", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	var got models.ScoringParameters
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if got != colorScoring {
+		t.Errorf("served %+v, want %+v", got, colorScoring)
+	}
+}
+
+func TestAttemptBreakdown(t *testing.T) {
+	tests := []struct {
+		name              string
+		submitted, target [3]int
+		wantDeltas        [3]int
+		wantWorst         string
+	}{
+		{"exact match has no worst channel", [3]int{10, 20, 30}, [3]int{10, 20, 30}, [3]int{0, 0, 0}, ""},
+		{"over on red", [3]int{60, 20, 30}, [3]int{10, 20, 30}, [3]int{50, 0, 0}, "r"},
+		{"under counts by size, not sign", [3]int{10, 20, 30}, [3]int{15, 90, 40}, [3]int{-5, -70, -10}, "g"},
+		{"blue furthest off", [3]int{0, 0, 255}, [3]int{10, 10, 0}, [3]int{-10, -10, 255}, "b"},
+		{"a tie goes to the first channel", [3]int{40, 0, 40}, [3]int{0, 0, 0}, [3]int{40, 0, 40}, "r"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			score := models.DailyScore{
+				ID:              7,
+				Date:            time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC),
+				AttemptNumber:   2,
+				Score:           81,
+				SubmittedColorR: tt.submitted[0],
+				SubmittedColorG: tt.submitted[1],
+				SubmittedColorB: tt.submitted[2],
+				TargetColorR:    tt.target[0],
+				TargetColorG:    tt.target[1],
+				TargetColorB:    tt.target[2],
+			}
+
+			got := attemptBreakdown(score)
+
+			if got.AttemptID != 7 || got.Date != "2026-10-15" || got.AttemptNumber != 2 || got.Score != 81 {
+				t.Errorf("attempt fields = %+v, want them copied from the score", got)
+			}
+			if got.WorstChannel != tt.wantWorst {
+				t.Errorf("worst channel = %q, want %q", got.WorstChannel, tt.wantWorst)
+			}
+			if len(got.Channels) != 3 {
+				t.Fatalf("got %d channels, want 3", len(got.Channels))
+			}
+			for i, name := range []string{"r", "g", "b"} {
+				c := got.Channels[i]
+				wantAbs := tt.wantDeltas[i]
+				if wantAbs < 0 {
+					wantAbs = -wantAbs
+				}
+				if c.Channel != name || c.Submitted != tt.submitted[i] || c.Target != tt.target[i] || c.Delta != tt.wantDeltas[i] || c.AbsDelta != wantAbs {
+					t.Errorf("channel %d = %+v, want %s with delta %d", i, c, name, tt.wantDeltas[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseLimitParam(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		max     int
+		want    int
+		wantErr bool
+	}{
+		{"missing uses the default", "", 100, 50, false},
+		{"empty value uses the default", "?limit=", 100, 50, false},
+		{"within range", "?limit=20", 100, 20, false},
+		{"exactly the max", "?limit=100", 100, 100, false},
+		{"above the max is capped", "?limit=101", 100, 100, false},
+		{"no max configured", "?limit=5000", 0, 5000, false},
+		{"zero is raised to 1", "?limit=0", 100, 1, false},
+		{"negative is raised to 1", "?limit=-7", 100, 1, false},
+		{"non-numeric", "?limit=ten", 100, 0, true},
+		{"decimal", "?limit=2.5", 100, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/v1/leaderboard"+tt.query, nil)
+			got, err := parseLimitParam(r, 50, tt.max)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseLimitParam(%q) error = %v, wantErr %v", tt.query, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseLimitParam(%q) = %d, want %d", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHome(t *testing.T) {
+	app := &Application{}
+	rec := httptest.NewRecorder()
+	app.home(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if got := rec.Header().Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", got)
+	}
+
+	var got struct {
+		Service string            `json:"service"`
+		Version string            `json:"version"`
+		Links   map[string]string `json:"links"`
+	}
+	decoder := json.NewDecoder(rec.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&got); err != nil {
+		t.Fatalf("unexpected response shape: %v", err)
+	}
+
+	if got.Service != "Color Game API" || got.Version != apiVersion {
+		t.Errorf("service, version = %q, %q, want %q, %q", got.Service, got.Version, "Color Game API", apiVersion)
+	}
+	wantLinks := map[string]string{
+		"dailyColor":  "/v1/colors/daily",
+		"leaderboard": "/v1/leaderboard",
+		"shopItems":   "/v1/shop/items",
+	}
+	if len(got.Links) != len(wantLinks) {
+		t.Errorf("links = %v, want %v", got.Links, wantLinks)
+	}
+	for name, path := range wantLinks {
+		if got.Links[name] != path {
+			t.Errorf("links[%q] = %q, want %q", name, got.Links[name], path)
+		}
+	}
+}
+
+func TestRootIsNotCaughtByCatchAll(t *testing.T) {
+	app := Application{}
+	routes := app.BuildRoutes(http.NewServeMux())
+
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+		wantHome   bool
+	}{
+		{"root serves home", "/", http.StatusOK, true},
+		{"root with a query serves home", "/?ref=docs", http.StatusOK, true},
+		{"unknown path is not found", "/nope", http.StatusNotFound, false},
+		{"unknown versioned path is not found", "/v1/nope", http.StatusNotFound, false},
+		{"path under root is not home", "/index.html", http.StatusNotFound, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			routes.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("GET %s status = %d, want %d", tt.path, rec.Code, tt.wantStatus)
+			}
+			var body map[string]interface{}
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatalf("GET %s body isn't JSON: %v", tt.path, err)
+			}
+			if _, isHome := body["service"]; isHome != tt.wantHome {
+				t.Errorf("GET %s served home = %v, want %v: %v", tt.path, isHome, tt.wantHome, body)
+			}
+			if !tt.wantHome && body["errorName"] != "Route Not Found" {
+				t.Errorf("GET %s errorName = %v, want Route Not Found", tt.path, body["errorName"])
+			}
+		})
+	}
+}
+
+// The fakes below keep one player's game in memory. Unimplemented methods panic through the nil
+// embedded interfaces, so a handler reaching for something unexpected fails loudly.
+
+var errNoRows = datastore.NoRowsError{NoRows: true, Err: sql.ErrNoRows}
+
+func dateKey(date time.Time) string {
+	return date.Format("2006-01-02")
+}
+
+type fakeUserRepo struct {
+	datastore.UserRepository
+	user models.User
+}
+
+func (f *fakeUserRepo) Get(userID string) (models.User, error) {
+	return f.user, nil
+}
+
+func (f *fakeUserRepo) GetDeviceByFingerprint(userID string, fingerprint string) (models.UserDevice, error) {
+	return models.UserDevice{UserID: userID, Fingerprint: fingerprint, Expiry: time.Now().Add(time.Hour)}, nil
+}
+
+func (f *fakeUserRepo) Update(user models.User) (models.User, error) {
+	f.user = user
+	return user, nil
+}
+
+type fakeDailyColorRepo struct {
+	datastore.DailyColorRepository
+	colors map[string]models.DailyColor
+}
+
+func (f *fakeDailyColorRepo) GetByDate(date time.Time) (models.DailyColor, error) {
+	color, ok := f.colors[dateKey(date)]
+	if !ok {
+		return models.DailyColor{}, errNoRows
+	}
+	return color, nil
+}
+
+type fakeDailyScoreRepo struct {
+	datastore.DailyScoreRepository
+	scores []models.DailyScore
+}
+
+func (f *fakeDailyScoreRepo) GetDailyAttemptModifier(userID string, date time.Time) (models.DailyAttemptModifier, error) {
+	return models.DailyAttemptModifier{}, errNoRows
+}
+
+func (f *fakeDailyScoreRepo) GetUserAttemptCount(userID string, date time.Time) (int, error) {
+	count := 0
+	for _, score := range f.scores {
+		if score.UserID == userID && dateKey(score.Date) == dateKey(date) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (f *fakeDailyScoreRepo) CreateNextAttempt(score models.DailyScore, maxAttempts int) (models.DailyScore, error) {
+	used, _ := f.GetUserAttemptCount(score.UserID, score.Date)
+	if used >= maxAttempts {
+		return models.DailyScore{}, datastore.ErrMaxAttemptsReached
+	}
+	score.ID = len(f.scores) + 1
+	score.AttemptNumber = used + 1
+	f.scores = append(f.scores, score)
+	return score, nil
+}
+
+func (f *fakeDailyScoreRepo) GetUserBestScoreForColor(userID string, date time.Time, dailyColorID int) (models.DailyScore, error) {
+	var best *models.DailyScore
+	for i, score := range f.scores {
+		if score.UserID != userID || dateKey(score.Date) != dateKey(date) || score.DailyColorID == nil || *score.DailyColorID != dailyColorID {
+			continue
+		}
+		if best == nil || score.Score > best.Score {
+			best = &f.scores[i]
+		}
+	}
+	if best == nil {
+		return models.DailyScore{}, errNoRows
+	}
+	return *best, nil
+}
+
+type fakeLeaderboardRepo struct {
+	datastore.DailyLeaderboardRepository
+	entries map[string]models.DailyLeaderboard
+}
+
+func (f *fakeLeaderboardRepo) GetByUserAndDate(userID string, date time.Time) (models.DailyLeaderboard, error) {
+	entry, ok := f.entries[userID+"/"+dateKey(date)]
+	if !ok {
+		return models.DailyLeaderboard{}, errNoRows
+	}
+	return entry, nil
+}
+
+func (f *fakeLeaderboardRepo) CreateOrUpdate(entry models.DailyLeaderboard) (models.DailyLeaderboard, error) {
+	f.entries[entry.UserID+"/"+dateKey(entry.Date)] = entry
+	return entry, nil
+}
+
+type fakeFriendRepo struct {
+	datastore.FriendRepository
+}
+
+func (f *fakeFriendRepo) RecordFriendActivity(userID string, date time.Time, bestScore, attemptsUsed int) error {
+	return nil
+}
+
+// fakeGame is an Application wired to in-memory repositories for a single signed-in player
+type fakeGame struct {
+	app         *Application
+	user        *fakeUserRepo
+	colors      *fakeDailyColorRepo
+	scores      *fakeDailyScoreRepo
+	leaderboard *fakeLeaderboardRepo
+}
+
+func newFakeGame(t *testing.T) *fakeGame {
+	// Attempt allowances read runtime settings; make sure none are left over from another test
+	cachedSettings.invalidate()
+	t.Cleanup(cachedSettings.invalidate)
+
+	g := &fakeGame{
+		user:        &fakeUserRepo{user: models.User{UserID: "player-1", Approved: true, Level: 1}},
+		colors:      &fakeDailyColorRepo{colors: map[string]models.DailyColor{}},
+		scores:      &fakeDailyScoreRepo{},
+		leaderboard: &fakeLeaderboardRepo{entries: map[string]models.DailyLeaderboard{}},
+	}
+	g.app = &Application{
+		Config:               Config{JwtSecret: strings.Repeat("s", MinJwtSecretLength)},
+		UserRepo:             g.user,
+		DailyColorRepo:       g.colors,
+		DailyScoreRepo:       g.scores,
+		DailyLeaderboardRepo: g.leaderboard,
+		FriendRepo:           &fakeFriendRepo{},
+		RewardEventRepo:      &fakeRewardEventRepo{},
+	}
+	return g
+}
+
+// request builds a request carrying a valid access token for the player
+func (g *fakeGame) request(t *testing.T, method, target, body string) *http.Request {
+	t.Helper()
+	claims := models.JWTClaims{
+		UserID:            g.user.user.UserID,
+		DeviceFingerprint: "test-device",
+		Scope:             "authentication",
+		RegisteredClaims:  jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))},
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(g.app.Config.JwtSecret))
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+
+	r := httptest.NewRequest(method, target, strings.NewReader(body))
+	r.Header.Set("Authorization", "Bearer "+token)
+	return r
+}
+
+// submit sends a guess for today and decodes the response
+func (g *fakeGame) submit(t *testing.T, r, gr, b int) models.ScoreSubmissionResponse {
+	t.Helper()
+	body, _ := json.Marshal(models.ScoreSubmissionRequest{SubmittedColorR: r, SubmittedColorG: gr, SubmittedColorB: b})
+	rec := httptest.NewRecorder()
+	g.app.submitScore(rec, g.request(t, http.MethodPost, "/v1/scores/submit", string(body)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("submitScore status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body)
+	}
+	var response models.ScoreSubmissionResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("invalid submitScore response: %v", err)
+	}
+	return response
+}
+
+func TestSubmitScoreAfterColorRegeneratedMidDay(t *testing.T) {
+	g := newFakeGame(t)
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	leaderboardKey := g.user.user.UserID + "/" + dateKey(today)
+
+	g.colors.colors[dateKey(today)] = models.DailyColor{ID: 1, Date: today, R: 200, G: 40, B: 40}
+	first := g.submit(t, 200, 40, 40)
+	if first.BestScore != 100 || g.leaderboard.entries[leaderboardKey].BestScore != 100 {
+		t.Fatalf("perfect first guess: best = %d, leaderboard = %+v", first.BestScore, g.leaderboard.entries[leaderboardKey])
+	}
+
+	// An admin regenerates the color; the perfect guess was against the old one
+	g.colors.colors[dateKey(today)] = models.DailyColor{ID: 2, Date: today, R: 20, G: 20, B: 220}
+
+	second := g.submit(t, 60, 60, 220)
+	if !second.IsNewBest || second.BestScore != second.Score || second.Score >= 100 {
+		t.Errorf("first guess on the new color: score %d, best %d, new best %v; want it to be the best", second.Score, second.BestScore, second.IsNewBest)
+	}
+	entry := g.leaderboard.entries[leaderboardKey]
+	if entry.BestScore != second.Score || entry.AttemptsUsed != second.AttemptNumber {
+		t.Errorf("leaderboard = %d on attempt %d, want %d on attempt %d", entry.BestScore, entry.AttemptsUsed, second.Score, second.AttemptNumber)
+	}
+
+	third := g.submit(t, 255, 255, 0)
+	if third.IsNewBest || third.BestScore != second.Score {
+		t.Errorf("worse guess: best %d, new best %v; want best to stay %d", third.BestScore, third.IsNewBest, second.Score)
+	}
+
+	// A leaderboard entry left over from the old color is recomputed even when the guess isn't a new best
+	g.leaderboard.entries[leaderboardKey] = models.DailyLeaderboard{UserID: g.user.user.UserID, Date: today, BestScore: 100, AttemptsUsed: 1}
+	fourth := g.submit(t, 255, 255, 0)
+	if fourth.IsNewBest || fourth.BestScore != second.Score {
+		t.Errorf("guess with a stale entry: best %d, new best %v; want %d from the new color", fourth.BestScore, fourth.IsNewBest, second.Score)
+	}
+	entry = g.leaderboard.entries[leaderboardKey]
+	if entry.BestScore != second.Score || entry.AttemptsUsed != second.AttemptNumber {
+		t.Errorf("stale leaderboard = %d on attempt %d, want it recomputed to %d on attempt %d", entry.BestScore, entry.AttemptsUsed, second.Score, second.AttemptNumber)
+	}
+}
+
+func TestAnswerHidden(t *testing.T) {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	yesterday := today.AddDate(0, 0, -1)
+	justAfterMidnight := today.Add(10 * time.Minute)
+
+	tests := []struct {
+		name         string
+		date         time.Time
+		now          time.Time
+		graceMinutes int
+		attemptsUsed int
+		want         bool
+	}{
+		{"today with attempts left", today, now, 0, 2, true},
+		{"today out of attempts", today, now, 0, baseDailyAttempts, false},
+		{"yesterday with no grace window", yesterday, justAfterMidnight, 0, 2, false},
+		{"yesterday inside the grace window", yesterday, justAfterMidnight, 30, 2, true},
+		{"yesterday inside the grace window, out of attempts", yesterday, justAfterMidnight, 30, baseDailyAttempts, false},
+		{"yesterday after the grace window", yesterday, today.Add(45 * time.Minute), 30, 2, false},
+		{"two days ago", today.AddDate(0, 0, -2), justAfterMidnight, 30, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := newFakeGame(t)
+			g.app.Config.ScoreGraceMinutes = tt.graceMinutes
+			for i := 0; i < tt.attemptsUsed; i++ {
+				g.scores.scores = append(g.scores.scores, models.DailyScore{UserID: g.user.user.UserID, Date: tt.date, AttemptNumber: i + 1})
+			}
+
+			got, err := g.app.answerHidden(g.user.user.UserID, tt.date, tt.now)
+			if err != nil {
+				t.Fatalf("answerHidden error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("answerHidden = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetDailyColorAnswer(t *testing.T) {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	lastWeek := today.AddDate(0, 0, -7)
+
+	tests := []struct {
+		name         string
+		query        string
+		attemptsUsed int
+		wantStatus   int
+	}{
+		{"today with attempts left", "", 1, http.StatusForbidden},
+		{"today out of attempts", "", baseDailyAttempts, http.StatusOK},
+		{"past day", "?date=" + dateKey(lastWeek), 0, http.StatusOK},
+		{"future day", "?date=" + dateKey(today.AddDate(0, 0, 1)), 0, http.StatusBadRequest},
+		{"past day without a color", "?date=" + dateKey(today.AddDate(0, 0, -30)), 0, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := newFakeGame(t)
+			g.colors.colors[dateKey(today)] = models.DailyColor{ID: 1, Date: today, ColorName: "Rust", R: 183, G: 65, B: 14}
+			g.colors.colors[dateKey(lastWeek)] = models.DailyColor{ID: 2, Date: lastWeek, ColorName: "Teal", R: 0, G: 128, B: 128}
+			for i := 0; i < tt.attemptsUsed; i++ {
+				g.scores.scores = append(g.scores.scores, models.DailyScore{UserID: g.user.user.UserID, Date: today, AttemptNumber: i + 1})
+			}
+
+			rec := httptest.NewRecorder()
+			g.app.getDailyColorAnswer(rec, g.request(t, http.MethodGet, "/v1/colors/daily/answer"+tt.query, ""))
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if rec.Code != http.StatusOK {
+				return
+			}
+
+			var got models.DailyColorResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatalf("invalid response: %v", err)
+			}
+			if got.Hex == "" || got.RGB == "" || got.ColorName == "" {
+				t.Errorf("answer = %+v, want the exact color", got)
+			}
+		})
+	}
+}
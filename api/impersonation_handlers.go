@@ -0,0 +1,133 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/color-game/api/datastore"
+	"github.com/color-game/api/models"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// impersonationTokenDuration is how long an impersonation token (and its device) stays valid
+const impersonationTokenDuration = 15 * time.Minute
+
+// POST /v1/admin/impersonate - Mint a short-lived, read-only access token for a user (Admin only, audited)
+func (app *Application) impersonateUser(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		app.requirePostMethod(w, r, ErrPOST)
+		return
+	}
+
+	admin, err := app.getUserFromToken(w, r)
+	if err != nil {
+		return
+	}
+
+	var req models.ImpersonationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		app.badJSONRequest(w, r, err)
+		return
+	}
+
+	var v validator
+	v.check(strings.TrimSpace(req.UserID) != "", "userId", "userId is required")
+	v.check(strings.TrimSpace(req.Reason) != "", "reason", "a reason is required for the audit log")
+	if !v.valid() {
+		app.validationFailed(w, r, &v)
+		return
+	}
+
+	target, err := app.UserRepo.Get(req.UserID)
+	if err != nil {
+		if _, ok := err.(datastore.NoRowsError); ok {
+			http.Error(w, "User not found", http.StatusNotFound)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if target.UserID == admin.UserID {
+		app.badRequest(w, r, errors.New("cannot impersonate yourself"))
+		return
+	}
+	// Impersonating another admin would hand out admin access under someone else's name
+	if target.Kind == models.Admin {
+		app.forbidden(w, r, errors.New("cannot impersonate an admin"))
+		return
+	}
+
+	// The token gets its own device so it can be listed and revoked like any other session
+	now := time.Now()
+	expiry := now.Add(impersonationTokenDuration)
+	tokenID := models.NewTokenID()
+	fingerprint := "impersonation-" + tokenID
+
+	if err := app.UserRepo.CreateDevice(models.UserDevice{
+		UserID:      target.UserID,
+		Fingerprint: fingerprint,
+		DeviceData:  "impersonation by " + admin.UserID,
+		Expiry:      expiry,
+	}); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	details, err := json.Marshal(map[string]interface{}{
+		"reason":    strings.TrimSpace(req.Reason),
+		"tokenId":   tokenID,
+		"expiresAt": expiry,
+	})
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	// No audit entry, no token
+	entry, err := app.AuditLogRepo.RecordAuditEntry(models.AuditEntry{
+		AdminID:      admin.UserID,
+		Action:       models.AuditActionImpersonate,
+		TargetUserID: &target.UserID,
+		Details:      details,
+	})
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	claims := models.JWTClaims{
+		UserID:            target.UserID,
+		Email:             target.Email,
+		Kind:              target.Kind,
+		DeviceFingerprint: fingerprint,
+		Scope:             "authentication",
+		TokenType:         models.JWT.ACCESS_COOKIE_NAME,
+		ImpersonatedBy:    admin.UserID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    app.Config.JwtIssuer,
+			Audience:  app.jwtAudience(),
+			ID:        tokenID,
+			ExpiresAt: jwt.NewNumericDate(expiry),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+	}
+
+	tokenString, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(app.Config.JwtSecret))
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	// Returned in the body rather than as cookies so it never replaces the admin's own session
+	app.writeJSON(w, http.StatusOK, models.ImpersonationResponse{
+		AccessToken:    tokenString,
+		ExpiresAt:      expiry,
+		UserID:         target.UserID,
+		ImpersonatedBy: admin.UserID,
+		AuditID:        entry.ID,
+	})
+}
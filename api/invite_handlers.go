@@ -0,0 +1,84 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/color-game/api/models"
+)
+
+// maxInviteCodesPerRequest bounds how many codes one request can mint
+const maxInviteCodesPerRequest = 100
+
+// POST /v1/admin/invites - Mint invite codes (Admin only)
+func (app *Application) createInviteCodes(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		app.requirePostMethod(w, r, ErrPOST)
+		return
+	}
+
+	admin, err := app.getUserFromToken(w, r)
+	if err != nil {
+		return
+	}
+
+	var req models.CreateInviteCodesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		app.badJSONRequest(w, r, err)
+		return
+	}
+
+	if req.Count == 0 {
+		req.Count = 1
+	}
+	if req.Count < 0 || req.Count > maxInviteCodesPerRequest {
+		app.badRequest(w, r, fmt.Errorf("count must be between 1 and %d", maxInviteCodesPerRequest))
+		return
+	}
+	if req.ExpiresInDays < 0 {
+		app.badRequest(w, r, errors.New("expiresInDays cannot be negative"))
+		return
+	}
+
+	var expiresAt *time.Time
+	if req.ExpiresInDays > 0 {
+		expiry := time.Now().AddDate(0, 0, req.ExpiresInDays)
+		expiresAt = &expiry
+	}
+
+	invites := make([]models.InviteCode, 0, req.Count)
+	for i := 0; i < req.Count; i++ {
+		invite, err := app.InviteCodeRepo.CreateInviteCode(admin.UserID, expiresAt)
+		if err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+		invites = append(invites, invite)
+	}
+
+	log.Printf("Admin %s minted %d invite codes", admin.UserID, len(invites))
+
+	app.writeJSON(w, http.StatusCreated, map[string]interface{}{
+		"invites": invites,
+	})
+}
+
+// GET /v1/admin/invites/all - List all invite codes (Admin only)
+func (app *Application) getInviteCodes(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	invites, err := app.InviteCodeRepo.ListInviteCodes()
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.writeList(w, "invites", invites, len(invites))
+}
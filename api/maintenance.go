@@ -0,0 +1,96 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/color-game/api/datastore"
+	"github.com/color-game/api/models"
+)
+
+// loadMaintenanceStatus reads the maintenance flag, falling back to Config.MaintenanceMode when it has
+// never been set at runtime
+func (app *Application) loadMaintenanceStatus() (models.MaintenanceStatus, error) {
+	fallback := models.MaintenanceStatus{Enabled: app.Config.MaintenanceMode}
+
+	setting, err := app.SettingsRepo.GetSetting(models.SettingMaintenanceMode)
+	if err != nil {
+		if _, ok := err.(datastore.NoRowsError); ok {
+			return fallback, nil
+		}
+		return fallback, err
+	}
+
+	enabled, err := strconv.ParseBool(setting.Value)
+	if err != nil {
+		return fallback, err
+	}
+	return models.MaintenanceStatus{
+		Enabled:   enabled,
+		UpdatedBy: setting.UpdatedBy,
+		UpdatedAt: &setting.UpdatedAt,
+	}, nil
+}
+
+// gameplay answers 503 while maintenance mode is on. Health checks, auth, profile reads and admin
+// endpoints are left ungated so operators can see and fix things while the game is paused.
+func (app *Application) gameplay(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if app.settingBool(models.SettingMaintenanceMode) {
+			app.underMaintenance(w, r)
+			return
+		}
+		h.ServeHTTP(w, r)
+	}
+}
+
+// GET /v1/admin/maintenance - Whether gameplay is paused (Admin only)
+// PUT /v1/admin/maintenance - Pause or resume gameplay on every instance with {"enabled": true} (Admin only, audited)
+func (app *Application) adminMaintenanceMode(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodGet {
+		status, err := app.loadMaintenanceStatus()
+		if err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+		app.writeJSON(w, http.StatusOK, status)
+		return
+	}
+
+	if r.Method != http.MethodPut {
+		app.requirePutMethod(w, r, ErrPUT)
+		return
+	}
+
+	admin, err := app.getUserFromToken(w, r)
+	if err != nil {
+		return
+	}
+
+	var req struct {
+		Enabled *bool `json:"enabled"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		app.badJSONRequest(w, r, err)
+		return
+	}
+	if req.Enabled == nil {
+		app.badRequest(w, r, errors.New("enabled is required"))
+		return
+	}
+
+	def, _ := findSettingDefinition(models.SettingMaintenanceMode)
+	setting, err := app.updateSetting(admin.UserID, def, strconv.FormatBool(*req.Enabled))
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.writeJSON(w, http.StatusOK, models.MaintenanceStatus{
+		Enabled:   *req.Enabled,
+		UpdatedBy: setting.UpdatedBy,
+		UpdatedAt: &setting.UpdatedAt,
+	})
+}
@@ -1,23 +1,55 @@
 package api
 
 import (
+	"encoding/json"
 	"errors"
-	"fmt"
+	"log"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/color-game/api/models"
 	"github.com/golang-jwt/jwt/v5"
 )
 
-func handleCors(h http.HandlerFunc) http.HandlerFunc {
+// DefaultAllowedMethods and DefaultAllowedHeaders are the CORS allow lists used when
+// ALLOWED_METHODS and ALLOWED_HEADERS are unset
+const (
+	DefaultAllowedMethods = "POST,GET,OPTIONS,PUT,DELETE"
+	DefaultAllowedHeaders = "Access-Control-Allow-Credentials,Access-Control-Allow-Origin,Accept,Content-Type,Content-Length,Accept-Encoding,X-CSRF-Token,Authorization"
+)
+
+// corsHeaderValue joins a configured allow list into a CORS header value
+func corsHeaderValue(values []string) string {
+	trimmed := make([]string, 0, len(values))
+	for _, value := range values {
+		trimmed = append(trimmed, strings.TrimSpace(value))
+	}
+	return strings.Join(trimmed, ", ")
+}
+
+// isHTTPToken reports whether s is a valid HTTP token (RFC 9110), as method and header names must be
+func isHTTPToken(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c > 0x7e || !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || strings.ContainsRune("!#$%&'*+-.^_`|~", c)) {
+			return false
+		}
+	}
+	return true
+}
+
+func handleCors(h http.HandlerFunc, allowMethods, allowHeaders string) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		origin := r.Header.Get("Origin")
 
 		w.Header().Set("Access-Control-Allow-Origin", origin)
-		w.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, DELETE")
+		w.Header().Set("Access-Control-Allow-Methods", allowMethods)
 		w.Header().Set("Access-Control-Allow-Credentials", "true")
-		w.Header().Set("Access-Control-Allow-Headers", "Access-Control-Allow-Credentials, Access-Control-Allow-Origin, Accept, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization")
+		w.Header().Set("Access-Control-Allow-Headers", allowHeaders)
+		w.Header().Set("Access-Control-Expose-Headers", "X-Leaderboard-Limit, X-Leaderboard-Final, X-Total-Count, X-Next-Cursor")
 		if r.Method == "OPTIONS" {
 			return
 		} else {
@@ -26,31 +58,144 @@ func handleCors(h http.HandlerFunc) http.HandlerFunc {
 	}
 }
 
-// getUserFromJWT attempts to get user from JWT access token cookie
-func (app *Application) getUserFromJWT(r *http.Request) (models.User, error) {
-	// Get JWT access token from cookie
-	cookie, err := r.Cookie(models.JWT.ACCESS_COOKIE_NAME)
-	if err != nil {
-		return models.User{}, errors.New("no JWT cookie found")
+// limitConcurrency sheds load with a 503 once Config.MaxConcurrentRequests requests are already
+// in flight, rather than letting them queue on the database pool. The root health check is exempt.
+// A limit of 0 disables it.
+func (app *Application) limitConcurrency(next http.Handler) http.Handler {
+	if app.Config.MaxConcurrentRequests <= 0 {
+		return next
 	}
 
-	// Parse and validate JWT token
-	token, err := jwt.ParseWithClaims(cookie.Value, &models.JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	slots := make(chan struct{}, app.Config.MaxConcurrentRequests)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		select {
+		case slots <- struct{}{}:
+			defer func() { <-slots }()
+			next.ServeHTTP(w, r)
+		default:
+			app.serverBusy(w, r)
 		}
-		return []byte(app.Config.JwtSecret), nil
 	})
+}
+
+// requestTimeoutExempt lists long-running admin jobs that must not be reported as failed while
+// they are still working. Streaming endpoints belong here too, since the timeout buffers responses.
+var requestTimeoutExempt = map[string]bool{
+	"/v1/admin/colors/backfill":          true,
+	"/v1/admin/users/recalculate-levels": true,
+}
+
+// withRequestTimeout puts a deadline on each request's context and answers 503 once it passes.
+// Work that takes the request context is cancelled at the deadline; anything else runs to
+// completion in the background, but its response is discarded. It must wrap limitConcurrency, not
+// the other way round, so a request keeps its concurrency slot until its handler really returns.
+func (app *Application) withRequestTimeout(next http.Handler) http.Handler {
+	if app.Config.RequestTimeout <= 0 {
+		return next
+	}
+
+	body, _ := json.Marshal(HandlerError{
+		ErrorName:        "Request Timeout",
+		Description:      "The request took too long to process",
+		PossibleSolution: "Retry the request later",
+	})
+	timed := http.TimeoutHandler(next, time.Duration(app.Config.RequestTimeout)*time.Second, string(body))
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requestTimeoutExempt[r.URL.Path] {
+			next.ServeHTTP(w, r)
+			return
+		}
+		timed.ServeHTTP(timeoutResponseWriter{w}, r)
+	})
+}
+
+// timeoutResponseWriter labels http.TimeoutHandler's 503 body as the JSON it is. Handler responses
+// come with their own Content-Type, which TimeoutHandler copies over before writing the status.
+type timeoutResponseWriter struct {
+	http.ResponseWriter
+}
 
-	if err != nil || !token.Valid {
+func (w timeoutResponseWriter) WriteHeader(status int) {
+	if status == http.StatusServiceUnavailable && w.Header().Get("Content-Type") == "" {
+		w.Header().Set("Content-Type", "application/json")
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// jwtAudience returns the configured audience claim, or nil when none is configured
+func (app *Application) jwtAudience() jwt.ClaimStrings {
+	if app.Config.JwtAudience == "" {
+		return nil
+	}
+	return jwt.ClaimStrings{app.Config.JwtAudience}
+}
+
+// accessTokenFromRequest reads the access token from its cookie, falling back to an
+// "Authorization: Bearer <token>" header for clients that can't use cookies
+func accessTokenFromRequest(r *http.Request) (string, error) {
+	if cookie, err := r.Cookie(models.JWT.ACCESS_COOKIE_NAME); err == nil && cookie.Value != "" {
+		return cookie.Value, nil
+	}
+
+	scheme, token, found := strings.Cut(r.Header.Get("Authorization"), " ")
+	if found && strings.EqualFold(scheme, "Bearer") && strings.TrimSpace(token) != "" {
+		return strings.TrimSpace(token), nil
+	}
+
+	return "", errors.New("no JWT cookie or bearer token found")
+}
+
+// impersonationAllowsMethod reports whether an impersonation token may be used for a request method
+func impersonationAllowsMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	return false
+}
+
+// getUserFromJWT attempts to get user from the JWT access token cookie or Authorization header
+func (app *Application) getUserFromJWT(r *http.Request) (models.User, error) {
+	tokenString, err := accessTokenFromRequest(r)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	// Parse and validate JWT token, including issuer and audience
+	claims, err := models.ValidateJWTToken(tokenString, app.Config.JwtSecret, app.Config.JwtIssuer, app.Config.JwtAudience)
+	if err != nil {
 		return models.User{}, errors.New("invalid JWT token")
 	}
 
-	claims, ok := token.Claims.(*models.JWTClaims)
-	if !ok || claims.Scope != "authentication" {
+	if claims.Scope != "authentication" {
 		return models.User{}, errors.New("invalid token claims")
 	}
 
+	// Impersonation tokens may look but not touch, and every use is logged
+	if claims.ImpersonatedBy != "" {
+		log.Printf("impersonation: admin %s as user %s: %s %s", claims.ImpersonatedBy, claims.UserID, r.Method, r.URL.Path)
+		if !impersonationAllowsMethod(r.Method) {
+			return models.User{}, errors.New("impersonation tokens are read-only")
+		}
+	}
+
+	// Tokens issued before jti was added have no ID and can only be revoked via their device
+	if claims.ID != "" {
+		revoked, err := app.UserRepo.IsTokenRevoked(claims.ID)
+		if err != nil {
+			return models.User{}, err
+		}
+		if revoked {
+			return models.User{}, errors.New("token revoked")
+		}
+	}
+
 	// Verify device still exists and is valid
 	device, err := app.UserRepo.GetDeviceByFingerprint(claims.UserID, claims.DeviceFingerprint)
 	if err != nil {
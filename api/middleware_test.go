@@ -0,0 +1,54 @@
+package api
+
+import "testing"
+
+func TestIsHTTPToken(t *testing.T) {
+	tests := []struct {
+		value string
+		want  bool
+	}{
+		{"GET", true},
+		{"PATCH", true},
+		{"Content-Type", true},
+		{"X-CSRF-Token", true},
+		{"x_custom.header~1", true},
+		{"!#$%&'*+-.^_`|~", true},
+		{"", false},
+		{"Content Type", false},
+		{" GET", false},
+		{"X-Token:", false},
+		{"Header,Other", false},
+		{"(comment)", false},
+		{`"quoted"`, false},
+		{"Tab\tHeader", false},
+		{"Größe", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			if got := isHTTPToken(tt.value); got != tt.want {
+				t.Errorf("isHTTPToken(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCorsHeaderValue(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []string
+		want   string
+	}{
+		{"empty list", nil, ""},
+		{"single value", []string{"GET"}, "GET"},
+		{"values are trimmed and joined", []string{" GET", "POST ", " OPTIONS "}, "GET, POST, OPTIONS"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := corsHeaderValue(tt.values); got != tt.want {
+				t.Errorf("corsHeaderValue(%q) = %q, want %q", tt.values, got, tt.want)
+			}
+		})
+	}
+}
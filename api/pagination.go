@@ -0,0 +1,78 @@
+package api
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/color-game/api/models"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 200
+)
+
+// encodeCursor turns a cursor into the opaque string handed to clients
+func encodeCursor(cursor models.PageCursor) string {
+	raw, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// decodeCursor reverses encodeCursor
+func decodeCursor(value string) (models.PageCursor, error) {
+	var cursor models.PageCursor
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil || json.Unmarshal(raw, &cursor) != nil || cursor.At.IsZero() || cursor.ID == "" {
+		return models.PageCursor{}, errors.New("cursor is invalid")
+	}
+	return cursor, nil
+}
+
+// parsePageParams reads the limit and cursor query parameters. paged is false when neither is
+// given, for endpoints that still return everything to clients that don't page.
+func parsePageParams(r *http.Request) (limit int, after *models.PageCursor, paged bool, err error) {
+	query := r.URL.Query()
+	paged = query.Has("limit") || query.Has("cursor")
+
+	limit, err = parseLimitParam(r, defaultPageSize, maxPageSize)
+	if err != nil {
+		return 0, nil, false, err
+	}
+
+	if value := query.Get("cursor"); value != "" {
+		cursor, err := decodeCursor(value)
+		if err != nil {
+			return 0, nil, false, err
+		}
+		after = &cursor
+	}
+	return limit, after, paged, nil
+}
+
+// writePage writes one page of a list. Repositories are asked for limit+1 rows, so an extra row
+// means there is a next page; it is trimmed off and its predecessor becomes the cursor. The next
+// cursor is sent in the X-Next-Cursor header, and in the body's meta when RESPONSE_ENVELOPE is on.
+// total is the size of the whole list across every page.
+func writePage[T any](app *Application, w http.ResponseWriter, items []T, limit, total int, cursorOf func(T) models.PageCursor) {
+	meta := models.PageMeta{Limit: limit}
+	if len(items) > limit {
+		items = items[:limit]
+		meta.NextCursor = encodeCursor(cursorOf(items[len(items)-1]))
+		w.Header().Set("X-Next-Cursor", meta.NextCursor)
+	}
+	if items == nil {
+		items = []T{}
+	}
+
+	if app.Config.ResponseEnvelope {
+		app.writeJSON(w, http.StatusOK, map[string]interface{}{
+			"data":  items,
+			"total": total,
+			"meta":  meta,
+		})
+		return
+	}
+	app.writeJSON(w, http.StatusOK, items)
+}
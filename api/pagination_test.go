@@ -0,0 +1,123 @@
+package api
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/color-game/api/models"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	tests := []models.PageCursor{
+		{At: time.Date(2026, 10, 15, 9, 30, 0, 0, time.UTC), ID: "42"},
+		{At: time.Date(2026, 1, 2, 3, 4, 5, 123456789, time.UTC), ID: "8f14e45f-ceea-467f-a0e6-7a9c2c1e4b11"},
+		{At: time.Date(2026, 6, 1, 12, 0, 0, 0, time.FixedZone("UTC+2", 2*60*60)), ID: "id with spaces/and+symbols"},
+	}
+
+	for _, want := range tests {
+		t.Run(want.ID, func(t *testing.T) {
+			encoded := encodeCursor(want)
+			got, err := decodeCursor(encoded)
+			if err != nil {
+				t.Fatalf("decodeCursor(encodeCursor(%+v)) error = %v", want, err)
+			}
+			if !got.At.Equal(want.At) || got.ID != want.ID {
+				t.Errorf("round trip = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestDecodeCursorRejectsInvalidValues(t *testing.T) {
+	encode := func(v any) string {
+		raw, _ := json.Marshal(v)
+		return base64.RawURLEncoding.EncodeToString(raw)
+	}
+
+	tests := []struct {
+		name  string
+		value string
+	}{
+		{"not base64", "not a cursor!"},
+		{"not JSON", base64.RawURLEncoding.EncodeToString([]byte("hello"))},
+		{"missing time", encode(map[string]string{"id": "42"})},
+		{"missing ID", encode(map[string]any{"at": time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)})},
+		{"empty object", encode(map[string]any{})},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if cursor, err := decodeCursor(tt.value); err == nil {
+				t.Errorf("decodeCursor(%q) = %+v, want an error", tt.value, cursor)
+			}
+		})
+	}
+}
+
+func TestWritePage(t *testing.T) {
+	base := time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)
+	items := func(n int) []int {
+		out := make([]int, n)
+		for i := range out {
+			out[i] = i + 1
+		}
+		return out
+	}
+	cursorOf := func(i int) models.PageCursor {
+		return models.PageCursor{At: base.Add(-time.Duration(i) * time.Hour), ID: strconv.Itoa(i)}
+	}
+
+	tests := []struct {
+		name       string
+		items      []int
+		limit      int
+		wantLen    int
+		wantCursor bool
+	}{
+		{"a short page is the last one", items(2), 3, 2, false},
+		{"a full page without an extra row is the last one", items(3), 3, 3, false},
+		{"an extra row means there is a next page", items(4), 3, 3, true},
+		{"an empty page", nil, 3, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := &Application{Config: Config{ResponseEnvelope: true}}
+			rec := httptest.NewRecorder()
+			writePage(app, rec, tt.items, tt.limit, 10, cursorOf)
+
+			var body struct {
+				Data  []int           `json:"data"`
+				Total int             `json:"total"`
+				Meta  models.PageMeta `json:"meta"`
+			}
+			if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+				t.Fatalf("decoding response: %v", err)
+			}
+			if body.Data == nil || len(body.Data) != tt.wantLen {
+				t.Errorf("data = %v, want %d items", body.Data, tt.wantLen)
+			}
+			if body.Total != 10 {
+				t.Errorf("total = %d, want the full list size 10", body.Total)
+			}
+
+			header := rec.Header().Get("X-Next-Cursor")
+			if (header != "") != tt.wantCursor || body.Meta.NextCursor != header {
+				t.Fatalf("next cursor header %q, meta %q, want a cursor: %v", header, body.Meta.NextCursor, tt.wantCursor)
+			}
+			if tt.wantCursor {
+				cursor, err := decodeCursor(header)
+				if err != nil {
+					t.Fatalf("next cursor doesn't decode: %v", err)
+				}
+				if want := cursorOf(tt.items[tt.limit-1]); cursor.ID != want.ID || !cursor.At.Equal(want.At) {
+					t.Errorf("next cursor = %+v, want the last returned row %+v", cursor, want)
+				}
+			}
+		})
+	}
+}
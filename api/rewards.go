@@ -0,0 +1,196 @@
+package api
+
+import (
+	"log"
+	"math"
+	"time"
+
+	"github.com/color-game/api/datastore"
+	"github.com/color-game/api/models"
+)
+
+// pointsPerLevel is the default flat number of points between level milestones
+const pointsPerLevel = 1000
+
+// levelCurve lists the points needed to advance out of each level: entry 0 takes a player from
+// level 1 to 2, entry 1 from 2 to 3, and so on. Levels past the end of the list keep costing the
+// last entry. An empty curve is the flat pointsPerLevel default.
+type levelCurve []int
+
+// levelCurve returns the configured level curve
+func (app *Application) levelCurve() levelCurve {
+	return levelCurve(app.Config.LevelCurve)
+}
+
+// pointsForLevelUp returns the points needed to advance from level to level+1
+func (c levelCurve) pointsForLevelUp(level int) int {
+	if len(c) == 0 {
+		return pointsPerLevel
+	}
+	i := level - 1
+	if i >= len(c) {
+		i = len(c) - 1
+	}
+	return c[i]
+}
+
+// levelAndRemainder walks the curve and returns the level reached and the points earned into it
+func (c levelCurve) levelAndRemainder(points int) (int, int) {
+	if points < 0 {
+		points = 0
+	}
+
+	level := 1
+	for i := 0; i < len(c)-1; i++ {
+		if points < c[i] {
+			return level, points
+		}
+		points -= c[i]
+		level++
+	}
+
+	// Past the listed levels every level costs the same, so skip ahead
+	cost := c.pointsForLevelUp(level)
+	return level + points/cost, points % cost
+}
+
+// LevelForPoints returns the level reached with the given total points. Players start at level 1.
+// Reward finalization and level progress both derive levels from here so they can't diverge.
+func (c levelCurve) LevelForPoints(points int) int {
+	level, _ := c.levelAndRemainder(points)
+	return level
+}
+
+// levelProgress reports where the given total points sit within their level
+func (c levelCurve) levelProgress(points int) models.LevelProgress {
+	if points < 0 {
+		points = 0
+	}
+	level, into := c.levelAndRemainder(points)
+	cost := c.pointsForLevelUp(level)
+
+	return models.LevelProgress{
+		Level:             level,
+		Points:            points,
+		PointsIntoLevel:   into,
+		PointsToNextLevel: cost - into,
+		PointsPerLevel:    cost,
+		ProgressPercent:   math.Round(float64(into)/float64(cost)*10000) / 100,
+	}
+}
+
+// rewardRates convert a day's best score into points and credits
+type rewardRates struct {
+	PointsPerScorePoint  float64
+	CreditsPerScorePoint float64
+}
+
+// rewardRates returns the current score conversion rates, which admins may change at runtime
+func (app *Application) rewardRates() rewardRates {
+	return rewardRates{
+		PointsPerScorePoint:  app.settingFloat(models.SettingPointsPerScorePoint),
+		CreditsPerScorePoint: app.settingFloat(models.SettingCreditsPerScorePoint),
+	}
+}
+
+// rewardMultiplier returns the multiplier and name of the reward event covering date, or the
+// default multiplier and no name when no event is running. Overlapping events are resolved by
+// the repository, so the highest multiplier wins.
+func (app *Application) rewardMultiplier(date time.Time) (float64, string, error) {
+	event, err := app.RewardEventRepo.GetActiveEvent(date)
+	if err != nil {
+		if _, ok := err.(datastore.NoRowsError); ok {
+			return models.DefaultRewardMultiplier, "", nil
+		}
+		return 0, "", err
+	}
+	return event.Multiplier, event.Name, nil
+}
+
+// dailyRewards is what a player earns when their attempts for the day are used up
+type dailyRewards struct {
+	PointsAwarded  int
+	CreditsAwarded int
+	LevelUps       int
+}
+
+// calculateDailyRewards converts the day's best score into points, credits and level-ups
+// at the given rates and level curve, scaled by the active reward event multiplier
+func calculateDailyRewards(currentPoints, bestScore int, multiplier float64, rates rewardRates, curve levelCurve) dailyRewards {
+	if multiplier <= 0 {
+		multiplier = 1
+	}
+
+	pointsAward := int(math.Round(float64(bestScore) * rates.PointsPerScorePoint * multiplier))
+	creditAward := int(math.Ceil(float64(bestScore) * rates.CreditsPerScorePoint * multiplier))
+
+	levelUps := curve.LevelForPoints(currentPoints+pointsAward) - curve.LevelForPoints(currentPoints)
+	if levelUps < 0 {
+		levelUps = 0
+	}
+
+	return dailyRewards{
+		PointsAwarded:  pointsAward,
+		CreditsAwarded: creditAward,
+		LevelUps:       levelUps,
+	}
+}
+
+// levelRepairBatchSize is how many users the level repair reads per query
+const levelRepairBatchSize = 500
+
+// levelRepairResult summarises a level recalculation run
+type levelRepairResult struct {
+	UsersChecked int `json:"usersChecked"`
+	UsersFixed   int `json:"usersFixed"`
+
+	// Changes lists every corrected user for the audit log
+	Changes []levelChange `json:"-"`
+}
+
+// levelChange is one user's level correction
+type levelChange struct {
+	UserID   string `json:"userId"`
+	Points   int    `json:"points"`
+	OldLevel int    `json:"oldLevel"`
+	NewLevel int    `json:"newLevel"`
+}
+
+// recalculateLevels recomputes every user's level from their points with the configured curve and
+// corrects any that drifted. Users are read in batches and fixed one row at a time so no long
+// lock is held on the users table.
+func (app *Application) recalculateLevels() (levelRepairResult, error) {
+	curve := app.levelCurve()
+	var result levelRepairResult
+
+	afterUserID := ""
+	for {
+		users, err := app.UserRepo.GetUserLevelsPage(afterUserID, levelRepairBatchSize)
+		if err != nil {
+			return result, err
+		}
+
+		for _, user := range users {
+			result.UsersChecked++
+			level := curve.LevelForPoints(user.Points)
+			if level == user.Level {
+				continue
+			}
+
+			fixed, err := app.UserRepo.SetLevelIfPointsUnchanged(user.UserID, user.Points, level)
+			if err != nil {
+				return result, err
+			}
+			if fixed {
+				result.UsersFixed++
+				result.Changes = append(result.Changes, levelChange{UserID: user.UserID, Points: user.Points, OldLevel: user.Level, NewLevel: level})
+				log.Printf("level repair: user %s had level %d with %d points, set to %d", user.UserID, user.Level, user.Points, level)
+			}
+		}
+
+		if len(users) < levelRepairBatchSize {
+			return result, nil
+		}
+		afterUserID = users[len(users)-1].UserID
+	}
+}
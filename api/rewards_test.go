@@ -0,0 +1,219 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/color-game/api/datastore"
+	"github.com/color-game/api/models"
+)
+
+// fakeRewardEventRepo answers GetActiveEvent with a fixed event, or err when it is set
+type fakeRewardEventRepo struct {
+	datastore.RewardEventRepository
+	event *models.RewardEvent
+	err   error
+}
+
+func (f *fakeRewardEventRepo) GetActiveEvent(date time.Time) (models.RewardEvent, error) {
+	if f.err != nil {
+		return models.RewardEvent{}, f.err
+	}
+	if f.event == nil {
+		return models.RewardEvent{}, datastore.NoRowsError{NoRows: true, Err: sql.ErrNoRows}
+	}
+	return *f.event, nil
+}
+
+func TestRewardMultiplier(t *testing.T) {
+	date := time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)
+	failure := errors.New("connection reset")
+
+	tests := []struct {
+		name           string
+		repo           *fakeRewardEventRepo
+		wantMultiplier float64
+		wantName       string
+		wantErr        error
+	}{
+		{"no event uses the default", &fakeRewardEventRepo{}, models.DefaultRewardMultiplier, "", nil},
+		{"active event", &fakeRewardEventRepo{event: &models.RewardEvent{Name: "Double Week", Multiplier: 2}}, 2, "Double Week", nil},
+		{"repository error", &fakeRewardEventRepo{err: failure}, 0, "", failure},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := &Application{RewardEventRepo: tt.repo}
+			multiplier, name, err := app.rewardMultiplier(date)
+			if err != tt.wantErr {
+				t.Fatalf("rewardMultiplier error = %v, want %v", err, tt.wantErr)
+			}
+			if multiplier != tt.wantMultiplier || name != tt.wantName {
+				t.Errorf("rewardMultiplier = (%v, %q), want (%v, %q)", multiplier, name, tt.wantMultiplier, tt.wantName)
+			}
+		})
+	}
+}
+
+func TestCalculateDailyRewardsMultiplier(t *testing.T) {
+	rates := rewardRates{PointsPerScorePoint: 10, CreditsPerScorePoint: 0.5}
+
+	tests := []struct {
+		name        string
+		multiplier  float64
+		wantPoints  int
+		wantCredits int
+	}{
+		{"default multiplier", models.DefaultRewardMultiplier, 850, 43},
+		{"double event", 2, 1700, 85},
+		{"fractional event", 1.5, 1275, 64},
+		{"zero falls back to 1x", 0, 850, 43},
+		{"negative falls back to 1x", -2, 850, 43},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := calculateDailyRewards(0, 85, tt.multiplier, rates, nil)
+			if got.PointsAwarded != tt.wantPoints || got.CreditsAwarded != tt.wantCredits {
+				t.Errorf("calculateDailyRewards = %d points and %d credits, want %d and %d",
+					got.PointsAwarded, got.CreditsAwarded, tt.wantPoints, tt.wantCredits)
+			}
+		})
+	}
+}
+
+func TestLevelCurve(t *testing.T) {
+	curve := levelCurve{100, 200, 300}
+
+	tests := []struct {
+		name      string
+		curve     levelCurve
+		points    int
+		wantLevel int
+		wantInto  int
+	}{
+		{"zero points", curve, 0, 1, 0},
+		{"negative points", curve, -50, 1, 0},
+		{"one below level 2", curve, 99, 1, 99},
+		{"exactly level 2", curve, 100, 2, 0},
+		{"one above level 2", curve, 101, 2, 1},
+		{"one below level 3", curve, 299, 2, 199},
+		{"exactly level 3", curve, 300, 3, 0},
+		{"one below level 4", curve, 599, 3, 299},
+		{"exactly level 4", curve, 600, 4, 0},
+		{"last cost repeats", curve, 900, 5, 0},
+		{"well past the curve", curve, 3650, 14, 50},
+		{"flat default at zero", nil, 0, 1, 0},
+		{"flat default one below", nil, 999, 1, 999},
+		{"flat default exactly", nil, 1000, 2, 0},
+		{"flat default one above", nil, 1001, 2, 1},
+		{"single entry curve", levelCurve{50}, 149, 3, 49},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			level, into := tt.curve.levelAndRemainder(tt.points)
+			if level != tt.wantLevel || into != tt.wantInto {
+				t.Errorf("levelAndRemainder(%d) = (%d, %d), want (%d, %d)", tt.points, level, into, tt.wantLevel, tt.wantInto)
+			}
+			if got := tt.curve.LevelForPoints(tt.points); got != tt.wantLevel {
+				t.Errorf("LevelForPoints(%d) = %d, want %d", tt.points, got, tt.wantLevel)
+			}
+		})
+	}
+}
+
+func TestPointsForLevelUp(t *testing.T) {
+	curve := levelCurve{100, 200, 300}
+
+	tests := []struct {
+		curve levelCurve
+		level int
+		want  int
+	}{
+		{curve, 1, 100},
+		{curve, 2, 200},
+		{curve, 3, 300},
+		{curve, 4, 300},
+		{curve, 50, 300},
+		{nil, 1, pointsPerLevel},
+		{nil, 50, pointsPerLevel},
+	}
+
+	for _, tt := range tests {
+		if got := tt.curve.pointsForLevelUp(tt.level); got != tt.want {
+			t.Errorf("%v.pointsForLevelUp(%d) = %d, want %d", tt.curve, tt.level, got, tt.want)
+		}
+	}
+}
+
+// fakeSettingsRepo lists a fixed set of runtime settings
+type fakeSettingsRepo struct {
+	datastore.SettingsRepository
+	settings []models.Setting
+}
+
+func (f *fakeSettingsRepo) ListSettings() ([]models.Setting, error) {
+	return f.settings, nil
+}
+
+func TestRewardRates(t *testing.T) {
+	config := Config{PointsPerScorePoint: 10, CreditsPerScorePoint: 1}
+
+	tests := []struct {
+		name     string
+		settings []models.Setting
+		want     rewardRates
+	}{
+		{"config defaults", nil, rewardRates{PointsPerScorePoint: 10, CreditsPerScorePoint: 1}},
+		{"runtime overrides", []models.Setting{
+			{Key: models.SettingPointsPerScorePoint, Value: "2.5"},
+			{Key: models.SettingCreditsPerScorePoint, Value: "0"},
+		}, rewardRates{PointsPerScorePoint: 2.5, CreditsPerScorePoint: 0}},
+		{"invalid override keeps the default", []models.Setting{
+			{Key: models.SettingPointsPerScorePoint, Value: "-3"},
+		}, rewardRates{PointsPerScorePoint: 10, CreditsPerScorePoint: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cachedSettings.invalidate()
+			t.Cleanup(cachedSettings.invalidate)
+
+			app := &Application{Config: config, SettingsRepo: &fakeSettingsRepo{settings: tt.settings}}
+			if got := app.rewardRates(); got != tt.want {
+				t.Errorf("rewardRates() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalculateDailyRewardsRates(t *testing.T) {
+	curve := levelCurve{100}
+
+	tests := []struct {
+		name          string
+		currentPoints int
+		bestScore     int
+		rates         rewardRates
+		want          dailyRewards
+	}{
+		{"zero rates award nothing", 50, 90, rewardRates{}, dailyRewards{}},
+		{"zero point rate still pays credits", 50, 90, rewardRates{CreditsPerScorePoint: 1}, dailyRewards{CreditsAwarded: 90}},
+		{"zero credit rate still pays points", 50, 90, rewardRates{PointsPerScorePoint: 1}, dailyRewards{PointsAwarded: 90, LevelUps: 1}},
+		{"custom rates", 0, 80, rewardRates{PointsPerScorePoint: 2.5, CreditsPerScorePoint: 0.1}, dailyRewards{PointsAwarded: 200, CreditsAwarded: 8, LevelUps: 2}},
+		{"points round and credits round up", 0, 33, rewardRates{PointsPerScorePoint: 0.5, CreditsPerScorePoint: 0.01}, dailyRewards{PointsAwarded: 17, CreditsAwarded: 1}},
+		{"zero score", 0, 0, rewardRates{PointsPerScorePoint: 10, CreditsPerScorePoint: 1}, dailyRewards{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := calculateDailyRewards(tt.currentPoints, tt.bestScore, models.DefaultRewardMultiplier, tt.rates, curve)
+			if got != tt.want {
+				t.Errorf("calculateDailyRewards = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
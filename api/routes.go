@@ -36,6 +36,9 @@ func isAllowedOrigin(origin string, allowedOrigins []string) bool {
 }
 
 func wrapMuxWithCorsAndOrigins(mux *http.ServeMux, app Application) http.Handler {
+	allowMethods := corsHeaderValue(app.Config.AllowedMethods)
+	allowHeaders := corsHeaderValue(app.Config.AllowedHeaders)
+
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		origin := r.Header.Get("Origin")
 
@@ -47,13 +50,13 @@ func wrapMuxWithCorsAndOrigins(mux *http.ServeMux, app Application) http.Handler
 		}
 
 		if origin == "" {
-			handleCors(mux.ServeHTTP)(w, r)
+			handleCors(mux.ServeHTTP, allowMethods, allowHeaders)(w, r)
 			return
 		}
 
 		// Check if origin is allowed
 		if isAllowedOrigin(origin, app.Config.AllowedOrigins) {
-			handleCors(mux.ServeHTTP)(w, r)
+			handleCors(mux.ServeHTTP, allowMethods, allowHeaders)(w, r)
 			return
 		}
 
@@ -65,54 +68,109 @@ func wrapMuxWithCorsAndOrigins(mux *http.ServeMux, app Application) http.Handler
 func (app Application) BuildRoutes(mux *http.ServeMux) *http.ServeMux {
 	finalMux := http.NewServeMux()
 
-	// Public endpoints
-	mux.HandleFunc("/", app.home)
+	// Public endpoints. "/{$}" matches only the root; everything unmatched falls through to "/"
+	mux.HandleFunc("/{$}", app.home)
+	mux.HandleFunc("/", app.routeNotFound)
 	mux.HandleFunc("/v1/auth/signup", app.signup)
 	mux.HandleFunc("/v1/auth/login", app.login)
 	mux.HandleFunc("/v1/colors/random", app.getRandomColor)
-	mux.HandleFunc("/v1/colors/daily", app.getDailyColor)
+	mux.HandleFunc("/v1/colors/daily", app.gameplay(app.getDailyColor))
 	mux.HandleFunc("/v1/colors/daily/all", app.getAllDailyColors)
+	mux.HandleFunc("/v1/colors/daily/palette", app.gameplay(app.getDailyPalette))
 	mux.HandleFunc("/v1/leaderboard", app.getLeaderboard)
+	mux.HandleFunc("/v1/activity/recent", app.getRecentActivity)
+	mux.HandleFunc("/v1/stats/global", app.getGlobalStats)
+	mux.HandleFunc("/v1/game/scoring", app.getScoringParameters)
+	mux.HandleFunc("/v1/leaderboard/distribution", app.authenticate(app.getScoreDistribution))
 
 	// Authenticated endpoints
+	mux.HandleFunc("/v1/auth/logout", app.authenticate(app.logout))
 	mux.HandleFunc("/v1/users/me", app.authenticate(app.getCurrentUser))
 	mux.HandleFunc("/v1/users/me/update", app.authenticate(app.updateCurrentUser))
-	mux.HandleFunc("/v1/scores/submit", app.authenticate(app.submitScore))
+	mux.HandleFunc("/v1/users/me/avatar", app.authenticate(app.updateAvatar))
+	mux.HandleFunc("/v1/users/me/privacy", app.authenticate(app.updatePrivacy))
+	mux.HandleFunc("/v1/users/me/level", app.authenticate(app.getLevelProgress))
+	mux.HandleFunc("/v1/users/me/best", app.authenticate(app.getPersonalBest))
+	mux.HandleFunc("/v1/users/me/export", app.authenticate(app.exportUserData))
+	mux.HandleFunc("/v1/game/status", app.authenticate(app.getGameStatus))
+	mux.HandleFunc("/v1/colors/daily/answer", app.authenticate(app.getDailyColorAnswer))
+	mux.HandleFunc("/v1/scores/submit", app.gameplay(app.authenticate(app.submitScore)))
+	mux.HandleFunc("/v1/scores/preview", app.gameplay(app.authenticate(app.previewScore)))
+	mux.HandleFunc("/v1/scores/reset", app.gameplay(app.authenticate(app.resetOwnDailyAttempts)))
 	mux.HandleFunc("/v1/scores/history", app.authenticate(app.getUserScoreHistory))
+	mux.HandleFunc("/v1/scores/attempts", app.authenticate(app.getScoreAttempts))
+	mux.HandleFunc("/v1/scores/attempts/{id}/breakdown", app.authenticate(app.getAttemptBreakdown))
 
 	// Friends endpoints
 	mux.HandleFunc("/v1/friends", app.authenticate(app.getFriends))
 	mux.HandleFunc("/v1/friends/requests", app.authenticate(app.getFriendRequests))
 	mux.HandleFunc("/v1/friends/search", app.authenticate(app.searchFriends))
 	mux.HandleFunc("/v1/friends/request", app.authenticate(app.createFriendRequest))
+	mux.HandleFunc("/v1/friends/request/batch", app.authenticate(app.createFriendRequestBatch))
 	mux.HandleFunc("/v1/friends/respond", app.authenticate(app.respondToFriendRequest))
 	mux.HandleFunc("/v1/friends/remove", app.authenticate(app.removeFriend))
 	mux.HandleFunc("/v1/friends/activity", app.authenticate(app.getFriendActivity))
+	mux.HandleFunc("/v1/friends/today", app.authenticate(app.getFriendsToday))
+	mux.HandleFunc("/v1/friends/{id}/head-to-head", app.authenticate(app.getHeadToHead))
 
 	// Shop endpoints (public - browse items)
 	mux.HandleFunc("/v1/shop/items", app.getShopItems)
+	mux.HandleFunc("/v1/shop/items/batch", app.getShopItemsBatch)
+	mux.HandleFunc("/v1/shop/items/by-rarity", app.getShopItemsByRarity)
+	mux.HandleFunc("/v1/shop/featured", app.getFeaturedShopItems)
 
 	// Shop endpoints (authenticated)
-	mux.HandleFunc("/v1/shop/purchase", app.authenticate(app.purchaseItem))
+	mux.HandleFunc("/v1/shop/items/available", app.authenticate(app.getAvailableShopItems))
+	mux.HandleFunc("/v1/shop/purchase", app.gameplay(app.authenticate(app.purchaseItem)))
 	mux.HandleFunc("/v1/inventory", app.authenticate(app.getUserInventory))
 	mux.HandleFunc("/v1/inventory/equipped", app.authenticate(app.getEquippedItems))
 	mux.HandleFunc("/v1/inventory/equip", app.authenticate(app.equipItem))
-	mux.HandleFunc("/v1/inventory/use", app.authenticate(app.useItem))
+	mux.HandleFunc("/v1/inventory/unequip-all", app.authenticate(app.unequipAllItems))
+	mux.HandleFunc("/v1/inventory/use", app.gameplay(app.authenticate(app.useItem)))
+	mux.HandleFunc("/v1/inventory/{id}/preview", app.authenticate(app.previewItem))
 	mux.HandleFunc("/v1/shop/purchases", app.authenticate(app.getPurchaseHistory))
+	mux.HandleFunc("/v1/shop/purchases/summary", app.authenticate(app.getSpendingSummary))
+	mux.HandleFunc("/v1/shop/purchases/{id}", app.authenticate(app.getPurchase))
+
+	// Trade endpoints
+	mux.HandleFunc("/v1/trades", app.authenticate(app.getTrades))
+	mux.HandleFunc("/v1/trades/propose", app.authenticate(app.proposeTrade))
+	mux.HandleFunc("/v1/trades/respond", app.authenticate(app.respondToTrade))
 
 	// Admin endpoints
 	mux.HandleFunc("/v1/users", app.verifyPermissions(app.getAllUsers))
+	mux.HandleFunc("/v1/admin/users/recalculate-levels", app.verifyPermissions(app.recalculateUserLevels))
+	mux.HandleFunc("/v1/admin/users/{id}/devices", app.verifyPermissions(app.adminUserDevices))
+	mux.HandleFunc("/v1/admin/users/{id}/devices/{deviceId}", app.verifyPermissions(app.adminRevokeUserDevice))
+	mux.HandleFunc("/v1/admin/impersonate", app.verifyPermissions(app.impersonateUser))
+	mux.HandleFunc("/v1/admin/maintenance", app.verifyPermissions(app.adminMaintenanceMode))
+	mux.HandleFunc("/v1/admin/settings", app.verifyPermissions(app.getSettings))
+	mux.HandleFunc("/v1/admin/settings/{key}", app.verifyPermissions(app.updateSettingHandler))
 	mux.HandleFunc("/v1/admin/colors/generate", app.verifyPermissions(app.generateDailyColor))
+	mux.HandleFunc("/v1/admin/colors/status", app.verifyPermissions(app.getDailyColorStatus))
+	mux.HandleFunc("/v1/admin/colors/backfill", app.verifyPermissions(app.backfillDailyColors))
+	mux.HandleFunc("/v1/admin/colors/curated", app.verifyPermissions(app.enqueueCuratedColor))
+	mux.HandleFunc("/v1/admin/colors/curated/all", app.verifyPermissions(app.getCuratedColors))
 	mux.HandleFunc("/v1/admin/shop/items", app.verifyPermissions(app.createShopItem))
 	mux.HandleFunc("/v1/admin/shop/items/all", app.verifyPermissions(app.getAllShopItems))
 	mux.HandleFunc("/v1/admin/shop/items/update", app.verifyPermissions(app.updateShopItem))
 	mux.HandleFunc("/v1/admin/shop/items/delete", app.verifyPermissions(app.deactivateShopItem))
 	mux.HandleFunc("/v1/admin/users/credits", app.verifyPermissions(app.addUserCredits))
 	mux.HandleFunc("/v1/admin/shop/purchases", app.verifyPermissions(app.getAdminPurchases))
+	mux.HandleFunc("/v1/admin/shop/refund", app.verifyPermissions(app.refundPurchase))
 	mux.HandleFunc("/v1/admin/scores/reset", app.verifyPermissions(app.resetUserDailyAttempts))
-
-	// Wrap entire mux with CORS and origins check
-	finalMux.Handle("/", wrapMuxWithCorsAndOrigins(mux, app))
+	mux.HandleFunc("/v1/admin/events", app.verifyPermissions(app.createRewardEvent))
+	mux.HandleFunc("/v1/admin/events/all", app.verifyPermissions(app.getRewardEvents))
+	mux.HandleFunc("/v1/admin/events/update", app.verifyPermissions(app.updateRewardEvent))
+	mux.HandleFunc("/v1/admin/events/delete", app.verifyPermissions(app.deleteRewardEvent))
+	mux.HandleFunc("/v1/admin/invites", app.verifyPermissions(app.createInviteCodes))
+	mux.HandleFunc("/v1/admin/invites/all", app.verifyPermissions(app.getInviteCodes))
+
+	// Wrap entire mux with CORS and origins check and the concurrency limit, under the request deadline
+	finalMux.Handle("/", app.withRequestTimeout(app.limitConcurrency(wrapMuxWithCorsAndOrigins(mux, app))))
+
+	// Build metadata is served without the origin check so deploy tooling can always reach it
+	finalMux.HandleFunc("/v1/version", app.getVersion)
 
 	return finalMux
 }
@@ -0,0 +1,138 @@
+package api
+
+import (
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/color-game/api/datastore"
+	"github.com/color-game/api/models"
+)
+
+// scoreBucketSize is the width of each histogram bucket, in score points
+const scoreBucketSize = 10
+
+// scoreDistributionTTL is how long a computed histogram is reused before it's recomputed
+const scoreDistributionTTL = 30 * time.Second
+
+// scoreDistributionCache holds the most recently computed histogram for a single date
+type scoreDistributionCache struct {
+	mu        sync.Mutex
+	date      string
+	buckets   []models.ScoreBucket
+	expiresAt time.Time
+}
+
+// todaysScoreDistribution is shared by all requests; it only ever holds one day's histogram
+var todaysScoreDistribution scoreDistributionCache
+
+// get returns the cached buckets for date, computing and storing them with load when stale
+func (c *scoreDistributionCache) get(date string, load func() ([]models.ScoreBucket, error)) ([]models.ScoreBucket, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.date == date && time.Now().Before(c.expiresAt) {
+		return c.buckets, nil
+	}
+
+	buckets, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	c.date = date
+	c.buckets = buckets
+	c.expiresAt = time.Now().Add(scoreDistributionTTL)
+	return buckets, nil
+}
+
+// fillScoreBuckets returns every bucket from 0 to 100, taking counts from the non-empty ones
+func fillScoreBuckets(counted []models.ScoreBucket) ([]models.ScoreBucket, int) {
+	counts := make(map[int]int, len(counted))
+	for _, bucket := range counted {
+		counts[bucket.MinScore] = bucket.Players
+	}
+
+	var buckets []models.ScoreBucket
+	total := 0
+	for start := 0; start < 100; start += scoreBucketSize {
+		end := start + scoreBucketSize - 1
+		if end >= 99 {
+			end = 100
+		}
+		buckets = append(buckets, models.ScoreBucket{MinScore: start, MaxScore: end, Players: counts[start]})
+		total += counts[start]
+	}
+	return buckets, total
+}
+
+// rankPercentile converts a 1-based rank among total players into the percentage of the other
+// players ranked below it: the best player is at 100, the worst at 0, and a lone player at 100
+func rankPercentile(rank, total int) float64 {
+	if total <= 1 {
+		return 100
+	}
+	if rank < 1 {
+		rank = 1
+	}
+	if rank > total {
+		rank = total
+	}
+	return math.Round(float64(total-rank)/float64(total-1)*10000) / 100
+}
+
+// GET /v1/leaderboard/distribution - Get today's best-score histogram and the caller's percentile
+func (app *Application) getScoreDistribution(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	user, err := app.getUserFromToken(w, r)
+	if err != nil {
+		return
+	}
+
+	today := time.Now()
+	normalizedToday := time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, today.Location())
+	date := normalizedToday.Format("2006-01-02")
+
+	counted, err := todaysScoreDistribution.get(date, func() ([]models.ScoreBucket, error) {
+		return app.DailyLeaderboardRepo.GetScoreDistribution(normalizedToday, scoreBucketSize)
+	})
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	buckets, total := fillScoreBuckets(counted)
+	response := models.ScoreDistributionResponse{
+		Date:         date,
+		TotalPlayers: total,
+		Buckets:      buckets,
+	}
+
+	// The caller's standing is always live; only the histogram is cached
+	entry, err := app.DailyLeaderboardRepo.GetByUserAndDate(user.UserID, normalizedToday)
+	if err == nil {
+		rank, err := app.DailyLeaderboardRepo.GetUserRankByDate(user.UserID, normalizedToday)
+		if err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+		// The cached total may lag behind a score submitted since it was computed
+		if total < rank {
+			total = rank
+		}
+		percentile := rankPercentile(rank, total)
+		response.BestScore = &entry.BestScore
+		response.Rank = &rank
+		response.Percentile = &percentile
+	} else if _, ok := err.(datastore.NoRowsError); !ok {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,29 @@
+package api
+
+import "testing"
+
+func TestRankPercentile(t *testing.T) {
+	tests := []struct {
+		name  string
+		rank  int
+		total int
+		want  float64
+	}{
+		{"lone player", 1, 1, 100},
+		{"no players", 1, 0, 100},
+		{"best of many", 1, 5, 100},
+		{"worst of many", 5, 5, 0},
+		{"middle", 3, 5, 50},
+		{"rounded to two decimals", 2, 4, 66.67},
+		{"rank below one clamps to best", 0, 5, 100},
+		{"rank past total clamps to worst", 9, 5, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rankPercentile(tt.rank, tt.total); got != tt.want {
+				t.Errorf("rankPercentile(%d, %d) = %v, want %v", tt.rank, tt.total, got, tt.want)
+			}
+		})
+	}
+}
@@ -11,14 +11,26 @@ import (
 	"time"
 )
 
-func (app *Application) Serve(mux *http.ServeMux) error {
-	srv := &http.Server{
-		Addr:         app.Config.HTTPPort,
-		Handler:      app.BuildRoutes(mux),
-		IdleTimeout:  time.Minute,
-		ReadTimeout:  10 * time.Second,
-		WriteTimeout: 30 * time.Second,
+// newServer builds the HTTP server using the configured timeouts
+func (app *Application) newServer(handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              app.Config.HTTPPort,
+		Handler:           handler,
+		IdleTimeout:       time.Duration(app.Config.ServerIdleTimeout) * time.Second,
+		ReadTimeout:       time.Duration(app.Config.ServerReadTimeout) * time.Second,
+		ReadHeaderTimeout: time.Duration(app.Config.ServerReadHeaderTimeout) * time.Second,
+		WriteTimeout:      time.Duration(app.Config.ServerWriteTimeout) * time.Second,
 	}
+}
+
+// disableWriteDeadline lets a long-lived streaming response (SSE, WebSocket upgrade)
+// outlive the server's WriteTimeout. Call it before writing the first byte.
+func disableWriteDeadline(w http.ResponseWriter) error {
+	return http.NewResponseController(w).SetWriteDeadline(time.Time{})
+}
+
+func (app *Application) Serve(mux *http.ServeMux) error {
+	srv := app.newServer(app.BuildRoutes(mux))
 	shutdownErr := make(chan error)
 
 	go func() {
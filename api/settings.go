@@ -0,0 +1,313 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/color-game/api/models"
+)
+
+// settingsRefresh is how often each instance rereads runtime settings, and so how long a change made
+// through one instance takes to reach the others
+const settingsRefresh = 10 * time.Second
+
+// settingDefinition describes a setting operators may change at runtime. Its environment value is the
+// default until an admin sets it, and again after the runtime value is deleted.
+type settingDefinition struct {
+	Key         string
+	Type        string
+	Description string
+	Default     func(c Config) string
+	Validate    func(value string) error
+}
+
+// runtimeSettings lists every setting that can change without a restart
+var runtimeSettings = []settingDefinition{
+	{
+		Key:         models.SettingMaintenanceMode,
+		Type:        "bool",
+		Description: "Pause gameplay endpoints with a 503",
+		Default:     func(c Config) string { return strconv.FormatBool(c.MaintenanceMode) },
+		Validate:    validateBoolSetting,
+	},
+	{
+		Key:         models.SettingBaseDailyAttempts,
+		Type:        "int",
+		Description: fmt.Sprintf("Daily attempts before level bonuses and extras, 1 to %d", maxDailyAttempts),
+		Default:     func(c Config) string { return strconv.Itoa(baseDailyAttempts) },
+		Validate:    validateIntSetting(1, maxDailyAttempts),
+	},
+	{
+		Key:         models.SettingPointsPerScorePoint,
+		Type:        "float",
+		Description: "Points awarded per point of the day's best score",
+		Default:     func(c Config) string { return strconv.FormatFloat(c.PointsPerScorePoint, 'g', -1, 64) },
+		Validate:    validateRateSetting,
+	},
+	{
+		Key:         models.SettingCreditsPerScorePoint,
+		Type:        "float",
+		Description: "Credits awarded per point of the day's best score",
+		Default:     func(c Config) string { return strconv.FormatFloat(c.CreditsPerScorePoint, 'g', -1, 64) },
+		Validate:    validateRateSetting,
+	},
+}
+
+func validateBoolSetting(value string) error {
+	if _, err := strconv.ParseBool(value); err != nil {
+		return errors.New("must be true or false")
+	}
+	return nil
+}
+
+func validateIntSetting(min, max int) func(string) error {
+	return func(value string) error {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < min || n > max {
+			return fmt.Errorf("must be a whole number from %d to %d", min, max)
+		}
+		return nil
+	}
+}
+
+func validateRateSetting(value string) error {
+	rate, err := strconv.ParseFloat(value, 64)
+	if err != nil || rate < 0 || rate > 100 {
+		return errors.New("must be a number from 0 to 100")
+	}
+	return nil
+}
+
+// findSettingDefinition looks up a runtime setting by key
+func findSettingDefinition(key string) (settingDefinition, bool) {
+	for _, def := range runtimeSettings {
+		if def.Key == key {
+			return def, true
+		}
+	}
+	return settingDefinition{}, false
+}
+
+// settingsCache holds the runtime values last read from the settings table, keyed by setting
+type settingsCache struct {
+	mu        sync.Mutex
+	values    map[string]models.Setting
+	expiresAt time.Time
+}
+
+// cachedSettings is shared by all requests so reading a setting doesn't query the database
+var cachedSettings settingsCache
+
+// get returns the cached values, rereading them with load when stale. If the read fails, the last known
+// values are kept, so a database hiccup doesn't flip settings back to their defaults.
+func (c *settingsCache) get(load func() ([]models.Setting, error)) map[string]models.Setting {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.values != nil && time.Now().Before(c.expiresAt) {
+		return c.values
+	}
+
+	c.expiresAt = time.Now().Add(settingsRefresh)
+	settings, err := load()
+	if err != nil {
+		log.Printf("Failed to refresh runtime settings, keeping the last known values: %v", err)
+		if c.values == nil {
+			return map[string]models.Setting{}
+		}
+		return c.values
+	}
+
+	values := make(map[string]models.Setting, len(settings))
+	for _, setting := range settings {
+		values[setting.Key] = setting
+	}
+	c.values = values
+	return values
+}
+
+// invalidate makes the next read reload, so a change shows up on this instance straight away
+func (c *settingsCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.expiresAt = time.Time{}
+}
+
+// loadSettings reads every runtime value from the settings table
+func (app *Application) loadSettings() ([]models.Setting, error) {
+	if app.SettingsRepo == nil {
+		return nil, nil
+	}
+	return app.SettingsRepo.ListSettings()
+}
+
+// settingValue returns a setting's effective value: the runtime value when one is set and valid,
+// otherwise the environment default
+func (app *Application) settingValue(key string) string {
+	def, ok := findSettingDefinition(key)
+	if !ok {
+		return ""
+	}
+	if setting, ok := cachedSettings.get(app.loadSettings)[key]; ok && def.Validate(setting.Value) == nil {
+		return setting.Value
+	}
+	return def.Default(app.Config)
+}
+
+// settingBool returns a bool runtime setting
+func (app *Application) settingBool(key string) bool {
+	value, _ := strconv.ParseBool(app.settingValue(key))
+	return value
+}
+
+// settingInt returns an int runtime setting
+func (app *Application) settingInt(key string) int {
+	value, _ := strconv.Atoi(app.settingValue(key))
+	return value
+}
+
+// settingFloat returns a float runtime setting
+func (app *Application) settingFloat(key string) float64 {
+	value, _ := strconv.ParseFloat(app.settingValue(key), 64)
+	return value
+}
+
+// describeSetting combines a definition with its runtime value, if any
+func (app *Application) describeSetting(def settingDefinition, setting *models.Setting) models.RuntimeSetting {
+	described := models.RuntimeSetting{
+		Key:         def.Key,
+		Type:        def.Type,
+		Description: def.Description,
+		Default:     def.Default(app.Config),
+	}
+	described.Value = described.Default
+	if setting != nil && def.Validate(setting.Value) == nil {
+		described.Value = setting.Value
+		described.Overridden = true
+		described.UpdatedBy = setting.UpdatedBy
+		described.UpdatedAt = &setting.UpdatedAt
+	}
+	return described
+}
+
+// updateSetting validates and stores a runtime value, then audits the change
+func (app *Application) updateSetting(adminID string, def settingDefinition, value string) (models.Setting, error) {
+	setting, err := app.SettingsRepo.SetSetting(def.Key, value, adminID)
+	if err != nil {
+		return models.Setting{}, err
+	}
+	cachedSettings.invalidate()
+
+	details, _ := json.Marshal(map[string]string{"key": def.Key, "value": value})
+	if _, err := app.AuditLogRepo.RecordAuditEntry(models.AuditEntry{
+		AdminID: adminID,
+		Action:  models.AuditActionUpdateSetting,
+		Details: details,
+	}); err != nil {
+		log.Printf("Failed to audit setting %s change by %s: %v", def.Key, adminID, err)
+	}
+	return setting, nil
+}
+
+// GET /v1/admin/settings - Every runtime setting with its effective value and default (Admin only)
+func (app *Application) getSettings(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Read straight from the database so admins never see a stale value
+	stored, err := app.SettingsRepo.ListSettings()
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	byKey := make(map[string]models.Setting, len(stored))
+	for _, setting := range stored {
+		byKey[setting.Key] = setting
+	}
+
+	settings := make([]models.RuntimeSetting, 0, len(runtimeSettings))
+	for _, def := range runtimeSettings {
+		var setting *models.Setting
+		if s, ok := byKey[def.Key]; ok {
+			setting = &s
+		}
+		settings = append(settings, app.describeSetting(def, setting))
+	}
+
+	app.writeList(w, "settings", settings, len(settings))
+}
+
+// PUT /v1/admin/settings/{key} - Change a runtime setting with {"value": "..."} (Admin only, audited)
+// DELETE /v1/admin/settings/{key} - Go back to the environment default (Admin only, audited)
+func (app *Application) updateSettingHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut && r.Method != http.MethodDelete {
+		app.requirePutMethod(w, r, ErrPUT)
+		return
+	}
+
+	admin, err := app.getUserFromToken(w, r)
+	if err != nil {
+		return
+	}
+
+	def, ok := findSettingDefinition(r.PathValue("key"))
+	if !ok {
+		http.Error(w, "Setting not found", http.StatusNotFound)
+		return
+	}
+
+	if r.Method == http.MethodDelete {
+		if _, err := app.SettingsRepo.DeleteSetting(def.Key); err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+		cachedSettings.invalidate()
+
+		details, _ := json.Marshal(map[string]string{"key": def.Key})
+		if _, err := app.AuditLogRepo.RecordAuditEntry(models.AuditEntry{
+			AdminID: admin.UserID,
+			Action:  models.AuditActionDeleteSetting,
+			Details: details,
+		}); err != nil {
+			log.Printf("Failed to audit setting %s reset by %s: %v", def.Key, admin.UserID, err)
+		}
+
+		app.writeJSON(w, http.StatusOK, app.describeSetting(def, nil))
+		return
+	}
+
+	var req struct {
+		Value *string `json:"value"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		app.badJSONRequest(w, r, err)
+		return
+	}
+
+	var v validator
+	if req.Value == nil {
+		v.check(false, "value", "value is required")
+	} else {
+		v.checkErr("value", def.Validate(*req.Value))
+	}
+	if !v.valid() {
+		app.validationFailed(w, r, &v)
+		return
+	}
+
+	setting, err := app.updateSetting(admin.UserID, def, *req.Value)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.writeJSON(w, http.StatusOK, app.describeSetting(def, &setting))
+}
@@ -5,8 +5,11 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"log"
+	"math"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/color-game/api/datastore"
@@ -15,6 +18,40 @@ import (
 
 // ============= SHOP ITEMS =============
 
+// publicShopItem hides the stock count on an item that isn't a limited edition. Limited editions keep
+// their remaining stock so the shop can show "only 12 left"; for other items the count stays admin-only.
+// Every response a player sees that carries a shop item goes through it.
+func publicShopItem(item models.ShopItem) models.ShopItem {
+	if !item.IsLimitedEdition {
+		item.StockQuantity = nil
+	}
+	return item
+}
+
+// publicShopItems applies publicShopItem to a list of items
+func publicShopItems(items []models.ShopItem) []models.ShopItem {
+	for i := range items {
+		items[i] = publicShopItem(items[i])
+	}
+	return items
+}
+
+// publicInventory applies publicShopItem to the item in each inventory entry
+func publicInventory(inventory []models.UserInventoryWithItem) []models.UserInventoryWithItem {
+	for i := range inventory {
+		inventory[i].ShopItem = publicShopItem(inventory[i].ShopItem)
+	}
+	return inventory
+}
+
+// publicPurchases applies publicShopItem to the item in each purchase
+func publicPurchases(purchases []models.PurchaseRecordWithItem) []models.PurchaseRecordWithItem {
+	for i := range purchases {
+		purchases[i].ShopItem = publicShopItem(purchases[i].ShopItem)
+	}
+	return purchases
+}
+
 // GET /v1/shop/items - Get all active shop items
 func (app *Application) getShopItems(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
@@ -39,8 +76,101 @@ func (app *Application) getShopItems(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(items)
+	app.writeList(w, "", publicShopItems(items), len(items))
+}
+
+// GET /v1/shop/items/by-rarity - Active shop items grouped into rarity tiers, most common first
+func (app *Application) getShopItemsByRarity(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	items, err := app.ShopRepo.GetActiveItems()
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	tiers := groupItemsByRarity(publicShopItems(items))
+	app.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"total": len(items),
+		"tiers": tiers,
+	})
+}
+
+// groupItemsByRarity returns one tier per rarity in models.Rarities order, keeping the items' order
+// within each tier. Every tier is present even when empty. Items without a known rarity count as common.
+func groupItemsByRarity(items []models.ShopItem) []models.RarityTier {
+	tiers := make([]models.RarityTier, len(models.Rarities))
+	index := make(map[string]int, len(models.Rarities))
+	for i, rarity := range models.Rarities {
+		tiers[i] = models.RarityTier{Rarity: rarity, Items: []models.ShopItem{}}
+		index[rarity] = i
+	}
+
+	for _, item := range items {
+		i, ok := index[item.Rarity]
+		if !ok {
+			i = index[models.RarityCommon]
+		}
+		tiers[i].Items = append(tiers[i].Items, item)
+		tiers[i].Count++
+	}
+
+	if len(items) > 0 {
+		for i := range tiers {
+			tiers[i].Share = math.Round(float64(tiers[i].Count)/float64(len(items))*10000) / 100
+		}
+	}
+	return tiers
+}
+
+// GET /v1/shop/featured - Get the active items admins have featured
+func (app *Application) getFeaturedShopItems(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	items, err := app.ShopRepo.GetFeaturedItems()
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.writeList(w, "", publicShopItems(items), len(items))
+}
+
+// GET /v1/shop/items/available - Get active items the user doesn't own yet, optionally filtered by type and rarity
+func (app *Application) getAvailableShopItems(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Get current user from token
+	user, err := app.getUserFromToken(w, r)
+	if err != nil {
+		return
+	}
+
+	items, err := app.ShopRepo.GetUnownedActiveItems(user.UserID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	itemType := r.URL.Query().Get("type")
+	rarity := r.URL.Query().Get("rarity")
+	available := []models.ShopItem{}
+	for _, item := range items {
+		if (itemType == "" || item.ItemType == itemType) && (rarity == "" || item.Rarity == rarity) {
+			available = append(available, item)
+		}
+	}
+
+	app.writeList(w, "", publicShopItems(available), len(available))
 }
 
 // GET /v1/shop/items/:id - Get a specific shop item
@@ -66,8 +196,63 @@ func (app *Application) getShopItem(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(item)
+	app.writeJSON(w, http.StatusOK, publicShopItem(item))
+}
+
+// maxBatchItemIDs caps how many items a single batch request can load
+const maxBatchItemIDs = 100
+
+// POST /v1/shop/items/batch - Get several listed shop items by ID in one request; IDs of unknown or
+// unlisted items come back in notFound
+func (app *Application) getShopItemsBatch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		app.requirePostMethod(w, r, ErrPOST)
+		return
+	}
+
+	var batchReq models.BatchShopItemsRequest
+	if err := json.NewDecoder(r.Body).Decode(&batchReq); err != nil {
+		app.badJSONRequest(w, r, err)
+		return
+	}
+
+	if len(batchReq.ItemIDs) == 0 {
+		app.badRequest(w, r, errors.New("itemIds is required"))
+		return
+	}
+
+	if len(batchReq.ItemIDs) > maxBatchItemIDs {
+		app.badRequest(w, r, fmt.Errorf("at most %d itemIds can be requested at once", maxBatchItemIDs))
+		return
+	}
+
+	items, err := app.ShopRepo.GetActiveItemsByIDs(batchReq.ItemIDs)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	found := make(map[string]bool, len(items))
+	for _, item := range items {
+		found[item.ItemID] = true
+	}
+
+	notFound := []string{}
+	for _, itemID := range batchReq.ItemIDs {
+		if !found[itemID] {
+			notFound = append(notFound, itemID)
+			found[itemID] = true
+		}
+	}
+
+	if items == nil {
+		items = []models.ShopItem{}
+	}
+
+	app.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"items":    publicShopItems(items),
+		"notFound": notFound,
+	})
 }
 
 // POST /v1/shop/purchase - Purchase an item
@@ -113,9 +298,13 @@ func (app *Application) purchaseItem(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Check stock availability
+	// Check stock availability; nil stock is unlimited
+	if item.StockQuantity != nil && *item.StockQuantity == 0 {
+		app.badRequest(w, r, errors.New("item is sold out"))
+		return
+	}
 	if item.StockQuantity != nil && *item.StockQuantity < purchaseReq.Quantity {
-		app.badRequest(w, r, errors.New("insufficient stock available"))
+		app.badRequest(w, r, datastore.ErrInsufficientStock)
 		return
 	}
 
@@ -128,39 +317,25 @@ func (app *Application) purchaseItem(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Start transaction logic
-	// 1. Deduct credits from user
-	user.Credits -= totalCost
-	_, err = app.UserRepo.Update(user)
+	// Auto-apply items take effect now instead of going to inventory
+	itemMetadata, err := parseItemMetadata(item.Metadata)
 	if err != nil {
-		app.internalServerError(w, r, fmt.Errorf("failed to deduct credits: %v", err))
+		app.internalServerError(w, r, err)
 		return
 	}
+	autoApply := isAutoApply(itemMetadata)
 
-	// 2. Add item to user's inventory
-	err = app.ShopRepo.AddItemToInventory(user.UserID, item.ItemID, purchaseReq.Quantity, nil)
-	if err != nil {
-		// Rollback: Add credits back
-		user.Credits += totalCost
-		app.UserRepo.Update(user)
-		app.internalServerError(w, r, fmt.Errorf("failed to add item to inventory: %v", err))
-		return
+	// Auto-applied items never reach the inventory, so there's nothing to hold a limit against
+	limit := 0
+	if !autoApply {
+		limit = maxPerUser(item.ItemType, itemMetadata)
 	}
-
-	// 3. Update stock if limited edition
-	if item.StockQuantity != nil {
-		newStock := *item.StockQuantity - purchaseReq.Quantity
-		updates := models.UpdateShopItemRequest{
-			StockQuantity: &newStock,
-		}
-		_, err = app.ShopRepo.UpdateItem(item.ItemID, updates)
-		if err != nil {
-			// Note: This is a non-critical error, log but don't fail the purchase
-			fmt.Printf("Warning: Failed to update stock for item %s: %v\n", item.ItemID, err)
-		}
+	if limit > 0 && purchaseReq.Quantity > limit {
+		app.badRequest(w, r, fmt.Errorf("you can own at most %d of this item", limit))
+		return
 	}
 
-	// 4. Record the purchase
+	// Charge credits, take stock, stock the inventory and record the purchase atomically
 	purchase := models.PurchaseRecord{
 		PurchaseID:   models.GeneratePurchaseID(),
 		UserID:       user.UserID,
@@ -169,24 +344,66 @@ func (app *Application) purchaseItem(w http.ResponseWriter, r *http.Request) {
 		CreditsSpent: totalCost,
 		PurchasedAt:  time.Now(),
 	}
-
-	err = app.ShopRepo.CreatePurchase(purchase)
+	limits := datastore.PurchaseLimits{MaxPerUser: limit, Cooldown: purchaseCooldown(itemMetadata)}
+	user.Credits, err = app.ShopRepo.PurchaseItem(purchase, !autoApply, limits)
 	if err != nil {
-		// Non-critical error, log but don't fail
-		fmt.Printf("Warning: Failed to record purchase: %v\n", err)
+		if errors.Is(err, datastore.ErrPurchaseLimitReached) {
+			app.badRequest(w, r, fmt.Errorf("you can own at most %d of this item", limit))
+			return
+		}
+		var cooldownErr datastore.PurchaseCooldownError
+		if errors.As(err, &cooldownErr) {
+			w.Header().Set("Retry-After", strconv.Itoa(cooldownErr.RetryAfterSeconds()))
+			app.tooManyRequests(w, r, cooldownErr)
+			return
+		}
+		// Another purchase may have spent the credits or stock since the checks above, or the item is
+		// outside its availability window
+		if errors.Is(err, datastore.ErrInsufficientCredits) || errors.Is(err, datastore.ErrInsufficientStock) ||
+			errors.Is(err, datastore.ErrItemUnavailable) {
+			app.badRequest(w, r, err)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	// Auto-apply items take effect now; if that fails the purchase is refunded
+	var appliedEffect map[string]any
+	if autoApply {
+		effect, _ := lookupItemEffect(itemMetadata)
+		appliedEffect, err = effect.apply(app, user.UserID, itemMetadata, purchaseReq.Quantity)
+		if err != nil {
+			if _, refundErr := app.ShopRepo.RefundPurchase(purchase.PurchaseID, true); refundErr != nil {
+				log.Printf("Failed to refund purchase %s after its effect failed: %v", purchase.PurchaseID, refundErr)
+			}
+			app.internalServerError(w, r, err)
+			return
+		}
+	}
+
+	// The item was loaded before the purchase took its stock, so reload it to show what is left
+	if updated, err := app.ShopRepo.GetItem(item.ItemID); err == nil {
+		item = updated
+	} else if item.StockQuantity != nil {
+		log.Printf("Failed to reload item %s after purchase %s: %v", item.ItemID, purchase.PurchaseID, err)
+		left := *item.StockQuantity - purchaseReq.Quantity
+		item.StockQuantity = &left
 	}
 
 	// Build response
 	response := map[string]interface{}{
 		"message":          "Purchase successful",
-		"item":             item,
+		"item":             publicShopItem(item),
 		"quantity":         purchaseReq.Quantity,
 		"creditsSpent":     totalCost,
 		"creditsRemaining": user.Credits,
 	}
+	if appliedEffect != nil {
+		response["appliedEffect"] = appliedEffect
+	}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(response)
+	app.writeJSON(w, http.StatusOK, response)
 }
 
 // ============= INVENTORY =============
@@ -211,8 +428,7 @@ func (app *Application) getUserInventory(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(inventory)
+	app.writeList(w, "", publicInventory(inventory), len(inventory))
 }
 
 // GET /v1/inventory/equipped - Get user's equipped items
@@ -235,8 +451,7 @@ func (app *Application) getEquippedItems(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(equippedItems)
+	app.writeList(w, "", publicInventory(equippedItems), len(equippedItems))
 }
 
 // PUT /v1/inventory/equip - Equip/unequip an item
@@ -294,8 +509,105 @@ func (app *Application) equipItem(w http.ResponseWriter, r *http.Request) {
 		"equipped":    equipReq.Equip,
 	}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(response)
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// POST /v1/inventory/unequip-all - Unequip all of the user's items
+func (app *Application) unequipAllItems(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		app.requirePostMethod(w, r, ErrPOST)
+		return
+	}
+
+	// Get current user from token
+	user, err := app.getUserFromToken(w, r)
+	if err != nil {
+		return
+	}
+
+	// Scoped to the caller's rows, so no per-item ownership check is needed
+	count, err := app.ShopRepo.UnequipAll(user.UserID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	response := map[string]interface{}{
+		"message":    "All items unequipped",
+		"unequipped": count,
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
+}
+
+// GET /v1/inventory/{id}/preview - Describe what using an inventory item would do, without using it
+func (app *Application) previewItem(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	user, err := app.getUserFromToken(w, r)
+	if err != nil {
+		return
+	}
+
+	inventoryID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		app.badRequest(w, r, errors.New("inventory ID must be an integer"))
+		return
+	}
+
+	// Scoped to the caller, so someone else's item is indistinguishable from a missing one
+	inventoryItem, err := app.ShopRepo.GetInventoryItem(inventoryID)
+	if _, ok := err.(datastore.NoRowsError); ok || (err == nil && inventoryItem.UserID != user.UserID) {
+		http.Error(w, "Inventory item not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	shopItem, err := app.ShopRepo.GetItem(inventoryItem.ItemID)
+	if err != nil {
+		app.internalServerError(w, r, fmt.Errorf("failed to load item %s: %v", inventoryItem.ItemID, err))
+		return
+	}
+
+	effectMetadata, err := parseItemMetadata(shopItem.Metadata)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	publicItem := publicShopItem(shopItem)
+	preview := models.ItemPreview{
+		InventoryID: inventoryID,
+		Item:        &publicItem,
+	}
+
+	effect, hasEffect := lookupItemEffect(effectMetadata)
+	switch {
+	case inventoryItem.Quantity <= 0:
+		preview.Description = "None of this item left to use"
+	case inventoryItem.ExpiresAt != nil && inventoryItem.ExpiresAt.Before(time.Now()):
+		preview.Description = "This item has expired"
+	case !hasEffect:
+		preview.Usable = true
+		preview.Description = "Using this item has no effect beyond using up one"
+	default:
+		description, projected, err := effect.preview(app, user.UserID, effectMetadata)
+		if err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+		preview.Usable = true
+		preview.Description = description
+		preview.Projected = projected
+	}
+
+	app.writeJSON(w, http.StatusOK, preview)
 }
 
 // POST /v1/inventory/use - Use a consumable item
@@ -353,13 +665,10 @@ func (app *Application) useItem(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var effectMetadata map[string]any
-	if len(shopItem.Metadata) > 0 {
-		effectMetadata = map[string]any{}
-		if err := json.Unmarshal(shopItem.Metadata, &effectMetadata); err != nil {
-			app.internalServerError(w, r, fmt.Errorf("failed to parse item metadata: %v", err))
-			return
-		}
+	effectMetadata, err := parseItemMetadata(shopItem.Metadata)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
 	}
 
 	// Use the item
@@ -376,12 +685,13 @@ func (app *Application) useItem(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	publicItem := publicShopItem(shopItem)
 	response := models.UseItemResponse{
 		Message:       "Item used successfully",
 		InventoryID:   useReq.InventoryID,
 		QuantityLeft:  updatedItem.Quantity,
 		UsedCount:     updatedItem.UsedCount,
-		Item:          &shopItem,
+		Item:          &publicItem,
 		InventoryItem: &updatedItem,
 	}
 
@@ -389,50 +699,24 @@ func (app *Application) useItem(w http.ResponseWriter, r *http.Request) {
 	if len(effectMetadata) > 0 {
 		response.EffectMetadata = effectMetadata
 
-		if effectType, _ := effectMetadata["effect_type"].(string); effectType == "extra_attempt" {
-			extraAttempts := 1
-			if raw, ok := effectMetadata["extra_attempts"]; ok {
-				switch v := raw.(type) {
-				case float64:
-					if attemptInt := int(v); attemptInt > 0 {
-						extraAttempts = attemptInt
-					}
-				case int:
-					if v > 0 {
-						extraAttempts = v
-					}
-				case string:
-					if parsed, err := strconv.Atoi(v); err == nil && parsed > 0 {
-						extraAttempts = parsed
-					}
-				}
-			}
-
-			now := time.Now()
-			normalizedDate := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
-			modifier, err := app.DailyScoreRepo.SetDailyAttemptModifier(user.UserID, normalizedDate, extraAttempts)
+		if effect, ok := lookupItemEffect(effectMetadata); ok {
+			applied, err := effect.apply(app, user.UserID, effectMetadata, 1)
 			if err != nil {
-				app.internalServerError(w, r, fmt.Errorf("failed to apply extra attempts: %v", err))
+				app.internalServerError(w, r, err)
 				return
 			}
-
-			if response.EffectMetadata == nil {
-				response.EffectMetadata = map[string]any{}
+			for key, value := range applied {
+				response.EffectMetadata[key] = value
 			}
-
-			response.EffectMetadata["extra_attempts_applied"] = extraAttempts
-			response.EffectMetadata["total_extra_attempts"] = modifier.ExtraAttempts
-			response.EffectMetadata["max_attempts"] = 5 + modifier.ExtraAttempts
 		}
 	}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(response)
+	app.writeJSON(w, http.StatusOK, response)
 }
 
 // ============= PURCHASE HISTORY =============
 
-// GET /v1/shop/purchases - Get user's purchase history
+// GET /v1/shop/purchases - Get user's purchase history, paged by cursor when limit or cursor is given
 func (app *Application) getPurchaseHistory(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -445,15 +729,86 @@ func (app *Application) getPurchaseHistory(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	// Get purchase history
-	purchases, err := app.ShopRepo.GetUserPurchaseHistory(user.UserID)
+	limit, after, paged, err := parsePageParams(r)
+	if err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+
+	// Clients that don't page still get their whole history
+	if !paged {
+		purchases, err := app.ShopRepo.GetUserPurchaseHistory(user.UserID, nil, 0)
+		if err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+		app.writeList(w, "", publicPurchases(purchases), len(purchases))
+		return
+	}
+
+	purchases, err := app.ShopRepo.GetUserPurchaseHistory(user.UserID, after, limit+1)
 	if err != nil {
 		app.internalServerError(w, r, err)
 		return
 	}
+	total, err := app.ShopRepo.CountUserPurchases(user.UserID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	writePage(app, w, publicPurchases(purchases), limit, total, func(p models.PurchaseRecordWithItem) models.PageCursor {
+		return models.PageCursor{At: p.PurchasedAt, ID: p.PurchaseID}
+	})
+}
+
+// GET /v1/shop/purchases/summary - Get the user's total spending, broken down by item type
+func (app *Application) getSpendingSummary(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	user, err := app.getUserFromToken(w, r)
+	if err != nil {
+		return
+	}
+
+	summary, err := app.ShopRepo.GetUserSpendingSummary(user.UserID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.writeJSON(w, http.StatusOK, summary)
+}
+
+// GET /v1/shop/purchases/{id} - Get one of the user's purchase receipts
+func (app *Application) getPurchase(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(purchases)
+	// Get current user from token
+	user, err := app.getUserFromToken(w, r)
+	if err != nil {
+		return
+	}
+
+	// Scoped to the caller, so someone else's purchase is indistinguishable from a missing one
+	purchase, err := app.ShopRepo.GetPurchase(r.PathValue("id"), user.UserID)
+	if err != nil {
+		if _, ok := err.(datastore.NoRowsError); ok {
+			http.Error(w, "Purchase not found", http.StatusNotFound)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	purchase.ShopItem = publicShopItem(purchase.ShopItem)
+	app.writeJSON(w, http.StatusOK, purchase)
 }
 
 // ============= ADMIN ENDPOINTS =============
@@ -472,14 +827,17 @@ func (app *Application) createShopItem(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Validate required fields
-	if createReq.Name == "" || createReq.ItemType == "" {
-		app.badRequest(w, r, errors.New("name and itemType are required"))
-		return
-	}
-
-	if createReq.CreditCost < 0 {
-		app.badRequest(w, r, errors.New("creditCost must be non-negative"))
+	// Validate every field before rejecting, so all problems are reported together
+	var v validator
+	v.check(createReq.Name != "", "name", "name is required")
+	v.check(createReq.ItemType != "", "itemType", "itemType is required")
+	v.check(createReq.CreditCost >= 0, "creditCost", "creditCost must be non-negative")
+	v.check(createReq.Rarity == "" || models.IsValidRarity(createReq.Rarity), "rarity", "rarity must be one of "+strings.Join(models.Rarities, ", "))
+	v.check(createReq.StockQuantity == nil || *createReq.StockQuantity >= 0, "stockQuantity", "stockQuantity must be non-negative; omit it for unlimited stock")
+	v.checkErr("metadata", app.validateItemMetadata(createReq.Metadata, createReq.CreditCost))
+	v.check(validAvailabilityWindow(createReq.AvailableFrom, createReq.AvailableUntil), "availableUntil", "availableUntil must be after availableFrom")
+	if !v.valid() {
+		app.validationFailed(w, r, &v)
 		return
 	}
 
@@ -493,8 +851,23 @@ func (app *Application) createShopItem(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(createdItem)
+	app.writeJSON(w, http.StatusCreated, createdItem)
+}
+
+// validAvailabilityWindow reports whether a window's bounds are in order; a missing bound is open
+func validAvailabilityWindow(from, until *time.Time) bool {
+	return from == nil || until == nil || until.After(*from)
+}
+
+// updatedTime returns what a nullable time becomes after an optional update
+func updatedTime(current *time.Time, update models.Optional[time.Time]) *time.Time {
+	if !update.Set {
+		return current
+	}
+	if update.Null {
+		return nil
+	}
+	return &update.Value
 }
 
 // GET /v1/admin/shop/items - Get all shop items including inactive (Admin only)
@@ -510,8 +883,7 @@ func (app *Application) getAllShopItems(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(items)
+	app.writeList(w, "", items, len(items))
 }
 
 // PUT /v1/admin/shop/items - Update a shop item (Admin only)
@@ -534,6 +906,57 @@ func (app *Application) updateShopItem(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if updateReq.StockQuantity.Set && !updateReq.StockQuantity.Null && updateReq.StockQuantity.Value < 0 {
+		app.badRequest(w, r, errors.New("stockQuantity must be non-negative"))
+		return
+	}
+
+	if updateReq.Rarity != nil && !models.IsValidRarity(*updateReq.Rarity) {
+		app.badRequest(w, r, errors.New("rarity must be one of "+strings.Join(models.Rarities, ", ")))
+		return
+	}
+
+	// Re-validate effect metadata against the resulting price, and the resulting availability window,
+	// when any of them changes
+	priceChanged := len(updateReq.Metadata) > 0 || updateReq.CreditCost != nil
+	windowChanged := updateReq.AvailableFrom.Set || updateReq.AvailableUntil.Set
+	if priceChanged || windowChanged {
+		existingItem, err := app.ShopRepo.GetItem(itemID)
+		if err != nil {
+			if _, ok := err.(datastore.NoRowsError); ok {
+				http.Error(w, "Item not found", http.StatusNotFound)
+				return
+			}
+			app.internalServerError(w, r, err)
+			return
+		}
+
+		if priceChanged {
+			metadata := existingItem.Metadata
+			if len(updateReq.Metadata) > 0 {
+				metadata = updateReq.Metadata
+			}
+			creditCost := existingItem.CreditCost
+			if updateReq.CreditCost != nil {
+				creditCost = *updateReq.CreditCost
+			}
+
+			if err := app.validateItemMetadata(metadata, creditCost); err != nil {
+				app.badRequest(w, r, err)
+				return
+			}
+		}
+
+		if windowChanged {
+			from := updatedTime(existingItem.AvailableFrom, updateReq.AvailableFrom)
+			until := updatedTime(existingItem.AvailableUntil, updateReq.AvailableUntil)
+			if !validAvailabilityWindow(from, until) {
+				app.badRequest(w, r, errors.New("availableUntil must be after availableFrom"))
+				return
+			}
+		}
+	}
+
 	// Update the item
 	updatedItem, err := app.ShopRepo.UpdateItem(itemID, updateReq)
 	if err != nil {
@@ -541,8 +964,7 @@ func (app *Application) updateShopItem(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(updatedItem)
+	app.writeJSON(w, http.StatusOK, updatedItem)
 }
 
 // DELETE /v1/admin/shop/items - Deactivate a shop item (Admin only)
@@ -558,6 +980,33 @@ func (app *Application) deactivateShopItem(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	// Optionally give recent purchasers of a limited item some or all of their credits back
+	refundPercent := 0
+	if raw := r.URL.Query().Get("refundPercent"); raw != "" {
+		percent, err := strconv.Atoi(raw)
+		if err != nil || percent < 1 || percent > 100 {
+			app.badRequest(w, r, errors.New("refundPercent must be a whole number between 1 and 100"))
+			return
+		}
+		refundPercent = percent
+	}
+
+	if refundPercent > 0 {
+		item, err := app.ShopRepo.GetItem(itemID)
+		if err != nil {
+			if _, ok := err.(datastore.NoRowsError); ok {
+				http.Error(w, "Item not found", http.StatusNotFound)
+				return
+			}
+			app.internalServerError(w, r, err)
+			return
+		}
+		if !item.IsLimitedEdition {
+			app.badRequest(w, r, errors.New("refunds on deactivation are only offered for limited edition items"))
+			return
+		}
+	}
+
 	err := app.ShopRepo.DeactivateItem(itemID)
 	if err != nil {
 		app.internalServerError(w, r, err)
@@ -569,8 +1018,28 @@ func (app *Application) deactivateShopItem(w http.ResponseWriter, r *http.Reques
 		"itemId":  itemID,
 	}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(response)
+	if refundPercent > 0 {
+		windowDays := app.Config.DeactivationRefundWindowDays
+		since := time.Now().AddDate(0, 0, -windowDays)
+
+		usersRefunded, creditsReturned, err := app.ShopRepo.CompensatePurchasers(itemID, since, refundPercent)
+		if err != nil {
+			app.internalServerError(w, r, fmt.Errorf("item deactivated but refunds stopped after %d users: %v", usersRefunded, err))
+			return
+		}
+
+		log.Printf("Returned %d credits to %d purchasers of deactivated item %s (%d%%, last %d days)",
+			creditsReturned, usersRefunded, itemID, refundPercent, windowDays)
+
+		response["refunds"] = models.CompensationResult{
+			RefundPercent:   refundPercent,
+			WindowDays:      windowDays,
+			UsersRefunded:   usersRefunded,
+			CreditsReturned: creditsReturned,
+		}
+	}
+
+	app.writeJSON(w, http.StatusOK, response)
 }
 
 // POST /v1/admin/users/credits - Add credits to a user (Admin only)
@@ -627,8 +1096,7 @@ func (app *Application) addUserCredits(w http.ResponseWriter, r *http.Request) {
 		"totalCredits": updatedUser.Credits,
 	}
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(response)
+	app.writeJSON(w, http.StatusOK, response)
 }
 
 // GET /v1/admin/shop/purchases - Get all purchases or by item (Admin only)
@@ -647,8 +1115,7 @@ func (app *Application) getAdminPurchases(w http.ResponseWriter, r *http.Request
 			app.internalServerError(w, r, err)
 			return
 		}
-		w.WriteHeader(http.StatusOK)
-		json.NewEncoder(w).Encode(purchases)
+		app.writeList(w, "", purchases, len(purchases))
 		return
 	}
 
@@ -657,6 +1124,49 @@ func (app *Application) getAdminPurchases(w http.ResponseWriter, r *http.Request
 	app.badRequest(w, r, errors.New("itemId parameter is required"))
 }
 
+// POST /v1/admin/shop/refund - Refund a purchase (Admin only)
+func (app *Application) refundPurchase(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		app.requirePostMethod(w, r, ErrPOST)
+		return
+	}
+
+	admin, err := app.getUserFromToken(w, r)
+	if err != nil {
+		return
+	}
+
+	var req models.RefundPurchaseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		app.badJSONRequest(w, r, err)
+		return
+	}
+
+	if req.PurchaseID == "" {
+		app.badRequest(w, r, errors.New("purchaseId is required"))
+		return
+	}
+
+	result, err := app.ShopRepo.RefundPurchase(req.PurchaseID, req.Force)
+	if err != nil {
+		if _, ok := err.(datastore.NoRowsError); ok {
+			http.Error(w, "Purchase not found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, datastore.ErrPurchaseAlreadyRefunded) || errors.Is(err, datastore.ErrPurchaseItemConsumed) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	log.Printf("Admin %s refunded purchase %s for user %s (%d credits, forced: %t)",
+		admin.UserID, req.PurchaseID, result.Purchase.UserID, result.CreditsRefunded, req.Force)
+
+	app.writeJSON(w, http.StatusOK, result)
+}
+
 // Helper function to parse inventory ID from query params
 func parseInventoryID(r *http.Request) (int, error) {
 	idStr := r.URL.Query().Get("id")
@@ -0,0 +1,159 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/color-game/api/datastore"
+	"github.com/color-game/api/models"
+)
+
+// fakeShopRepo serves shop reads from an in-memory list; unimplemented methods panic through the
+// nil embedded interface
+type fakeShopRepo struct {
+	datastore.ShopRepository
+	items []models.ShopItem
+}
+
+func (f *fakeShopRepo) GetActiveItemsByIDs(itemIDs []string) ([]models.ShopItem, error) {
+	var items []models.ShopItem
+	for _, item := range f.items {
+		if item.IsActive && slices.Contains(itemIDs, item.ItemID) {
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+func TestGroupItemsByRarity(t *testing.T) {
+	items := []models.ShopItem{
+		{ItemID: "1", Rarity: models.RarityCommon},
+		{ItemID: "2", Rarity: models.RarityLegendary},
+		{ItemID: "3", Rarity: "mythic"},
+		{ItemID: "4", Rarity: ""},
+		{ItemID: "5", Rarity: models.RarityRare},
+		{ItemID: "6", Rarity: models.RarityCommon},
+	}
+
+	tiers := groupItemsByRarity(items)
+	if len(tiers) != len(models.Rarities) {
+		t.Fatalf("got %d tiers, want %d", len(tiers), len(models.Rarities))
+	}
+
+	want := []struct {
+		rarity string
+		ids    []string
+		share  float64
+	}{
+		{models.RarityCommon, []string{"1", "3", "4", "6"}, 66.67},
+		{models.RarityRare, []string{"5"}, 16.67},
+		{models.RarityEpic, nil, 0},
+		{models.RarityLegendary, []string{"2"}, 16.67},
+	}
+	for i, w := range want {
+		tier := tiers[i]
+		if tier.Rarity != w.rarity {
+			t.Errorf("tier %d rarity = %q, want %q", i, tier.Rarity, w.rarity)
+			continue
+		}
+		if tier.Count != len(w.ids) || len(tier.Items) != len(w.ids) {
+			t.Errorf("%s tier count = %d with %d items, want %d", w.rarity, tier.Count, len(tier.Items), len(w.ids))
+			continue
+		}
+		for j, id := range w.ids {
+			if tier.Items[j].ItemID != id {
+				t.Errorf("%s tier item %d = %q, want %q", w.rarity, j, tier.Items[j].ItemID, id)
+			}
+		}
+		if tier.Share != w.share {
+			t.Errorf("%s tier share = %v, want %v", w.rarity, tier.Share, w.share)
+		}
+	}
+}
+
+func TestGroupItemsByRarityEmpty(t *testing.T) {
+	tiers := groupItemsByRarity(nil)
+	if len(tiers) != len(models.Rarities) {
+		t.Fatalf("got %d tiers, want %d", len(tiers), len(models.Rarities))
+	}
+	for i, tier := range tiers {
+		if tier.Rarity != models.Rarities[i] {
+			t.Errorf("tier %d rarity = %q, want %q", i, tier.Rarity, models.Rarities[i])
+		}
+		if tier.Items == nil || tier.Count != 0 || tier.Share != 0 {
+			t.Errorf("%s tier = %+v, want empty non-nil items with zero count and share", tier.Rarity, tier)
+		}
+	}
+}
+
+func TestGetShopItemsBatch(t *testing.T) {
+	app := &Application{ShopRepo: &fakeShopRepo{items: []models.ShopItem{
+		{ItemID: "hat", Rarity: models.RarityCommon, IsActive: true},
+		{ItemID: "crown", Rarity: models.RarityLegendary, IsActive: true},
+		{ItemID: "retired", Rarity: models.RarityRare, IsActive: false},
+	}}}
+
+	body := `{"itemIds": ["hat", "ghost", "retired", "crown", "ghost"]}`
+	rec := httptest.NewRecorder()
+	app.getShopItemsBatch(rec, httptest.NewRequest(http.MethodPost, "/v1/shop/items/batch", strings.NewReader(body)))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	var got struct {
+		Items    []models.ShopItem `json:"items"`
+		NotFound []string          `json:"notFound"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("invalid response body: %v", err)
+	}
+
+	var ids []string
+	for _, item := range got.Items {
+		ids = append(ids, item.ItemID)
+	}
+	if want := []string{"hat", "crown"}; !slices.Equal(ids, want) {
+		t.Errorf("items = %v, want %v", ids, want)
+	}
+	// Inactive items are reported like unknown ones, and duplicates only once
+	if want := []string{"ghost", "retired"}; !slices.Equal(got.NotFound, want) {
+		t.Errorf("notFound = %v, want %v", got.NotFound, want)
+	}
+}
+
+func TestPublicShopItem(t *testing.T) {
+	stock := func(n int) *int { return &n }
+
+	tests := []struct {
+		name      string
+		item      models.ShopItem
+		wantStock *int
+	}{
+		{"limited edition keeps its stock", models.ShopItem{IsLimitedEdition: true, StockQuantity: stock(12)}, stock(12)},
+		{"sold out limited edition shows 0", models.ShopItem{IsLimitedEdition: true, StockQuantity: stock(0)}, stock(0)},
+		{"limited edition without stock", models.ShopItem{IsLimitedEdition: true}, nil},
+		{"regular item hides its stock", models.ShopItem{StockQuantity: stock(40)}, nil},
+		{"unlimited regular item", models.ShopItem{}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := publicShopItem(tt.item).StockQuantity
+			if (got == nil) != (tt.wantStock == nil) || (got != nil && *got != *tt.wantStock) {
+				t.Errorf("StockQuantity = %v, want %v", got, tt.wantStock)
+			}
+
+			raw, err := json.Marshal(publicShopItem(tt.item))
+			if err != nil {
+				t.Fatalf("failed to marshal item: %v", err)
+			}
+			if shown := strings.Contains(string(raw), `"stockQuantity"`); shown != (tt.wantStock != nil) {
+				t.Errorf("stockQuantity in JSON = %v, want %v: %s", shown, tt.wantStock != nil, raw)
+			}
+		})
+	}
+}
@@ -0,0 +1,228 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/color-game/api/datastore"
+	"github.com/color-game/api/models"
+)
+
+// normalizeTradeLeg defaults an item leg to a quantity of 1 and validates it
+func normalizeTradeLeg(itemID *string, quantity *int, credits int) error {
+	if credits < 0 {
+		return errors.New("credits cannot be negative")
+	}
+	if itemID == nil || strings.TrimSpace(*itemID) == "" {
+		if *quantity != 0 {
+			return errors.New("quantity requires an item")
+		}
+		if credits == 0 {
+			return errors.New("each side of a trade must include an item or credits")
+		}
+		return nil
+	}
+	if *quantity == 0 {
+		*quantity = 1
+	}
+	if *quantity < 0 {
+		return errors.New("quantity must be greater than 0")
+	}
+	return nil
+}
+
+// POST /v1/trades/propose - Propose a trade to another player
+func (app *Application) proposeTrade(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		app.requirePostMethod(w, r, ErrPOST)
+		return
+	}
+
+	// Get current user from token
+	user, err := app.getUserFromToken(w, r)
+	if err != nil {
+		return
+	}
+
+	var tradeReq models.ProposeTradeRequest
+	if err := json.NewDecoder(r.Body).Decode(&tradeReq); err != nil {
+		app.badJSONRequest(w, r, err)
+		return
+	}
+
+	if tradeReq.RecipientID == "" {
+		app.badRequest(w, r, errors.New("recipientId is required"))
+		return
+	}
+	if tradeReq.RecipientID == user.UserID {
+		app.badRequest(w, r, errors.New("cannot trade with yourself"))
+		return
+	}
+	if err := normalizeTradeLeg(tradeReq.OfferedItemID, &tradeReq.OfferedQuantity, tradeReq.OfferedCredits); err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+	if err := normalizeTradeLeg(tradeReq.RequestedItemID, &tradeReq.RequestedQuantity, tradeReq.RequestedCredits); err != nil {
+		app.badRequest(w, r, err)
+		return
+	}
+
+	// Ensure recipient exists
+	if _, err := app.UserRepo.Get(tradeReq.RecipientID); err != nil {
+		app.badRequest(w, r, errors.New("user not found"))
+		return
+	}
+
+	// Proposer must own what they offer now; this is checked again on acceptance
+	if tradeReq.OfferedCredits > user.Credits {
+		app.badRequest(w, r, errors.New("insufficient credits for this offer"))
+		return
+	}
+	if tradeReq.OfferedItemID != nil {
+		owned, err := app.ShopRepo.GetUserInventoryItem(user.UserID, *tradeReq.OfferedItemID)
+		if err != nil {
+			if _, ok := err.(datastore.NoRowsError); ok {
+				app.badRequest(w, r, errors.New("you do not own the offered item"))
+				return
+			}
+			app.internalServerError(w, r, err)
+			return
+		}
+		if owned.Quantity < tradeReq.OfferedQuantity {
+			app.badRequest(w, r, errors.New("you do not have enough of the offered item"))
+			return
+		}
+	}
+	if tradeReq.RequestedItemID != nil {
+		if _, err := app.ShopRepo.GetItem(*tradeReq.RequestedItemID); err != nil {
+			if _, ok := err.(datastore.NoRowsError); ok {
+				app.badRequest(w, r, errors.New("requested item not found"))
+				return
+			}
+			app.internalServerError(w, r, err)
+			return
+		}
+	}
+
+	trade, err := app.TradeRepo.CreateTrade(models.Trade{
+		ProposerID:        user.UserID,
+		RecipientID:       tradeReq.RecipientID,
+		OfferedItemID:     tradeReq.OfferedItemID,
+		OfferedQuantity:   tradeReq.OfferedQuantity,
+		OfferedCredits:    tradeReq.OfferedCredits,
+		RequestedItemID:   tradeReq.RequestedItemID,
+		RequestedQuantity: tradeReq.RequestedQuantity,
+		RequestedCredits:  tradeReq.RequestedCredits,
+	})
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.writeJSON(w, http.StatusCreated, trade)
+}
+
+// GET /v1/trades - Get the user's trades, optionally filtered by ?status=
+func (app *Application) getTrades(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Get current user from token
+	user, err := app.getUserFromToken(w, r)
+	if err != nil {
+		return
+	}
+
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "", models.TradeStatusPending, models.TradeStatusAccepted, models.TradeStatusDeclined, models.TradeStatusCancelled:
+	default:
+		app.badRequest(w, r, errors.New("invalid status filter"))
+		return
+	}
+
+	trades, err := app.TradeRepo.ListTrades(user.UserID, status)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	app.writeList(w, "trades", trades, len(trades))
+}
+
+// POST /v1/trades/respond - Accept or decline a received trade, or cancel a proposed one
+func (app *Application) respondToTrade(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		app.requirePostMethod(w, r, ErrPOST)
+		return
+	}
+
+	// Get current user from token
+	user, err := app.getUserFromToken(w, r)
+	if err != nil {
+		return
+	}
+
+	var respondReq models.RespondTradeRequest
+	if err := json.NewDecoder(r.Body).Decode(&respondReq); err != nil {
+		app.badJSONRequest(w, r, err)
+		return
+	}
+
+	if respondReq.TradeID == 0 || respondReq.Action == "" {
+		app.badRequest(w, r, errors.New("tradeId and action are required"))
+		return
+	}
+
+	trade, err := app.TradeRepo.GetTrade(respondReq.TradeID)
+	if err != nil {
+		if _, ok := err.(datastore.NoRowsError); ok {
+			http.Error(w, "Trade not found", http.StatusNotFound)
+			return
+		}
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	// Only the recipient may accept or decline; only the proposer may cancel
+	action := strings.ToLower(respondReq.Action)
+	switch action {
+	case "accept", "decline":
+		if trade.RecipientID != user.UserID {
+			http.Error(w, "Unauthorized", http.StatusForbidden)
+			return
+		}
+	case "cancel":
+		if trade.ProposerID != user.UserID {
+			http.Error(w, "Unauthorized", http.StatusForbidden)
+			return
+		}
+	default:
+		app.badRequest(w, r, errors.New("invalid action"))
+		return
+	}
+
+	switch action {
+	case "accept":
+		trade, err = app.TradeRepo.AcceptTrade(trade.TradeID)
+	case "decline":
+		trade, err = app.TradeRepo.UpdateTradeStatus(trade.TradeID, models.TradeStatusDeclined)
+	case "cancel":
+		trade, err = app.TradeRepo.UpdateTradeStatus(trade.TradeID, models.TradeStatusCancelled)
+	}
+	if err != nil {
+		switch err {
+		case datastore.ErrTradeNotPending, datastore.ErrTradeAssetsUnavailable:
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	app.writeJSON(w, http.StatusOK, trade)
+}
@@ -0,0 +1,65 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/color-game/api/models"
+)
+
+// FieldError is one problem with one field of a request body
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError is returned with a 422 and lists every field that failed validation
+type ValidationError struct {
+	HandlerError
+	Fields []FieldError `json:"fields"`
+}
+
+// validator collects field errors so a request can be rejected with all of its problems at once
+type validator struct {
+	fields []FieldError
+}
+
+// check records message against field unless ok holds
+func (v *validator) check(ok bool, field string, message string) {
+	if !ok {
+		v.fields = append(v.fields, FieldError{Field: field, Message: message})
+	}
+}
+
+// checkErr records err against field when it isn't nil
+func (v *validator) checkErr(field string, err error) {
+	if err != nil {
+		v.fields = append(v.fields, FieldError{Field: field, Message: err.Error()})
+	}
+}
+
+func (v *validator) valid() bool {
+	return len(v.fields) == 0
+}
+
+// validationFailed reports every field error the validator collected
+func (app *Application) validationFailed(w http.ResponseWriter, r *http.Request, v *validator) {
+	validationErr := ValidationError{
+		HandlerError: HandlerError{
+			ErrorName:        "Validation Failed",
+			Description:      fmt.Sprintf("%d field(s) failed validation", len(v.fields)),
+			PossibleSolution: "Fix every field listed in fields and retry",
+			CallerInfo:       getCallerInfo(),
+		},
+		Fields: v.fields,
+	}
+	app.writeJSON(w, http.StatusUnprocessableEntity, validationErr)
+}
+
+// checkSubmittedColor requires each channel of a submitted color to be a valid RGB value
+func checkSubmittedColor(v *validator, submission models.ScoreSubmissionRequest) {
+	const message = "must be between 0 and 255"
+	v.check(submission.SubmittedColorR >= 0 && submission.SubmittedColorR <= 255, "submitted_color_r", message)
+	v.check(submission.SubmittedColorG >= 0 && submission.SubmittedColorG <= 255, "submitted_color_g", message)
+	v.check(submission.SubmittedColorB >= 0 && submission.SubmittedColorB <= 255, "submitted_color_b", message)
+}
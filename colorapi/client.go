@@ -0,0 +1,169 @@
+package colorapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/color-game/api/models"
+)
+
+// DefaultBaseURL is the public thecolorapi.com endpoint
+const DefaultBaseURL = "https://www.thecolorapi.com"
+
+// Modes lists the scheme modes accepted by thecolorapi.com
+var Modes = []string{
+	"monochrome",
+	"monochrome-dark",
+	"monochrome-light",
+	"analogic",
+	"complement",
+	"analogic-complement",
+	"triad",
+	"quad",
+}
+
+// Service is the color API as used by the scheduler and handlers. *Client implements it;
+// a fake can stand in where no external calls should be made.
+type Service interface {
+	GetScheme(r, g, b int) (models.ColorAPIResponse, error)
+	GetSchemeWithMode(r, g, b int, mode string) (models.ColorAPIResponse, error)
+	GetRandomScheme() (models.ColorAPIResponse, error)
+	ModeForDate(date time.Time) string
+}
+
+// ExternalServiceError reports that the color API could not be reached or gave an unusable answer.
+// StatusCode is the upstream HTTP status, or 0 when no response arrived at all.
+type ExternalServiceError struct {
+	StatusCode int
+	Err        error
+}
+
+func (e *ExternalServiceError) Error() string {
+	if e.StatusCode != 0 && e.StatusCode != http.StatusOK {
+		return fmt.Sprintf("color API returned status: %d", e.StatusCode)
+	}
+	return fmt.Sprintf("color API request failed: %v", e.Err)
+}
+
+func (e *ExternalServiceError) Unwrap() error {
+	return e.Err
+}
+
+// Unavailable reports whether the failure looks temporary (no response, rate limiting or an
+// upstream outage) rather than a bad answer from a working service
+func (e *ExternalServiceError) Unavailable() bool {
+	return e.StatusCode == 0 || e.StatusCode == http.StatusTooManyRequests || e.StatusCode == http.StatusServiceUnavailable
+}
+
+// Client builds and sends requests to the external color API
+type Client struct {
+	BaseURL    string
+	Mode       string
+	Count      int
+	HTTPClient *http.Client
+	// Rotation, when set, picks each day's scheme mode by day of week (Sunday first) instead of Mode
+	Rotation []string
+}
+
+// NewClient validates the scheme settings and returns a Client
+func NewClient(baseURL, mode string, count int) (*Client, error) {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	if _, err := url.ParseRequestURI(baseURL); err != nil {
+		return nil, fmt.Errorf("invalid color API base URL %q: %v", baseURL, err)
+	}
+	if !IsValidMode(mode) {
+		return nil, fmt.Errorf("invalid color scheme mode %q, expected one of: %s", mode, strings.Join(Modes, ", "))
+	}
+	if count <= 0 {
+		return nil, fmt.Errorf("color scheme count must be positive, got %d", count)
+	}
+
+	return &Client{
+		BaseURL:    strings.TrimRight(baseURL, "/"),
+		Mode:       mode,
+		Count:      count,
+		HTTPClient: &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+// IsValidMode reports whether mode is a scheme mode the color API accepts
+func IsValidMode(mode string) bool {
+	for _, m := range Modes {
+		if m == mode {
+			return true
+		}
+	}
+	return false
+}
+
+// SetRotation validates and sets the scheme modes cycled through by ModeForDate.
+// An empty rotation always uses Mode.
+func (c *Client) SetRotation(modes []string) error {
+	for _, mode := range modes {
+		if !IsValidMode(mode) {
+			return fmt.Errorf("invalid color scheme mode %q in rotation, expected one of: %s", mode, strings.Join(Modes, ", "))
+		}
+	}
+	c.Rotation = modes
+	return nil
+}
+
+// ModeForDate returns the scheme mode for a day's color. The same date always gets the same mode.
+func (c *Client) ModeForDate(date time.Time) string {
+	if len(c.Rotation) == 0 {
+		return c.Mode
+	}
+	return c.Rotation[int(date.Weekday())%len(c.Rotation)]
+}
+
+// SchemeURL builds the scheme request URL seeded with the given RGB values
+func (c *Client) SchemeURL(r, g, b int) string {
+	return c.schemeURL(r, g, b, c.Mode)
+}
+
+func (c *Client) schemeURL(r, g, b int, mode string) string {
+	return fmt.Sprintf("%s/scheme?rgb=%d,%d,%d&mode=%s&count=%d&format=json",
+		c.BaseURL, r, g, b, url.QueryEscape(mode), c.Count)
+}
+
+// GetScheme fetches the color scheme seeded with the given RGB values
+func (c *Client) GetScheme(r, g, b int) (models.ColorAPIResponse, error) {
+	return c.GetSchemeWithMode(r, g, b, c.Mode)
+}
+
+// GetSchemeWithMode fetches the color scheme seeded with the given RGB values using a specific mode
+func (c *Client) GetSchemeWithMode(r, g, b int, mode string) (models.ColorAPIResponse, error) {
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	resp, err := httpClient.Get(c.schemeURL(r, g, b, mode))
+	if err != nil {
+		return models.ColorAPIResponse{}, &ExternalServiceError{Err: err}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return models.ColorAPIResponse{}, &ExternalServiceError{StatusCode: resp.StatusCode}
+	}
+
+	var colorResponse models.ColorAPIResponse
+	if err := json.NewDecoder(resp.Body).Decode(&colorResponse); err != nil {
+		return models.ColorAPIResponse{}, &ExternalServiceError{StatusCode: resp.StatusCode, Err: err}
+	}
+
+	return colorResponse, nil
+}
+
+// GetRandomScheme fetches a color scheme seeded with a random color
+func (c *Client) GetRandomScheme() (models.ColorAPIResponse, error) {
+	return c.GetScheme(rand.Intn(256), rand.Intn(256), rand.Intn(256))
+}
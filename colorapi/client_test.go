@@ -0,0 +1,129 @@
+package colorapi
+
+import (
+	"fmt"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestModeForDate(t *testing.T) {
+	// 2026-10-11 is a Sunday, so weekday indexes run 0..6 from there
+	sunday := time.Date(2026, 10, 11, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		rotation []string
+		date     time.Time
+		want     string
+	}{
+		{"no rotation uses the default mode", nil, sunday, "monochrome"},
+		{"sunday takes the first mode", []string{"triad", "quad"}, sunday, "triad"},
+		{"monday takes the second mode", []string{"triad", "quad"}, sunday.AddDate(0, 0, 1), "quad"},
+		{"the rotation wraps around", []string{"triad", "quad"}, sunday.AddDate(0, 0, 2), "triad"},
+		{"a full-week rotation gives each weekday its own mode", []string{"monochrome", "analogic", "complement", "triad", "quad", "analogic-complement", "monochrome-dark"}, sunday.AddDate(0, 0, 6), "monochrome-dark"},
+		{"the time of day doesn't matter", []string{"triad", "quad"}, sunday.Add(23 * time.Hour), "triad"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewClient(DefaultBaseURL, "monochrome", 5)
+			if err != nil {
+				t.Fatalf("NewClient: %v", err)
+			}
+			if err := client.SetRotation(tt.rotation); err != nil {
+				t.Fatalf("SetRotation: %v", err)
+			}
+
+			if got := client.ModeForDate(tt.date); got != tt.want {
+				t.Errorf("ModeForDate(%s) = %q, want %q", tt.date.Format("Mon 2006-01-02"), got, tt.want)
+			}
+			// The same date must always get the same mode
+			if again := client.ModeForDate(tt.date); again != tt.want {
+				t.Errorf("ModeForDate(%s) changed between calls: %q then %q", tt.date.Format("2006-01-02"), tt.want, again)
+			}
+		})
+	}
+}
+
+func TestSetRotationRejectsUnknownModes(t *testing.T) {
+	client, err := NewClient(DefaultBaseURL, "monochrome", 5)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	if err := client.SetRotation([]string{"triad", "sparkly"}); err == nil {
+		t.Fatal("SetRotation accepted an unknown mode")
+	}
+	if len(client.Rotation) != 0 {
+		t.Errorf("a rejected rotation was still applied: %v", client.Rotation)
+	}
+}
+
+func TestSchemeURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		baseURL  string
+		mode     string
+		count    int
+		rgb      [3]int
+		wantBase string
+		wantRGB  string
+	}{
+		{"default API", "", "analogic", 6, [3]int{255, 128, 0}, "https://www.thecolorapi.com/scheme", "255,128,0"},
+		{"mirror with a trailing slash", "http://localhost:9000/", "monochrome-dark", 3, [3]int{0, 0, 0}, "http://localhost:9000/scheme", "0,0,0"},
+		{"mirror under a path", "https://colors.example/api/v1", "quad", 10, [3]int{12, 7, 200}, "https://colors.example/api/v1/scheme", "12,7,200"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewClient(tt.baseURL, tt.mode, tt.count)
+			if err != nil {
+				t.Fatalf("NewClient: %v", err)
+			}
+
+			raw := client.SchemeURL(tt.rgb[0], tt.rgb[1], tt.rgb[2])
+			parsed, err := url.Parse(raw)
+			if err != nil {
+				t.Fatalf("SchemeURL returned an invalid URL %q: %v", raw, err)
+			}
+			if base := parsed.Scheme + "://" + parsed.Host + parsed.Path; base != tt.wantBase {
+				t.Errorf("endpoint = %q, want %q", base, tt.wantBase)
+			}
+
+			query := parsed.Query()
+			want := map[string]string{
+				"rgb":    tt.wantRGB,
+				"mode":   tt.mode,
+				"count":  fmt.Sprint(tt.count),
+				"format": "json",
+			}
+			for key, value := range want {
+				if got := query.Get(key); got != value {
+					t.Errorf("%s = %q, want %q in %s", key, got, value, raw)
+				}
+			}
+			if len(query) != len(want) {
+				t.Errorf("query has %d parameters, want %d: %s", len(query), len(want), raw)
+			}
+		})
+	}
+}
+
+func TestSchemeURLEscapesMode(t *testing.T) {
+	client, err := NewClient(DefaultBaseURL, "analogic", 6)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+
+	// Modes are validated, but a stray value must not be able to add query parameters
+	parsed, err := url.Parse(client.schemeURL(1, 2, 3, "analogic&count=99"))
+	if err != nil {
+		t.Fatalf("invalid URL: %v", err)
+	}
+	if got := parsed.Query().Get("mode"); got != "analogic&count=99" {
+		t.Errorf("mode = %q, want the raw value", got)
+	}
+	if got := parsed.Query()["count"]; len(got) != 1 || got[0] != "6" {
+		t.Errorf("count = %v, want only the configured 6", got)
+	}
+}
@@ -0,0 +1,39 @@
+package datastore
+
+import (
+	"fmt"
+
+	"github.com/color-game/api/models"
+)
+
+// AuditLogRepository records sensitive admin actions
+type AuditLogRepository interface {
+	RecordAuditEntry(entry models.AuditEntry) (models.AuditEntry, error)
+}
+
+type AuditLogDatabase struct {
+	database Querier
+}
+
+func NewAuditLogDatabase(db Querier) (AuditLogDatabase, error) {
+	return AuditLogDatabase{database: db}, nil
+}
+
+// RecordAuditEntry appends an entry to the admin audit log
+func (ald AuditLogDatabase) RecordAuditEntry(entry models.AuditEntry) (models.AuditEntry, error) {
+	var details any
+	if len(entry.Details) > 0 {
+		details = []byte(entry.Details)
+	}
+
+	err := ald.database.QueryRow(`
+		INSERT INTO admin_audit_log (admin_id, action, target_user_id, details)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at`,
+		entry.AdminID, entry.Action, entry.TargetUserID, details,
+	).Scan(&entry.ID, &entry.CreatedAt)
+	if err != nil {
+		return models.AuditEntry{}, fmt.Errorf("failed to record audit entry: %v", err)
+	}
+	return entry, nil
+}
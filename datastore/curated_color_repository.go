@@ -0,0 +1,141 @@
+package datastore
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/color-game/api/models"
+	"github.com/lib/pq"
+)
+
+// ErrCuratedDateTaken is returned when a curated color is already scheduled for the requested date
+var ErrCuratedDateTaken = errors.New("a curated color is already scheduled for that date")
+
+// CuratedColorRepository manages the queue of hand-picked daily colors
+type CuratedColorRepository interface {
+	EnqueueCuratedColor(color models.CuratedColor) (models.CuratedColor, error)
+	ListPendingCuratedColors() ([]models.CuratedColor, error)
+	CreateDailyColorFromCurated(date time.Time, schemeMode string, fromQueue bool) (models.DailyColor, bool, error)
+}
+
+type CuratedColorDatabase struct {
+	database Querier
+}
+
+func NewCuratedColorDatabase(db Querier) (CuratedColorDatabase, error) {
+	return CuratedColorDatabase{database: db}, nil
+}
+
+const curatedColorColumns = `id, color_name, r, g, b, scheduled_for, used_on, created_by, created_at`
+
+// EnqueueCuratedColor adds a color to the curated queue
+func (ccd CuratedColorDatabase) EnqueueCuratedColor(color models.CuratedColor) (models.CuratedColor, error) {
+	sqlStatement := `
+		INSERT INTO curated_colors (color_name, r, g, b, scheduled_for, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING ` + curatedColorColumns
+
+	created, err := scanCuratedColor(ccd.database.QueryRow(sqlStatement,
+		color.ColorName, color.R, color.G, color.B, color.ScheduledFor, color.CreatedBy))
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+		return models.CuratedColor{}, ErrCuratedDateTaken
+	}
+	if err != nil {
+		return models.CuratedColor{}, fmt.Errorf("failed to enqueue curated color: %v", err)
+	}
+	return created, nil
+}
+
+// ListPendingCuratedColors retrieves the unused curated colors, dated entries first by date, then the
+// queue in the order it will be used
+func (ccd CuratedColorDatabase) ListPendingCuratedColors() ([]models.CuratedColor, error) {
+	rows, err := ccd.database.Query(`
+		SELECT ` + curatedColorColumns + `
+		FROM curated_colors
+		WHERE used_on IS NULL
+		ORDER BY scheduled_for NULLS LAST, id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list curated colors: %v", err)
+	}
+	defer rows.Close()
+
+	colors := []models.CuratedColor{}
+	for rows.Next() {
+		color, err := scanCuratedColor(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan curated color: %v", err)
+		}
+		colors = append(colors, color)
+	}
+
+	return colors, rows.Err()
+}
+
+// CreateDailyColorFromCurated makes the curated color scheduled for date into that day's color. When
+// nothing is scheduled for it and fromQueue is set, the oldest unscheduled entry is used instead.
+// The entry is marked used in the same transaction, so it is only spent if the daily color is saved.
+// The bool is false when no curated color applied.
+func (ccd CuratedColorDatabase) CreateDailyColorFromCurated(date time.Time, schemeMode string, fromQueue bool) (models.DailyColor, bool, error) {
+	normalizedDate := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+
+	var dailyColor models.DailyColor
+	found := false
+	err := inTx(ccd.database, func(tx *sql.Tx) error {
+		curated, err := scanCuratedColor(tx.QueryRow(`
+			SELECT `+curatedColorColumns+`
+			FROM curated_colors
+			WHERE used_on IS NULL AND (scheduled_for = $1 OR ($2 AND scheduled_for IS NULL))
+			ORDER BY scheduled_for IS NULL, id
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED`, normalizedDate, fromQueue))
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get curated color: %v", err)
+		}
+
+		if _, err := tx.Exec(`UPDATE curated_colors SET used_on = $2 WHERE id = $1`, curated.ID, normalizedDate); err != nil {
+			return fmt.Errorf("failed to mark curated color used: %v", err)
+		}
+
+		dailyColor, err = DailyColorDatabase{database: tx}.Create(models.DailyColor{
+			Date:       normalizedDate,
+			ColorName:  curated.ColorName,
+			R:          curated.R,
+			G:          curated.G,
+			B:          curated.B,
+			Source:     models.DailyColorSourceCurated,
+			SchemeMode: schemeMode,
+			Difficulty: models.ClassifyColorDifficulty(curated.R, curated.G, curated.B),
+			CreatedAt:  time.Now(),
+		})
+		if err != nil {
+			return err
+		}
+		found = true
+		return nil
+	})
+	if err != nil {
+		return models.DailyColor{}, false, err
+	}
+	return dailyColor, found, nil
+}
+
+func scanCuratedColor(row rowScanner) (models.CuratedColor, error) {
+	var color models.CuratedColor
+	err := row.Scan(
+		&color.ID,
+		&color.ColorName,
+		&color.R,
+		&color.G,
+		&color.B,
+		&color.ScheduledFor,
+		&color.UsedOn,
+		&color.CreatedBy,
+		&color.CreatedAt,
+	)
+	return color, err
+}
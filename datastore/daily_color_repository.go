@@ -13,15 +13,15 @@ type DailyColorRepository interface {
 	Create(dailyColor models.DailyColor) (models.DailyColor, error)
 	GetByDate(date time.Time) (models.DailyColor, error)
 	GetToday() (models.DailyColor, error)
-	GetAll() ([]models.DailyColor, error)
+	GetAll(from, to time.Time, limit, offset int) (colors []models.DailyColor, total int, err error)
 	Delete(id int) error
 }
 
 type DailyColorDatabase struct {
-	database *sql.DB
+	database Querier
 }
 
-func NewDailyColorDatabase(db *sql.DB) (DailyColorDatabase, error) {
+func NewDailyColorDatabase(db Querier) (DailyColorDatabase, error) {
 	var dailyColorDB DailyColorDatabase
 	dailyColorDB.database = db
 	return dailyColorDB, nil
@@ -32,8 +32,8 @@ func (dcdb DailyColorDatabase) Create(dailyColor models.DailyColor) (models.Dail
 	db := dcdb.database
 
 	sqlStatement := `
-		INSERT INTO daily_color (date, color_name, r, g, b, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO daily_color (date, color_name, r, g, b, source, scheme_mode, difficulty, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 		RETURNING id`
 
 	err := db.QueryRow(
@@ -43,6 +43,9 @@ func (dcdb DailyColorDatabase) Create(dailyColor models.DailyColor) (models.Dail
 		dailyColor.R,
 		dailyColor.G,
 		dailyColor.B,
+		dailyColor.Source,
+		dailyColor.SchemeMode,
+		dailyColor.Difficulty,
 		dailyColor.CreatedAt,
 	).Scan(&dailyColor.ID)
 
@@ -61,7 +64,7 @@ func (dcdb DailyColorDatabase) GetByDate(date time.Time) (models.DailyColor, err
 	normalizedDate := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
 
 	sqlStatement := `
-		SELECT id, date, color_name, r, g, b, created_at
+		SELECT id, date, color_name, r, g, b, source, scheme_mode, difficulty, created_at
 		FROM daily_color
 		WHERE date = $1`
 
@@ -75,6 +78,9 @@ func (dcdb DailyColorDatabase) GetByDate(date time.Time) (models.DailyColor, err
 		&dailyColor.R,
 		&dailyColor.G,
 		&dailyColor.B,
+		&dailyColor.Source,
+		&dailyColor.SchemeMode,
+		&dailyColor.Difficulty,
 		&dailyColor.CreatedAt,
 	)
 
@@ -94,22 +100,31 @@ func (dcdb DailyColorDatabase) GetToday() (models.DailyColor, error) {
 	return dcdb.GetByDate(today)
 }
 
-// GetAll retrieves all daily colors
-func (dcdb DailyColorDatabase) GetAll() ([]models.DailyColor, error) {
+// GetAll retrieves one page of the daily colors dated from..to inclusive, newest first,
+// along with how many colors the whole range holds
+func (dcdb DailyColorDatabase) GetAll(from, to time.Time, limit, offset int) ([]models.DailyColor, int, error) {
 	db := dcdb.database
 
+	var total int
+	err := db.QueryRow(`SELECT COUNT(*) FROM daily_color WHERE date BETWEEN $1 AND $2`, from, to).Scan(&total)
+	if err != nil {
+		return []models.DailyColor{}, 0, fmt.Errorf("failed to count daily colors: %v", err)
+	}
+
 	sqlStatement := `
-		SELECT id, date, color_name, r, g, b, created_at
+		SELECT id, date, color_name, r, g, b, source, scheme_mode, difficulty, created_at
 		FROM daily_color
-		ORDER BY date DESC`
+		WHERE date BETWEEN $1 AND $2
+		ORDER BY date DESC
+		LIMIT $3 OFFSET $4`
 
-	rows, err := db.Query(sqlStatement)
+	rows, err := db.Query(sqlStatement, from, to, limit, offset)
 	if err != nil {
-		return []models.DailyColor{}, err
+		return []models.DailyColor{}, 0, err
 	}
 	defer rows.Close()
 
-	var dailyColors []models.DailyColor
+	dailyColors := []models.DailyColor{}
 	for rows.Next() {
 		var dc models.DailyColor
 		err := rows.Scan(
@@ -119,19 +134,22 @@ func (dcdb DailyColorDatabase) GetAll() ([]models.DailyColor, error) {
 			&dc.R,
 			&dc.G,
 			&dc.B,
+			&dc.Source,
+			&dc.SchemeMode,
+			&dc.Difficulty,
 			&dc.CreatedAt,
 		)
 		if err != nil {
-			return []models.DailyColor{}, err
+			return []models.DailyColor{}, 0, err
 		}
 		dailyColors = append(dailyColors, dc)
 	}
 
 	if err = rows.Err(); err != nil {
-		return []models.DailyColor{}, err
+		return []models.DailyColor{}, 0, err
 	}
 
-	return dailyColors, nil
+	return dailyColors, total, nil
 }
 
 // Delete removes a daily color by ID
@@ -12,16 +12,24 @@ import (
 type DailyLeaderboardRepository interface {
 	CreateOrUpdate(entry models.DailyLeaderboard) (models.DailyLeaderboard, error)
 	GetByUserAndDate(userID string, date time.Time) (models.DailyLeaderboard, error)
+	GetUserHistory(userID string) ([]models.DailyLeaderboard, error)
 	GetLeaderboardByDate(date time.Time, limit int) ([]models.LeaderboardEntry, error)
+	CountPlayersByDate(date time.Time, final bool) (int, error)
 	GetUserRankByDate(userID string, date time.Time) (int, error)
+	GetUserPersonalBest(userID string) (models.PersonalBest, error)
 	DeleteByUserAndDate(userID string, date time.Time) (int64, error)
+	GetScoreDistribution(date time.Time, bucketSize int) ([]models.ScoreBucket, error)
+	FinalizeDay(date time.Time) (int, bool, error)
+	GetFinalLeaderboardByDate(date time.Time, limit int) ([]models.LeaderboardEntry, bool, error)
+	GetHeadToHead(userID, opponentID string, from, to time.Time) (models.HeadToHead, error)
+	GetGlobalStats() (models.GlobalStats, error)
 }
 
 type DailyLeaderboardDatabase struct {
-	database *sql.DB
+	database Querier
 }
 
-func NewDailyLeaderboardDatabase(db *sql.DB) (DailyLeaderboardDatabase, error) {
+func NewDailyLeaderboardDatabase(db Querier) (DailyLeaderboardDatabase, error) {
 	var dailyLeaderboardDB DailyLeaderboardDatabase
 	dailyLeaderboardDB.database = db
 	return dailyLeaderboardDB, nil
@@ -114,6 +122,39 @@ func (dldb DailyLeaderboardDatabase) GetByUserAndDate(userID string, date time.T
 	}
 }
 
+// GetUserHistory retrieves every day a user has a leaderboard entry for, newest first
+func (dldb DailyLeaderboardDatabase) GetUserHistory(userID string) ([]models.DailyLeaderboard, error) {
+	sqlStatement := `
+		SELECT id, user_id, date, best_score, attempts_used, created_at, updated_at
+		FROM daily_leaderboard
+		WHERE user_id = $1
+		ORDER BY date DESC`
+
+	rows, err := dldb.database.Query(sqlStatement, userID)
+	if err != nil {
+		return []models.DailyLeaderboard{}, fmt.Errorf("failed to get leaderboard history: %v", err)
+	}
+	defer rows.Close()
+
+	entries := []models.DailyLeaderboard{}
+	for rows.Next() {
+		var entry models.DailyLeaderboard
+		if err := rows.Scan(
+			&entry.ID,
+			&entry.UserID,
+			&entry.Date,
+			&entry.BestScore,
+			&entry.AttemptsUsed,
+			&entry.CreatedAt,
+			&entry.UpdatedAt,
+		); err != nil {
+			return []models.DailyLeaderboard{}, fmt.Errorf("failed to scan leaderboard entry: %v", err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
 // GetLeaderboardByDate retrieves the leaderboard for a specific date with rank
 func (dldb DailyLeaderboardDatabase) GetLeaderboardByDate(date time.Time, limit int) ([]models.LeaderboardEntry, error) {
 	db := dldb.database
@@ -126,6 +167,7 @@ func (dldb DailyLeaderboardDatabase) GetLeaderboardByDate(date time.Time, limit
 			ROW_NUMBER() OVER (ORDER BY dl.best_score DESC, dl.attempts_used ASC, dl.created_at ASC) as rank,
 			dl.user_id,
 			u.username,
+			u.avatar_url,
 			dl.best_score,
 			dl.attempts_used
 		FROM daily_leaderboard dl
@@ -140,13 +182,14 @@ func (dldb DailyLeaderboardDatabase) GetLeaderboardByDate(date time.Time, limit
 	}
 	defer rows.Close()
 
-	var entries []models.LeaderboardEntry
+	entries := []models.LeaderboardEntry{}
 	for rows.Next() {
 		var entry models.LeaderboardEntry
 		err := rows.Scan(
 			&entry.Rank,
 			&entry.UserID,
 			&entry.Username,
+			&entry.AvatarURL,
 			&entry.BestScore,
 			&entry.AttemptsUsed,
 		)
@@ -159,6 +202,23 @@ func (dldb DailyLeaderboardDatabase) GetLeaderboardByDate(date time.Time, limit
 	return entries, rows.Err()
 }
 
+// CountPlayersByDate returns how many players are ranked on a date. With final set, it returns the count
+// frozen with the day's snapshot, so it stays consistent with the snapshot's ranks.
+func (dldb DailyLeaderboardDatabase) CountPlayersByDate(date time.Time, final bool) (int, error) {
+	normalizedDate := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+
+	query := `SELECT COUNT(*) FROM daily_leaderboard WHERE date = $1`
+	if final {
+		query = `SELECT COALESCE((SELECT entry_count FROM daily_leaderboard_finals WHERE date = $1), 0)`
+	}
+
+	var count int
+	if err := dldb.database.QueryRow(query, normalizedDate).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count leaderboard players: %v", err)
+	}
+	return count, nil
+}
+
 // GetUserRankByDate retrieves a user's rank for a specific date
 func (dldb DailyLeaderboardDatabase) GetUserRankByDate(userID string, date time.Time) (int, error) {
 	db := dldb.database
@@ -190,3 +250,272 @@ func (dldb DailyLeaderboardDatabase) GetUserRankByDate(userID string, date time.
 		return 0, err
 	}
 }
+
+// GetUserPersonalBest finds the user's highest daily best score across all days and its rank on
+// that day. Ties between days go to the better rank, then the most recent day.
+func (dldb DailyLeaderboardDatabase) GetUserPersonalBest(userID string) (models.PersonalBest, error) {
+	db := dldb.database
+
+	// Only the days the user played need ranking
+	sqlStatement := `
+		WITH ranked_leaderboard AS (
+			SELECT
+				user_id, date, best_score, attempts_used,
+				ROW_NUMBER() OVER (PARTITION BY date ORDER BY best_score DESC, attempts_used ASC, created_at ASC) as rank,
+				COUNT(*) OVER (PARTITION BY date) as total_players
+			FROM daily_leaderboard
+			WHERE date IN (SELECT date FROM daily_leaderboard WHERE user_id = $1)
+		)
+		SELECT date, best_score, attempts_used, rank, total_players
+		FROM ranked_leaderboard
+		WHERE user_id = $1
+		ORDER BY best_score DESC, rank ASC, date DESC
+		LIMIT 1`
+
+	var best models.PersonalBest
+	var date time.Time
+	err := db.QueryRow(sqlStatement, userID).Scan(&date, &best.BestScore, &best.AttemptsUsed, &best.Rank, &best.TotalPlayers)
+
+	switch err {
+	case sql.ErrNoRows:
+		return models.PersonalBest{}, NoRowsError{true, err}
+	case nil:
+		best.Date = date.Format("2006-01-02")
+		return best, nil
+	default:
+		return models.PersonalBest{}, fmt.Errorf("failed to get personal best: %v", err)
+	}
+}
+
+// GetScoreDistribution counts best scores for a date in buckets of bucketSize points. A perfect 100
+// is folded into the top bucket. Only non-empty buckets are returned.
+func (dldb DailyLeaderboardDatabase) GetScoreDistribution(date time.Time, bucketSize int) ([]models.ScoreBucket, error) {
+	db := dldb.database
+
+	// Normalize date to start of day
+	normalizedDate := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+
+	sqlStatement := `
+		SELECT LEAST(best_score, 99) / $2 * $2 AS bucket_start, COUNT(*)
+		FROM daily_leaderboard
+		WHERE date = $1
+		GROUP BY bucket_start
+		ORDER BY bucket_start`
+
+	rows, err := db.Query(sqlStatement, normalizedDate, bucketSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get score distribution: %v", err)
+	}
+	defer rows.Close()
+
+	buckets := []models.ScoreBucket{}
+	for rows.Next() {
+		var bucket models.ScoreBucket
+		if err := rows.Scan(&bucket.MinScore, &bucket.Players); err != nil {
+			return nil, fmt.Errorf("failed to scan score bucket: %v", err)
+		}
+		bucket.MaxScore = bucket.MinScore + bucketSize - 1
+		buckets = append(buckets, bucket)
+	}
+
+	return buckets, rows.Err()
+}
+
+// FinalizeDay freezes a day's leaderboard into daily_leaderboard_snapshots, returning how many entries
+// were frozen. Days are only ever finalized once; a repeat call returns false and leaves the
+// existing snapshot as it is.
+func (dldb DailyLeaderboardDatabase) FinalizeDay(date time.Time) (int, bool, error) {
+	normalizedDate := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+
+	var frozen int
+	var finalized bool
+	err := inTx(dldb.database, func(tx *sql.Tx) error {
+		// Claim the day first so concurrent runs can't both snapshot it
+		result, err := tx.Exec(`
+			INSERT INTO daily_leaderboard_finals (date, entry_count)
+			VALUES ($1, 0)
+			ON CONFLICT (date) DO NOTHING`, normalizedDate)
+		if err != nil {
+			return fmt.Errorf("failed to mark leaderboard final: %v", err)
+		}
+		claimed, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to check rows affected: %v", err)
+		}
+		if claimed == 0 {
+			return nil
+		}
+
+		result, err = tx.Exec(`
+			INSERT INTO daily_leaderboard_snapshots (date, rank, user_id, best_score, attempts_used)
+			SELECT
+				date,
+				ROW_NUMBER() OVER (ORDER BY best_score DESC, attempts_used ASC, created_at ASC),
+				user_id,
+				best_score,
+				attempts_used
+			FROM daily_leaderboard
+			WHERE date = $1`, normalizedDate)
+		if err != nil {
+			return fmt.Errorf("failed to snapshot leaderboard: %v", err)
+		}
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to check rows affected: %v", err)
+		}
+
+		if _, err := tx.Exec(`UPDATE daily_leaderboard_finals SET entry_count = $2 WHERE date = $1`, normalizedDate, rows); err != nil {
+			return fmt.Errorf("failed to record snapshot size: %v", err)
+		}
+		frozen, finalized = int(rows), true
+		return nil
+	})
+	if err != nil {
+		return 0, false, err
+	}
+	return frozen, finalized, nil
+}
+
+// GetFinalLeaderboardByDate retrieves a finalized day's frozen standings. The bool is false when the
+// day hasn't been finalized, in which case no entries are returned.
+func (dldb DailyLeaderboardDatabase) GetFinalLeaderboardByDate(date time.Time, limit int) ([]models.LeaderboardEntry, bool, error) {
+	db := dldb.database
+
+	normalizedDate := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+
+	var final bool
+	err := db.QueryRow(`SELECT EXISTS (SELECT 1 FROM daily_leaderboard_finals WHERE date = $1)`, normalizedDate).Scan(&final)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to check leaderboard finalization: %v", err)
+	}
+	if !final {
+		return nil, false, nil
+	}
+
+	sqlStatement := `
+		SELECT s.rank, s.user_id, u.username, u.avatar_url, s.best_score, s.attempts_used
+		FROM daily_leaderboard_snapshots s
+		JOIN users u ON s.user_id = u.user_id
+		WHERE s.date = $1
+		ORDER BY s.rank
+		LIMIT $2`
+
+	rows, err := db.Query(sqlStatement, normalizedDate, limit)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get final leaderboard: %v", err)
+	}
+	defer rows.Close()
+
+	entries := []models.LeaderboardEntry{}
+	for rows.Next() {
+		var entry models.LeaderboardEntry
+		err := rows.Scan(
+			&entry.Rank,
+			&entry.UserID,
+			&entry.Username,
+			&entry.AvatarURL,
+			&entry.BestScore,
+			&entry.AttemptsUsed,
+		)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to scan final leaderboard entry: %v", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, true, rows.Err()
+}
+
+// GetHeadToHead compares two users' best scores day by day between from and to (inclusive)
+func (dldb DailyLeaderboardDatabase) GetHeadToHead(userID, opponentID string, from, to time.Time) (models.HeadToHead, error) {
+	db := dldb.database
+
+	normalizedFrom := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, from.Location())
+	normalizedTo := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, to.Location())
+
+	// Days only one of them played join against NULL, which every comparison filter skips
+	sqlStatement := `
+		SELECT
+			COUNT(*) FILTER (WHERE u.best_score > o.best_score),
+			COUNT(*) FILTER (WHERE o.best_score > u.best_score),
+			COUNT(*) FILTER (WHERE u.best_score = o.best_score),
+			COUNT(u.best_score),
+			COUNT(o.best_score),
+			COALESCE(AVG(u.best_score), 0),
+			COALESCE(AVG(o.best_score), 0)
+		FROM (
+			SELECT date, best_score FROM daily_leaderboard
+			WHERE user_id = $1 AND date BETWEEN $3 AND $4
+		) u
+		FULL OUTER JOIN (
+			SELECT date, best_score FROM daily_leaderboard
+			WHERE user_id = $2 AND date BETWEEN $3 AND $4
+		) o ON u.date = o.date`
+
+	record := models.HeadToHead{
+		From:     normalizedFrom.Format("2006-01-02"),
+		To:       normalizedTo.Format("2006-01-02"),
+		User:     models.HeadToHeadSide{UserID: userID},
+		Opponent: models.HeadToHeadSide{UserID: opponentID},
+	}
+	err := db.QueryRow(sqlStatement, userID, opponentID, normalizedFrom, normalizedTo).Scan(
+		&record.User.Wins,
+		&record.Opponent.Wins,
+		&record.Ties,
+		&record.User.DaysPlayed,
+		&record.Opponent.DaysPlayed,
+		&record.User.AverageScore,
+		&record.Opponent.AverageScore,
+	)
+	if err != nil {
+		return models.HeadToHead{}, fmt.Errorf("failed to get head-to-head record: %v", err)
+	}
+
+	record.DaysCompared = record.User.Wins + record.Opponent.Wins + record.Ties
+	return record, nil
+}
+
+// GetGlobalStats computes platform-wide totals. Games come from daily_leaderboard, which keeps every
+// day played even after raw attempts are archived. Its attempts_used is the number of the user's best
+// attempt rather than how many they made, so attempts are counted from the raw daily_scores plus the
+// true per-day totals of days already rolled up into daily_score_summaries.
+func (dldb DailyLeaderboardDatabase) GetGlobalStats() (models.GlobalStats, error) {
+	db := dldb.database
+
+	sqlStatement := `
+		SELECT
+			(SELECT COUNT(*) FROM users),
+			dl.games,
+			(SELECT COUNT(*) FROM daily_scores) +
+				(SELECT COALESCE(SUM(attempts_used), 0)::bigint FROM daily_score_summaries),
+			dl.highest,
+			COALESCE(mc.color_name, ''),
+			COALESCE(mc.days, 0)
+		FROM (
+			SELECT COUNT(*) AS games, COALESCE(MAX(best_score), 0) AS highest
+			FROM daily_leaderboard
+		) dl
+		LEFT JOIN (
+			SELECT color_name, COUNT(*) AS days
+			FROM daily_color
+			GROUP BY color_name
+			ORDER BY days DESC, color_name
+			LIMIT 1
+		) mc ON true`
+
+	var stats models.GlobalStats
+	err := db.QueryRow(sqlStatement).Scan(
+		&stats.TotalUsers,
+		&stats.GamesPlayed,
+		&stats.TotalAttempts,
+		&stats.HighestScore,
+		&stats.MostCommonColor,
+		&stats.MostCommonColorCount,
+	)
+	if err != nil {
+		return models.GlobalStats{}, fmt.Errorf("failed to get global stats: %v", err)
+	}
+
+	stats.ComputedAt = time.Now()
+	return stats, nil
+}
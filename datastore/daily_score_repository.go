@@ -2,29 +2,44 @@ package datastore
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/color-game/api/models"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 )
 
+// ErrMaxAttemptsReached is returned when a user has no attempts left for the day
+var ErrMaxAttemptsReached = errors.New("maximum attempts reached for today")
+
+// attemptInsertRetries bounds how often CreateNextAttempt retries after losing a race for an attempt number
+const attemptInsertRetries = 3
+
 type DailyScoreRepository interface {
 	Create(score models.DailyScore) (models.DailyScore, error)
+	CreateNextAttempt(score models.DailyScore, maxAttempts int) (models.DailyScore, error)
 	GetUserScoresByDate(userID string, date time.Time) ([]models.DailyScore, error)
 	GetUserAttemptCount(userID string, date time.Time) (int, error)
+	GetUserBestScoreForColor(userID string, date time.Time, dailyColorID int) (models.DailyScore, error)
+	GetUserScoreByID(userID string, scoreID int) (models.DailyScore, error)
 	GetAllScoresByDate(date time.Time) ([]models.DailyScore, error)
-	GetUserScoreHistory(userID string) ([]models.DailyScore, error)
+	GetRecentHighScores(date time.Time, minScore int, limit int) ([]models.RecentScore, error)
+	GetUserScoreHistory(userID string, after *models.PageCursor, limit int) ([]models.DailyScore, error)
+	CountUserScores(userID string) (int, error)
+	GetUserScoreSummaries(userID string) ([]models.DailyScoreSummary, error)
 	DeleteUserScoresByDate(userID string, date time.Time) (int64, error)
+	ArchiveScoresBefore(cutoff time.Time) (int64, error)
 	SetDailyAttemptModifier(userID string, date time.Time, extraAttempts int) (models.DailyAttemptModifier, error)
 	GetDailyAttemptModifier(userID string, date time.Time) (models.DailyAttemptModifier, error)
 }
 
 type DailyScoreDatabase struct {
-	database *sql.DB
+	database Querier
 }
 
-func NewDailyScoreDatabase(db *sql.DB) (DailyScoreDatabase, error) {
+func NewDailyScoreDatabase(db Querier) (DailyScoreDatabase, error) {
 	var dailyScoreDB DailyScoreDatabase
 	dailyScoreDB.database = db
 	return dailyScoreDB, nil
@@ -113,23 +128,57 @@ func (dsdb DailyScoreDatabase) DeleteUserScoresByDate(userID string, date time.T
 	return rowsAffected, nil
 }
 
+// ArchiveScoresBefore rolls raw attempts dated before cutoff into daily_score_summaries and deletes them.
+// Deleting and summarising happen in one statement, so only the rows that were summarised are removed.
+// Returns the number of raw attempts archived.
+func (dsdb DailyScoreDatabase) ArchiveScoresBefore(cutoff time.Time) (int64, error) {
+	db := dsdb.database
+
+	normalizedCutoff := time.Date(cutoff.Year(), cutoff.Month(), cutoff.Day(), 0, 0, 0, 0, cutoff.Location())
+
+	var archived int64
+	err := db.QueryRow(`
+		WITH raw AS (
+			DELETE FROM daily_scores
+			WHERE date < $1
+			RETURNING user_id, date, score
+		), summarised AS (
+			INSERT INTO daily_score_summaries (user_id, date, best_score, attempts_used)
+			SELECT user_id, date, MAX(score), COUNT(*)
+			FROM raw
+			GROUP BY user_id, date
+			ON CONFLICT (user_id, date)
+			DO UPDATE SET best_score = GREATEST(daily_score_summaries.best_score, EXCLUDED.best_score),
+				attempts_used = daily_score_summaries.attempts_used + EXCLUDED.attempts_used,
+				archived_at = NOW()
+		)
+		SELECT COUNT(*) FROM raw
+	`, normalizedCutoff).Scan(&archived)
+	if err != nil {
+		return 0, fmt.Errorf("failed to archive daily scores: %v", err)
+	}
+
+	return archived, nil
+}
+
 // Create inserts a new daily score
 func (dsdb DailyScoreDatabase) Create(score models.DailyScore) (models.DailyScore, error) {
 	db := dsdb.database
 
 	sqlStatement := `
 		INSERT INTO daily_scores (
-			user_id, date, attempt_number, score,
+			user_id, daily_color_id, date, attempt_number, score,
 			submitted_color_r, submitted_color_g, submitted_color_b,
 			target_color_r, target_color_g, target_color_b,
 			created_at
 		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 		RETURNING id`
 
 	err := db.QueryRow(
 		sqlStatement,
 		score.UserID,
+		score.DailyColorID,
 		score.Date,
 		score.AttemptNumber,
 		score.Score,
@@ -149,6 +198,59 @@ func (dsdb DailyScoreDatabase) Create(score models.DailyScore) (models.DailyScor
 	return score, nil
 }
 
+// CreateNextAttempt inserts score as the user's next attempt for its date, numbering it and enforcing
+// maxAttempts in the same statement so concurrent submissions can't exceed the cap. Two submissions
+// that pick the same number collide on the (user_id, date, attempt_number) constraint and the loser
+// retries. score.AttemptNumber is ignored.
+func (dsdb DailyScoreDatabase) CreateNextAttempt(score models.DailyScore, maxAttempts int) (models.DailyScore, error) {
+	db := dsdb.database
+
+	sqlStatement := `
+		INSERT INTO daily_scores (
+			user_id, daily_color_id, date, attempt_number, score,
+			submitted_color_r, submitted_color_g, submitted_color_b,
+			target_color_r, target_color_g, target_color_b,
+			created_at
+		)
+		SELECT $1::varchar, $2::int, $3::date, COALESCE(MAX(attempt_number), 0) + 1, $4::int,
+			$5::int, $6::int, $7::int,
+			$8::int, $9::int, $10::int,
+			$11::timestamp
+		FROM daily_scores
+		WHERE user_id = $1 AND date = $3
+		HAVING COUNT(*) < $12
+		RETURNING id, attempt_number`
+
+	for try := 0; ; try++ {
+		err := db.QueryRow(
+			sqlStatement,
+			score.UserID,
+			score.DailyColorID,
+			score.Date,
+			score.Score,
+			score.SubmittedColorR,
+			score.SubmittedColorG,
+			score.SubmittedColorB,
+			score.TargetColorR,
+			score.TargetColorG,
+			score.TargetColorB,
+			score.CreatedAt,
+			maxAttempts,
+		).Scan(&score.ID, &score.AttemptNumber)
+
+		if err == nil {
+			return score, nil
+		}
+		if err == sql.ErrNoRows {
+			return models.DailyScore{}, ErrMaxAttemptsReached
+		}
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" && try < attemptInsertRetries {
+			continue
+		}
+		return models.DailyScore{}, fmt.Errorf("failed to create daily score: %v", err)
+	}
+}
+
 // GetUserScoresByDate retrieves all scores for a user on a specific date
 func (dsdb DailyScoreDatabase) GetUserScoresByDate(userID string, date time.Time) ([]models.DailyScore, error) {
 	db := dsdb.database
@@ -157,7 +259,7 @@ func (dsdb DailyScoreDatabase) GetUserScoresByDate(userID string, date time.Time
 	normalizedDate := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
 
 	sqlStatement := `
-		SELECT id, user_id, date, attempt_number, score,
+		SELECT id, user_id, daily_color_id, date, attempt_number, score,
 			submitted_color_r, submitted_color_g, submitted_color_b,
 			target_color_r, target_color_g, target_color_b,
 			created_at
@@ -171,12 +273,13 @@ func (dsdb DailyScoreDatabase) GetUserScoresByDate(userID string, date time.Time
 	}
 	defer rows.Close()
 
-	var scores []models.DailyScore
+	scores := []models.DailyScore{}
 	for rows.Next() {
 		var score models.DailyScore
 		err := rows.Scan(
 			&score.ID,
 			&score.UserID,
+			&score.DailyColorID,
 			&score.Date,
 			&score.AttemptNumber,
 			&score.Score,
@@ -218,6 +321,89 @@ func (dsdb DailyScoreDatabase) GetUserAttemptCount(userID string, date time.Time
 	return count, nil
 }
 
+// GetUserBestScoreForColor returns the user's best attempt on a date that was scored against the given daily color
+func (dsdb DailyScoreDatabase) GetUserBestScoreForColor(userID string, date time.Time, dailyColorID int) (models.DailyScore, error) {
+	db := dsdb.database
+
+	// Normalize date to start of day
+	normalizedDate := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+
+	sqlStatement := `
+		SELECT id, user_id, daily_color_id, date, attempt_number, score,
+			submitted_color_r, submitted_color_g, submitted_color_b,
+			target_color_r, target_color_g, target_color_b,
+			created_at
+		FROM daily_scores
+		WHERE user_id = $1 AND date = $2 AND daily_color_id = $3
+		ORDER BY score DESC, attempt_number ASC
+		LIMIT 1`
+
+	var score models.DailyScore
+	err := db.QueryRow(sqlStatement, userID, normalizedDate, dailyColorID).Scan(
+		&score.ID,
+		&score.UserID,
+		&score.DailyColorID,
+		&score.Date,
+		&score.AttemptNumber,
+		&score.Score,
+		&score.SubmittedColorR,
+		&score.SubmittedColorG,
+		&score.SubmittedColorB,
+		&score.TargetColorR,
+		&score.TargetColorG,
+		&score.TargetColorB,
+		&score.CreatedAt,
+	)
+
+	switch err {
+	case sql.ErrNoRows:
+		return models.DailyScore{}, NoRowsError{true, err}
+	case nil:
+		return score, nil
+	default:
+		return models.DailyScore{}, err
+	}
+}
+
+// GetUserScoreByID retrieves one of a user's attempts. Another user's attempt is reported as not found.
+func (dsdb DailyScoreDatabase) GetUserScoreByID(userID string, scoreID int) (models.DailyScore, error) {
+	db := dsdb.database
+
+	sqlStatement := `
+		SELECT id, user_id, daily_color_id, date, attempt_number, score,
+			submitted_color_r, submitted_color_g, submitted_color_b,
+			target_color_r, target_color_g, target_color_b,
+			created_at
+		FROM daily_scores
+		WHERE id = $1 AND user_id = $2`
+
+	var score models.DailyScore
+	err := db.QueryRow(sqlStatement, scoreID, userID).Scan(
+		&score.ID,
+		&score.UserID,
+		&score.DailyColorID,
+		&score.Date,
+		&score.AttemptNumber,
+		&score.Score,
+		&score.SubmittedColorR,
+		&score.SubmittedColorG,
+		&score.SubmittedColorB,
+		&score.TargetColorR,
+		&score.TargetColorG,
+		&score.TargetColorB,
+		&score.CreatedAt,
+	)
+
+	switch err {
+	case sql.ErrNoRows:
+		return models.DailyScore{}, NoRowsError{true, err}
+	case nil:
+		return score, nil
+	default:
+		return models.DailyScore{}, err
+	}
+}
+
 // GetAllScoresByDate retrieves all scores for a specific date
 func (dsdb DailyScoreDatabase) GetAllScoresByDate(date time.Time) ([]models.DailyScore, error) {
 	db := dsdb.database
@@ -226,7 +412,7 @@ func (dsdb DailyScoreDatabase) GetAllScoresByDate(date time.Time) ([]models.Dail
 	normalizedDate := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
 
 	sqlStatement := `
-		SELECT id, user_id, date, attempt_number, score,
+		SELECT id, user_id, daily_color_id, date, attempt_number, score,
 			submitted_color_r, submitted_color_g, submitted_color_b,
 			target_color_r, target_color_g, target_color_b,
 			created_at
@@ -240,12 +426,13 @@ func (dsdb DailyScoreDatabase) GetAllScoresByDate(date time.Time) ([]models.Dail
 	}
 	defer rows.Close()
 
-	var scores []models.DailyScore
+	scores := []models.DailyScore{}
 	for rows.Next() {
 		var score models.DailyScore
 		err := rows.Scan(
 			&score.ID,
 			&score.UserID,
+			&score.DailyColorID,
 			&score.Date,
 			&score.AttemptNumber,
 			&score.Score,
@@ -266,31 +453,91 @@ func (dsdb DailyScoreDatabase) GetAllScoresByDate(date time.Time) ([]models.Dail
 	return scores, rows.Err()
 }
 
-// GetUserScoreHistory retrieves all scores for a user across all dates
-func (dsdb DailyScoreDatabase) GetUserScoreHistory(userID string) ([]models.DailyScore, error) {
+// CountUserScores counts a user's attempts across all dates, the total GetUserScoreHistory pages through
+func (dsdb DailyScoreDatabase) CountUserScores(userID string) (int, error) {
+	var count int
+	err := dsdb.database.QueryRow(`SELECT COUNT(*) FROM daily_scores WHERE user_id = $1`, userID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count scores: %v", err)
+	}
+	return count, nil
+}
+
+// GetUserScoreSummaries retrieves every day of a user's archived attempts, newest first
+func (dsdb DailyScoreDatabase) GetUserScoreSummaries(userID string) ([]models.DailyScoreSummary, error) {
+	sqlStatement := `
+		SELECT id, user_id, date, best_score, attempts_used, archived_at
+		FROM daily_score_summaries
+		WHERE user_id = $1
+		ORDER BY date DESC`
+
+	rows, err := dsdb.database.Query(sqlStatement, userID)
+	if err != nil {
+		return []models.DailyScoreSummary{}, fmt.Errorf("failed to get score summaries: %v", err)
+	}
+	defer rows.Close()
+
+	summaries := []models.DailyScoreSummary{}
+	for rows.Next() {
+		var summary models.DailyScoreSummary
+		if err := rows.Scan(
+			&summary.ID,
+			&summary.UserID,
+			&summary.Date,
+			&summary.BestScore,
+			&summary.AttemptsUsed,
+			&summary.ArchivedAt,
+		); err != nil {
+			return []models.DailyScoreSummary{}, fmt.Errorf("failed to scan score summary: %v", err)
+		}
+		summaries = append(summaries, summary)
+	}
+	return summaries, rows.Err()
+}
+
+// GetUserScoreHistory retrieves a user's attempts across all dates newest first, starting after the
+// cursor when one is given. The cursor's ID is the attempt ID. A limit of 0 returns every attempt.
+func (dsdb DailyScoreDatabase) GetUserScoreHistory(userID string, after *models.PageCursor, limit int) ([]models.DailyScore, error) {
 	db := dsdb.database
 
+	var afterTime *time.Time
+	var afterID *int
+	if after != nil {
+		id, err := strconv.Atoi(after.ID)
+		if err != nil {
+			return []models.DailyScore{}, fmt.Errorf("invalid score cursor ID %q", after.ID)
+		}
+		afterTime, afterID = &after.At, &id
+	}
+	var limitArg *int
+	if limit > 0 {
+		limitArg = &limit
+	}
+
 	sqlStatement := `
-		SELECT id, user_id, date, attempt_number, score,
+		SELECT id, user_id, daily_color_id, date, attempt_number, score,
 			submitted_color_r, submitted_color_g, submitted_color_b,
 			target_color_r, target_color_g, target_color_b,
 			created_at
 		FROM daily_scores
 		WHERE user_id = $1
-		ORDER BY date DESC, attempt_number ASC`
+			AND ($2::timestamp IS NULL OR (created_at, id) < ($2, $3))
+		ORDER BY created_at DESC, id DESC
+		LIMIT $4`
 
-	rows, err := db.Query(sqlStatement, userID)
+	rows, err := db.Query(sqlStatement, userID, afterTime, afterID, limitArg)
 	if err != nil {
 		return []models.DailyScore{}, err
 	}
 	defer rows.Close()
 
-	var scores []models.DailyScore
+	scores := []models.DailyScore{}
 	for rows.Next() {
 		var score models.DailyScore
 		err := rows.Scan(
 			&score.ID,
 			&score.UserID,
+			&score.DailyColorID,
 			&score.Date,
 			&score.AttemptNumber,
 			&score.Score,
@@ -310,3 +557,34 @@ func (dsdb DailyScoreDatabase) GetUserScoreHistory(userID string) ([]models.Dail
 
 	return scores, rows.Err()
 }
+
+// GetRecentHighScores returns the newest attempts on date scoring at least minScore, skipping
+// users who have opted out of the public activity feed
+func (dsdb DailyScoreDatabase) GetRecentHighScores(date time.Time, minScore int, limit int) ([]models.RecentScore, error) {
+	normalizedDate := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+
+	sqlStatement := `
+		SELECT u.username, u.avatar_url, ds.score, ds.created_at
+		FROM daily_scores ds
+		JOIN users u ON u.user_id = ds.user_id
+		WHERE ds.date = $1 AND ds.score >= $2 AND NOT u.hide_from_activity_feed
+		ORDER BY ds.created_at DESC, ds.id DESC
+		LIMIT $3`
+
+	rows, err := dsdb.database.Query(sqlStatement, normalizedDate, minScore, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recent high scores: %v", err)
+	}
+	defer rows.Close()
+
+	scores := []models.RecentScore{}
+	for rows.Next() {
+		var score models.RecentScore
+		if err := rows.Scan(&score.Username, &score.AvatarURL, &score.Score, &score.ScoredAt); err != nil {
+			return nil, err
+		}
+		scores = append(scores, score)
+	}
+
+	return scores, rows.Err()
+}
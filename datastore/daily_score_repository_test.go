@@ -0,0 +1,67 @@
+package datastore
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/color-game/api/models"
+)
+
+// createTestColor stores the daily color for date, replacing any existing one as a regeneration does
+func createTestColor(t *testing.T, db *sql.DB, date time.Time, r, g, b int) models.DailyColor {
+	t.Helper()
+	if _, err := db.Exec(`DELETE FROM daily_color WHERE date = $1`, date); err != nil {
+		t.Fatalf("failed to clear daily color: %v", err)
+	}
+	color, err := DailyColorDatabase{database: db}.Create(models.DailyColor{
+		Date: date, ColorName: "test", R: r, G: g, B: b,
+		Source: models.DailyColorSourceDeterministic, SchemeMode: "analogic", Difficulty: "medium", CreatedAt: time.Now(),
+	})
+	if err != nil {
+		t.Fatalf("failed to create daily color: %v", err)
+	}
+	t.Cleanup(func() { db.Exec(`DELETE FROM daily_color WHERE id = $1`, color.ID) })
+	return color
+}
+
+func TestGetUserBestScoreForColorAfterRegeneration(t *testing.T) {
+	db := openTestDB(t)
+	scores := DailyScoreDatabase{database: db}
+	userID := createTestUser(t, db, 0)
+	date := time.Date(2199, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	attempt := func(color models.DailyColor, score int) models.DailyScore {
+		t.Helper()
+		colorID := color.ID
+		saved, err := scores.CreateNextAttempt(models.DailyScore{
+			UserID: userID, DailyColorID: &colorID, Date: date, Score: score,
+			TargetColorR: color.R, TargetColorG: color.G, TargetColorB: color.B, CreatedAt: time.Now(),
+		}, 10)
+		if err != nil {
+			t.Fatalf("CreateNextAttempt error = %v", err)
+		}
+		return saved
+	}
+
+	original := createTestColor(t, db, date, 200, 40, 40)
+	attempt(original, 100)
+
+	regenerated := createTestColor(t, db, date, 20, 20, 220)
+	if _, err := scores.GetUserBestScoreForColor(userID, date, regenerated.ID); err == nil {
+		t.Fatalf("best score on the new color before guessing it should be NoRowsError")
+	}
+
+	best := attempt(regenerated, 60)
+	attempt(regenerated, 40)
+	attempt(regenerated, 60)
+
+	got, err := scores.GetUserBestScoreForColor(userID, date, regenerated.ID)
+	if err != nil {
+		t.Fatalf("GetUserBestScoreForColor error = %v", err)
+	}
+	if got.ID != best.ID || got.Score != 60 {
+		t.Errorf("best = attempt %d scoring %d, want the first 60 (attempt %d), not the 100 on the old color",
+			got.AttemptNumber, got.Score, best.AttemptNumber)
+	}
+}
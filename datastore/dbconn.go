@@ -7,22 +7,31 @@ import (
 	_ "github.com/lib/pq"
 )
 
+// PostgresDBType is the only DB_TYPE the repositories support. They rely on $n placeholders,
+// ON CONFLICT upserts, JSONB and pq.Array, none of which degrade cleanly on other drivers.
+const PostgresDBType = "postgres"
+
 // NewDB takes arguments for db type and conn string and returns a DatabaseConnectionResult
 func NewDB(dbtype string, connstr string) (*sql.DB, error) {
 	db, openError := sql.Open(dbtype, connstr)
+	if openError != nil {
+		return &sql.DB{}, fmt.Errorf("error opening connection -> %v", openError)
+	}
 
 	if pingError := db.Ping(); pingError != nil {
 		return &sql.DB{}, fmt.Errorf("could not establish connection with database -> %v", pingError)
 	}
 
-	if openError != nil {
-		return &sql.DB{}, fmt.Errorf("error opening connection -> %v", openError)
-	}
-
 	return db, nil
 }
 
-// BuildDBConnStr builds a PostgreSQL connection string
-func BuildDBConnStr(password, user, dbname, sslmode string) string {
-	return fmt.Sprintf("postgres://%s:%s@localhost/%s?sslmode=%s", user, password, dbname, sslmode)
+// BuildDBConnStr builds a connection string for the given database driver.
+// Unsupported drivers fail fast rather than erroring on the first query.
+func BuildDBConnStr(dbtype, password, user, dbname, sslmode string) (string, error) {
+	switch dbtype {
+	case PostgresDBType:
+		return fmt.Sprintf("postgres://%s:%s@localhost/%s?sslmode=%s", user, password, dbname, sslmode), nil
+	default:
+		return "", fmt.Errorf("unsupported DB_TYPE %q: only %q is supported; see the README for running Postgres locally with Docker", dbtype, PostgresDBType)
+	}
 }
@@ -0,0 +1,54 @@
+package datastore
+
+import (
+	"database/sql"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/color-game/api/migrations"
+)
+
+// testDatabaseURLEnv names the Postgres database the repository tests run against. The tests create
+// their own rows and remove them afterwards, but should still be pointed at a throwaway database.
+const testDatabaseURLEnv = "TEST_DATABASE_URL"
+
+var (
+	migrateOnce sync.Once
+	migrateErr  error
+)
+
+// openTestDB connects to the test database and brings its schema up to date, skipping the test when
+// no database is configured
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	connStr := os.Getenv(testDatabaseURLEnv)
+	if connStr == "" {
+		t.Skipf("%s is not set", testDatabaseURLEnv)
+	}
+
+	db, err := NewDB(PostgresDBType, connStr)
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	// RunMigrations reads the migrations directory relative to the working directory
+	migrateOnce.Do(func() {
+		wd, err := os.Getwd()
+		if err != nil {
+			migrateErr = err
+			return
+		}
+		if err := os.Chdir(".."); err != nil {
+			migrateErr = err
+			return
+		}
+		defer os.Chdir(wd)
+		migrateErr = migrations.RunMigrations(db)
+	})
+	if migrateErr != nil {
+		t.Fatalf("failed to migrate test database: %v", migrateErr)
+	}
+	return db
+}
@@ -14,18 +14,22 @@ type FriendRepository interface {
 	UpdateFriendshipStatus(friendshipID int, status string) (models.Friendship, error)
 	GetFriendshipBetween(userID, otherUserID string) (models.Friendship, error)
 	ListFriends(userID string) ([]models.FriendSummary, error)
+	CountFriends(userID string) (int, error)
+	CountOutgoingPendingRequests(userID string, since time.Time) (int, error)
 	ListFriendRequests(userID string) ([]models.FriendRequestSummary, error)
 	SearchUsersForFriend(userID string, query string, limit int) ([]models.FriendSearchResult, error)
 	RecordFriendActivity(userID string, date time.Time, bestScore, attemptsUsed int) error
+	GetUserActivityHistory(userID string) ([]models.FriendActivityRecord, error)
 	GetFriendActivities(userID string, limitDays int) ([]models.FriendActivityEntry, error)
+	ListFriendsPlayedOn(userID string, date time.Time) ([]models.FriendDayEntry, error)
 	DeleteFriendship(friendshipID int, userID string) (models.Friendship, error)
 }
 
 type FriendDatabase struct {
-	database *sql.DB
+	database Querier
 }
 
-func NewFriendDatabase(db *sql.DB) (FriendDatabase, error) {
+func NewFriendDatabase(db Querier) (FriendDatabase, error) {
 	return FriendDatabase{database: db}, nil
 }
 
@@ -108,7 +112,8 @@ func (fr FriendDatabase) ListFriends(userID string) ([]models.FriendSummary, err
 			CASE WHEN f.requester_id = $1 THEN u_addressee.user_id ELSE u_requester.user_id END AS friend_user_id,
 			CASE WHEN f.requester_id = $1 THEN u_addressee.username ELSE u_requester.username END AS friend_username,
 			CASE WHEN f.requester_id = $1 THEN u_addressee.points ELSE u_requester.points END AS friend_points,
-			CASE WHEN f.requester_id = $1 THEN u_addressee.level ELSE u_requester.level END AS friend_level
+			CASE WHEN f.requester_id = $1 THEN u_addressee.level ELSE u_requester.level END AS friend_level,
+			CASE WHEN f.requester_id = $1 THEN u_addressee.avatar_url ELSE u_requester.avatar_url END AS friend_avatar_url
 		FROM friendships f
 		JOIN users u_requester ON f.requester_id = u_requester.user_id
 		JOIN users u_addressee ON f.addressee_id = u_addressee.user_id
@@ -122,7 +127,7 @@ func (fr FriendDatabase) ListFriends(userID string) ([]models.FriendSummary, err
 	}
 	defer rows.Close()
 
-	var friends []models.FriendSummary
+	friends := []models.FriendSummary{}
 	for rows.Next() {
 		var friend models.FriendSummary
 		var summary models.UserSummary
@@ -134,6 +139,7 @@ func (fr FriendDatabase) ListFriends(userID string) ([]models.FriendSummary, err
 			&summary.Username,
 			&summary.Points,
 			&summary.Level,
+			&summary.AvatarURL,
 		)
 		if err != nil {
 			return nil, err
@@ -146,6 +152,35 @@ func (fr FriendDatabase) ListFriends(userID string) ([]models.FriendSummary, err
 	return friends, rows.Err()
 }
 
+func (fr FriendDatabase) CountFriends(userID string) (int, error) {
+	sqlStatement := `
+		SELECT COUNT(*)
+		FROM friendships
+		WHERE (requester_id = $1 OR addressee_id = $1) AND status = $2`
+
+	var count int
+	err := fr.database.QueryRow(sqlStatement, userID, models.FriendshipStatusAccepted).Scan(&count)
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// CountOutgoingPendingRequests counts the user's friend requests sent since the given time that are still pending
+func (fr FriendDatabase) CountOutgoingPendingRequests(userID string, since time.Time) (int, error) {
+	sqlStatement := `
+		SELECT COUNT(*)
+		FROM friendships
+		WHERE requester_id = $1 AND status = $2 AND created_at >= $3`
+
+	var count int
+	err := fr.database.QueryRow(sqlStatement, userID, models.FriendshipStatusPending, since).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count pending friend requests: %v", err)
+	}
+	return count, nil
+}
+
 func (fr FriendDatabase) ListFriendRequests(userID string) ([]models.FriendRequestSummary, error) {
 	sqlStatement := `
 		SELECT f.friendship_id, f.created_at, f.status,
@@ -153,7 +188,8 @@ func (fr FriendDatabase) ListFriendRequests(userID string) ([]models.FriendReque
 			CASE WHEN f.addressee_id = $1 THEN u_requester.user_id ELSE u_addressee.user_id END AS other_user_id,
 			CASE WHEN f.addressee_id = $1 THEN u_requester.username ELSE u_addressee.username END AS other_username,
 			CASE WHEN f.addressee_id = $1 THEN u_requester.points ELSE u_addressee.points END AS other_points,
-			CASE WHEN f.addressee_id = $1 THEN u_requester.level ELSE u_addressee.level END AS other_level
+			CASE WHEN f.addressee_id = $1 THEN u_requester.level ELSE u_addressee.level END AS other_level,
+			CASE WHEN f.addressee_id = $1 THEN u_requester.avatar_url ELSE u_addressee.avatar_url END AS other_avatar_url
 		FROM friendships f
 		JOIN users u_requester ON f.requester_id = u_requester.user_id
 		JOIN users u_addressee ON f.addressee_id = u_addressee.user_id
@@ -166,7 +202,7 @@ func (fr FriendDatabase) ListFriendRequests(userID string) ([]models.FriendReque
 	}
 	defer rows.Close()
 
-	var requests []models.FriendRequestSummary
+	requests := []models.FriendRequestSummary{}
 	for rows.Next() {
 		var request models.FriendRequestSummary
 		var summary models.UserSummary
@@ -179,6 +215,7 @@ func (fr FriendDatabase) ListFriendRequests(userID string) ([]models.FriendReque
 			&summary.Username,
 			&summary.Points,
 			&summary.Level,
+			&summary.AvatarURL,
 		)
 		if err != nil {
 			return nil, err
@@ -190,11 +227,19 @@ func (fr FriendDatabase) ListFriendRequests(userID string) ([]models.FriendReque
 	return requests, rows.Err()
 }
 
+// likeEscaper escapes characters that LIKE would otherwise treat as wildcards
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// escapeLikePattern makes user input match literally inside a LIKE pattern using ESCAPE '\'
+func escapeLikePattern(input string) string {
+	return likeEscaper.Replace(input)
+}
+
 func (fr FriendDatabase) SearchUsersForFriend(userID string, query string, limit int) ([]models.FriendSearchResult, error) {
 	if limit <= 0 {
 		limit = 10
 	}
-	searchTerm := fmt.Sprintf("%%%s%%", strings.ToLower(query))
+	searchTerm := fmt.Sprintf("%%%s%%", escapeLikePattern(strings.ToLower(query)))
 
 	sqlStatement := `
 		WITH friend_status AS (
@@ -212,7 +257,8 @@ func (fr FriendDatabase) SearchUsersForFriend(userID string, query string, limit
 		FROM users u
 		LEFT JOIN friend_status fs
 			ON (fs.requester_id = u.user_id OR fs.addressee_id = u.user_id)
-		WHERE LOWER(u.username) LIKE $2 AND u.user_id <> $1
+		-- LOWER(username) must match idx_users_username_trgm's expression for the index to be used
+		WHERE LOWER(u.username) LIKE $2 ESCAPE '\' AND u.user_id <> $1
 		ORDER BY u.username ASC
 		LIMIT $3`
 
@@ -222,7 +268,7 @@ func (fr FriendDatabase) SearchUsersForFriend(userID string, query string, limit
 	}
 	defer rows.Close()
 
-	var results []models.FriendSearchResult
+	results := []models.FriendSearchResult{}
 	for rows.Next() {
 		var result models.FriendSearchResult
 		err := rows.Scan(
@@ -253,6 +299,82 @@ func (fr FriendDatabase) RecordFriendActivity(userID string, date time.Time, bes
 	return err
 }
 
+// GetUserActivityHistory retrieves every day recorded in a user's own activity feed, newest first
+func (fr FriendDatabase) GetUserActivityHistory(userID string) ([]models.FriendActivityRecord, error) {
+	sqlStatement := `
+		SELECT date, best_score, attempts_used, created_at
+		FROM friend_activity
+		WHERE user_id = $1
+		ORDER BY date DESC`
+
+	rows, err := fr.database.Query(sqlStatement, userID)
+	if err != nil {
+		return []models.FriendActivityRecord{}, fmt.Errorf("failed to get activity history: %v", err)
+	}
+	defer rows.Close()
+
+	records := []models.FriendActivityRecord{}
+	for rows.Next() {
+		var record models.FriendActivityRecord
+		if err := rows.Scan(&record.Date, &record.BestScore, &record.AttemptsUsed, &record.CreatedAt); err != nil {
+			return []models.FriendActivityRecord{}, fmt.Errorf("failed to scan activity: %v", err)
+		}
+		records = append(records, record)
+	}
+	return records, rows.Err()
+}
+
+// ListFriendsPlayedOn returns every accepted friend with their leaderboard entry for date, if any.
+// Friends who played come first, best score first; the rest follow by username
+func (fr FriendDatabase) ListFriendsPlayedOn(userID string, date time.Time) ([]models.FriendDayEntry, error) {
+	normalizedDate := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+
+	sqlStatement := `
+		WITH friend_ids AS (
+			SELECT CASE WHEN f.requester_id = $1 THEN f.addressee_id ELSE f.requester_id END AS friend_id
+			FROM friendships f
+			WHERE (f.requester_id = $1 OR f.addressee_id = $1) AND f.status = $2
+		)
+		SELECT u.user_id, u.username, u.points, u.level, u.avatar_url,
+			dl.best_score, COALESCE(dl.attempts_used, 0)
+		FROM friend_ids fi
+		JOIN users u ON u.user_id = fi.friend_id
+		LEFT JOIN daily_leaderboard dl ON dl.user_id = u.user_id AND dl.date = $3
+		ORDER BY dl.best_score DESC NULLS LAST, u.username ASC`
+
+	rows, err := fr.database.Query(sqlStatement, userID, models.FriendshipStatusAccepted, normalizedDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list friends' play for the day: %v", err)
+	}
+	defer rows.Close()
+
+	entries := []models.FriendDayEntry{}
+	for rows.Next() {
+		var entry models.FriendDayEntry
+		var bestScore sql.NullInt64
+		err := rows.Scan(
+			&entry.Friend.UserID,
+			&entry.Friend.Username,
+			&entry.Friend.Points,
+			&entry.Friend.Level,
+			&entry.Friend.AvatarURL,
+			&bestScore,
+			&entry.AttemptsUsed,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if bestScore.Valid {
+			score := int(bestScore.Int64)
+			entry.Played = true
+			entry.BestScore = &score
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
 func (fr FriendDatabase) DeleteFriendship(friendshipID int, userID string) (models.Friendship, error) {
 	sqlStatement := `
 		DELETE FROM friendships
@@ -298,9 +420,10 @@ func (fr FriendDatabase) GetFriendActivities(userID string, limitDays int) ([]mo
 	}
 	defer rows.Close()
 
-	var activities []models.FriendActivityEntry
+	activities := []models.FriendActivityEntry{}
 	for rows.Next() {
 		var activity models.FriendActivityEntry
+		var date time.Time
 		err := rows.Scan(
 			&activity.UserID,
 			&activity.Username,
@@ -308,12 +431,13 @@ func (fr FriendDatabase) GetFriendActivities(userID string, limitDays int) ([]mo
 			&activity.Level,
 			&activity.BestScore,
 			&activity.AttemptsUsed,
-			&activity.Date,
+			&date,
 		)
 		if err != nil {
 			return nil, err
 		}
-		activity.Date = activity.Date[:10]
+		// Scanning the DATE as time.Time avoids depending on the driver's string form of it
+		activity.Date = date.Format("2006-01-02")
 		activities = append(activities, activity)
 	}
 
@@ -0,0 +1,81 @@
+package datastore
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/color-game/api/models"
+)
+
+// ErrInviteCodeInvalid is returned when an invite code doesn't exist, has expired or was already used
+var ErrInviteCodeInvalid = errors.New("invite code is invalid, expired or already used")
+
+// InviteCodeRepository defines the interface for minting and listing invite codes.
+// Codes are consumed by UserRepository.CreateWithInviteCode so the signup and the code share a transaction.
+type InviteCodeRepository interface {
+	CreateInviteCode(createdBy string, expiresAt *time.Time) (models.InviteCode, error)
+	ListInviteCodes() ([]models.InviteCode, error)
+}
+
+type InviteCodeDatabase struct {
+	database Querier
+}
+
+func NewInviteCodeDatabase(db Querier) (InviteCodeDatabase, error) {
+	return InviteCodeDatabase{database: db}, nil
+}
+
+const inviteCodeColumns = `code, created_by, created_at, expires_at, used_by, used_at`
+
+// CreateInviteCode mints a new unused invite code
+func (icd InviteCodeDatabase) CreateInviteCode(createdBy string, expiresAt *time.Time) (models.InviteCode, error) {
+	code, err := models.NewInviteCode()
+	if err != nil {
+		return models.InviteCode{}, fmt.Errorf("failed to generate invite code: %v", err)
+	}
+
+	sqlStatement := `
+		INSERT INTO invite_codes (code, created_by, expires_at)
+		VALUES ($1, $2, $3)
+		RETURNING ` + inviteCodeColumns
+
+	invite, err := scanInviteCode(icd.database.QueryRow(sqlStatement, code, createdBy, expiresAt))
+	if err != nil {
+		return models.InviteCode{}, fmt.Errorf("failed to create invite code: %v", err)
+	}
+	return invite, nil
+}
+
+// ListInviteCodes retrieves every invite code, newest first
+func (icd InviteCodeDatabase) ListInviteCodes() ([]models.InviteCode, error) {
+	rows, err := icd.database.Query(`SELECT ` + inviteCodeColumns + ` FROM invite_codes ORDER BY created_at DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list invite codes: %v", err)
+	}
+	defer rows.Close()
+
+	invites := []models.InviteCode{}
+	for rows.Next() {
+		invite, err := scanInviteCode(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan invite code: %v", err)
+		}
+		invites = append(invites, invite)
+	}
+
+	return invites, rows.Err()
+}
+
+func scanInviteCode(row rowScanner) (models.InviteCode, error) {
+	var invite models.InviteCode
+	err := row.Scan(
+		&invite.Code,
+		&invite.CreatedBy,
+		&invite.CreatedAt,
+		&invite.ExpiresAt,
+		&invite.UsedBy,
+		&invite.UsedAt,
+	)
+	return invite, err
+}
@@ -0,0 +1,206 @@
+package datastore
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/color-game/api/models"
+)
+
+type RewardEventRepository interface {
+	Create(event models.RewardEvent) (models.RewardEvent, error)
+	Get(eventID int) (models.RewardEvent, error)
+	GetAll() ([]models.RewardEvent, error)
+	GetActiveEvent(date time.Time) (models.RewardEvent, error)
+	Update(event models.RewardEvent) (models.RewardEvent, error)
+	Delete(eventID int) error
+}
+
+type RewardEventDatabase struct {
+	database Querier
+}
+
+func NewRewardEventDatabase(db Querier) (RewardEventDatabase, error) {
+	return RewardEventDatabase{database: db}, nil
+}
+
+// Create inserts a new reward event
+func (redb RewardEventDatabase) Create(event models.RewardEvent) (models.RewardEvent, error) {
+	sqlStatement := `
+		INSERT INTO reward_events (name, multiplier, starts_on, ends_on, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, NOW(), NOW())
+		RETURNING event_id, name, multiplier, starts_on, ends_on, created_at, updated_at`
+
+	var created models.RewardEvent
+	err := redb.database.QueryRow(
+		sqlStatement,
+		event.Name,
+		event.Multiplier,
+		event.StartsOn,
+		event.EndsOn,
+	).Scan(
+		&created.EventID,
+		&created.Name,
+		&created.Multiplier,
+		&created.StartsOn,
+		&created.EndsOn,
+		&created.CreatedAt,
+		&created.UpdatedAt,
+	)
+	if err != nil {
+		return models.RewardEvent{}, fmt.Errorf("failed to create reward event: %v", err)
+	}
+
+	return created, nil
+}
+
+// Get retrieves a reward event by ID
+func (redb RewardEventDatabase) Get(eventID int) (models.RewardEvent, error) {
+	sqlStatement := `
+		SELECT event_id, name, multiplier, starts_on, ends_on, created_at, updated_at
+		FROM reward_events
+		WHERE event_id = $1`
+
+	var event models.RewardEvent
+	err := redb.database.QueryRow(sqlStatement, eventID).Scan(
+		&event.EventID,
+		&event.Name,
+		&event.Multiplier,
+		&event.StartsOn,
+		&event.EndsOn,
+		&event.CreatedAt,
+		&event.UpdatedAt,
+	)
+
+	switch err {
+	case sql.ErrNoRows:
+		return models.RewardEvent{}, NoRowsError{true, err}
+	case nil:
+		return event, nil
+	default:
+		return models.RewardEvent{}, err
+	}
+}
+
+// GetAll retrieves every reward event, most recent first
+func (redb RewardEventDatabase) GetAll() ([]models.RewardEvent, error) {
+	sqlStatement := `
+		SELECT event_id, name, multiplier, starts_on, ends_on, created_at, updated_at
+		FROM reward_events
+		ORDER BY starts_on DESC, event_id DESC`
+
+	rows, err := redb.database.Query(sqlStatement)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []models.RewardEvent{}
+	for rows.Next() {
+		var event models.RewardEvent
+		err := rows.Scan(
+			&event.EventID,
+			&event.Name,
+			&event.Multiplier,
+			&event.StartsOn,
+			&event.EndsOn,
+			&event.CreatedAt,
+			&event.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
+
+// GetActiveEvent returns the event covering the given date. When events overlap the
+// one with the highest multiplier wins; multipliers never stack.
+func (redb RewardEventDatabase) GetActiveEvent(date time.Time) (models.RewardEvent, error) {
+	// Normalize date to start of day
+	normalizedDate := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+
+	sqlStatement := `
+		SELECT event_id, name, multiplier, starts_on, ends_on, created_at, updated_at
+		FROM reward_events
+		WHERE starts_on <= $1 AND ends_on >= $1
+		ORDER BY multiplier DESC, event_id ASC
+		LIMIT 1`
+
+	var event models.RewardEvent
+	err := redb.database.QueryRow(sqlStatement, normalizedDate).Scan(
+		&event.EventID,
+		&event.Name,
+		&event.Multiplier,
+		&event.StartsOn,
+		&event.EndsOn,
+		&event.CreatedAt,
+		&event.UpdatedAt,
+	)
+
+	switch err {
+	case sql.ErrNoRows:
+		return models.RewardEvent{}, NoRowsError{true, err}
+	case nil:
+		return event, nil
+	default:
+		return models.RewardEvent{}, err
+	}
+}
+
+// Update saves changes to an existing reward event
+func (redb RewardEventDatabase) Update(event models.RewardEvent) (models.RewardEvent, error) {
+	sqlStatement := `
+		UPDATE reward_events
+		SET name = $2, multiplier = $3, starts_on = $4, ends_on = $5, updated_at = NOW()
+		WHERE event_id = $1
+		RETURNING event_id, name, multiplier, starts_on, ends_on, created_at, updated_at`
+
+	var updated models.RewardEvent
+	err := redb.database.QueryRow(
+		sqlStatement,
+		event.EventID,
+		event.Name,
+		event.Multiplier,
+		event.StartsOn,
+		event.EndsOn,
+	).Scan(
+		&updated.EventID,
+		&updated.Name,
+		&updated.Multiplier,
+		&updated.StartsOn,
+		&updated.EndsOn,
+		&updated.CreatedAt,
+		&updated.UpdatedAt,
+	)
+
+	switch err {
+	case sql.ErrNoRows:
+		return models.RewardEvent{}, NoRowsError{true, err}
+	case nil:
+		return updated, nil
+	default:
+		return models.RewardEvent{}, fmt.Errorf("failed to update reward event: %v", err)
+	}
+}
+
+// Delete removes a reward event
+func (redb RewardEventDatabase) Delete(eventID int) error {
+	result, err := redb.database.Exec(`DELETE FROM reward_events WHERE event_id = $1`, eventID)
+	if err != nil {
+		return fmt.Errorf("failed to delete reward event: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return NoRowsError{true, sql.ErrNoRows}
+	}
+
+	return nil
+}
@@ -0,0 +1,60 @@
+package datastore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/color-game/api/models"
+)
+
+func TestGetActiveEventOverlap(t *testing.T) {
+	db := openTestDB(t)
+	events := RewardEventDatabase{database: db}
+
+	// Far enough out that no real event covers it
+	day := func(d int) time.Time { return time.Date(2199, 3, d, 0, 0, 0, 0, time.UTC) }
+	create := func(name string, multiplier float64, from, to int) models.RewardEvent {
+		t.Helper()
+		event, err := events.Create(models.RewardEvent{Name: name, Multiplier: multiplier, StartsOn: day(from), EndsOn: day(to)})
+		if err != nil {
+			t.Fatalf("failed to create event: %v", err)
+		}
+		t.Cleanup(func() { events.Delete(event.EventID) })
+		return event
+	}
+
+	create("long weekend", 1.5, 1, 10)
+	boost := create("midweek boost", 3, 4, 6)
+	create("tied boost", 3, 5, 6)
+
+	tests := []struct {
+		name     string
+		date     time.Time
+		wantName string
+		noEvent  bool
+	}{
+		{"single event", day(2), "long weekend", false},
+		{"highest multiplier wins", day(4), boost.Name, false},
+		{"ties go to the earliest event", day(5), boost.Name, false},
+		{"time of day is ignored", day(4).Add(23 * time.Hour), boost.Name, false},
+		{"no event", day(20), "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event, err := events.GetActiveEvent(tt.date)
+			if tt.noEvent {
+				if _, ok := err.(NoRowsError); !ok {
+					t.Fatalf("GetActiveEvent error = %v, want NoRowsError", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetActiveEvent error = %v", err)
+			}
+			if event.Name != tt.wantName {
+				t.Errorf("GetActiveEvent = %q, want %q", event.Name, tt.wantName)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package datastore
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/color-game/api/models"
+)
+
+// SettingsRepository stores runtime settings shared by every API instance
+type SettingsRepository interface {
+	GetSetting(key string) (models.Setting, error)
+	ListSettings() ([]models.Setting, error)
+	SetSetting(key, value, updatedBy string) (models.Setting, error)
+	DeleteSetting(key string) (bool, error)
+}
+
+type SettingsDatabase struct {
+	database Querier
+}
+
+func NewSettingsDatabase(db Querier) (SettingsDatabase, error) {
+	return SettingsDatabase{database: db}, nil
+}
+
+// GetSetting returns a setting, or a NoRowsError when it has never been set
+func (sd SettingsDatabase) GetSetting(key string) (models.Setting, error) {
+	var setting models.Setting
+	err := sd.database.QueryRow(`
+		SELECT key, value, updated_by, updated_at
+		FROM app_settings
+		WHERE key = $1`, key,
+	).Scan(&setting.Key, &setting.Value, &setting.UpdatedBy, &setting.UpdatedAt)
+
+	switch err {
+	case sql.ErrNoRows:
+		return models.Setting{}, NoRowsError{true, err}
+	case nil:
+		return setting, nil
+	default:
+		return models.Setting{}, fmt.Errorf("failed to get setting %s: %v", key, err)
+	}
+}
+
+// ListSettings returns every setting that has been set at runtime
+func (sd SettingsDatabase) ListSettings() ([]models.Setting, error) {
+	rows, err := sd.database.Query(`
+		SELECT key, value, updated_by, updated_at
+		FROM app_settings
+		ORDER BY key`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list settings: %v", err)
+	}
+	defer rows.Close()
+
+	settings := []models.Setting{}
+	for rows.Next() {
+		var setting models.Setting
+		if err := rows.Scan(&setting.Key, &setting.Value, &setting.UpdatedBy, &setting.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan setting: %v", err)
+		}
+		settings = append(settings, setting)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list settings: %v", err)
+	}
+	return settings, nil
+}
+
+// SetSetting creates or replaces a setting
+func (sd SettingsDatabase) SetSetting(key, value, updatedBy string) (models.Setting, error) {
+	var setting models.Setting
+	err := sd.database.QueryRow(`
+		INSERT INTO app_settings (key, value, updated_by, updated_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (key) DO UPDATE
+		SET value = EXCLUDED.value, updated_by = EXCLUDED.updated_by, updated_at = EXCLUDED.updated_at
+		RETURNING key, value, updated_by, updated_at`,
+		key, value, updatedBy,
+	).Scan(&setting.Key, &setting.Value, &setting.UpdatedBy, &setting.UpdatedAt)
+	if err != nil {
+		return models.Setting{}, fmt.Errorf("failed to set setting %s: %v", key, err)
+	}
+	return setting, nil
+}
+
+// DeleteSetting removes a runtime value so the environment default applies again. It reports whether
+// there was a value to remove.
+func (sd SettingsDatabase) DeleteSetting(key string) (bool, error) {
+	result, err := sd.database.Exec(`DELETE FROM app_settings WHERE key = $1`, key)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete setting %s: %v", key, err)
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to delete setting %s: %v", key, err)
+	}
+	return deleted > 0, nil
+}
@@ -3,20 +3,64 @@ package datastore
 import (
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
 	"time"
 
 	"github.com/color-game/api/models"
+	"github.com/lib/pq"
 )
 
+// ErrPurchaseAlreadyRefunded is returned when refunding a purchase that was already refunded
+var ErrPurchaseAlreadyRefunded = errors.New("purchase has already been refunded")
+
+// ErrPurchaseItemConsumed is returned when the purchased items have been used, traded or expired
+var ErrPurchaseItemConsumed = errors.New("purchased items have already been used; force the refund to proceed anyway")
+
+// ErrInsufficientCredits is returned when a user can't afford a purchase
+var ErrInsufficientCredits = errors.New("insufficient credits")
+
+// ErrInsufficientStock is returned when a limited item doesn't have enough stock left for a purchase
+var ErrInsufficientStock = errors.New("insufficient stock available")
+
+// ErrItemUnavailable is returned when an item is bought outside its availability window
+var ErrItemUnavailable = errors.New("item is not available for purchase right now")
+
+// ErrPurchaseLimitReached is returned when a purchase would take a user past an item's per-user limit
+var ErrPurchaseLimitReached = errors.New("purchase would exceed the per-user limit for this item")
+
+// PurchaseCooldownError is returned when a user buys an item again before its cooldown has passed
+type PurchaseCooldownError struct {
+	RetryAfter time.Duration
+}
+
+func (e PurchaseCooldownError) Error() string {
+	return fmt.Sprintf("this item can be bought again in %d seconds", e.RetryAfterSeconds())
+}
+
+// RetryAfterSeconds is the wait rounded up to whole seconds, as used by the Retry-After header
+func (e PurchaseCooldownError) RetryAfterSeconds() int {
+	return int(math.Ceil(e.RetryAfter.Seconds()))
+}
+
+// PurchaseLimits restrict how a single user may buy an item. Zero values mean no limit.
+type PurchaseLimits struct {
+	MaxPerUser int           // most copies the user may hold in their inventory
+	Cooldown   time.Duration // minimum time between the user's purchases of the item
+}
+
 // ShopRepository defines the interface for shop-related database operations
 type ShopRepository interface {
 	// Shop Items
 	CreateItem(item models.ShopItem) (models.ShopItem, error)
 	GetItem(itemID string) (models.ShopItem, error)
+	GetActiveItemsByIDs(itemIDs []string) ([]models.ShopItem, error)
 	GetAllItems() ([]models.ShopItem, error)
 	GetItemsByType(itemType string) ([]models.ShopItem, error)
 	GetActiveItems() ([]models.ShopItem, error)
+	GetUnownedActiveItems(userID string) ([]models.ShopItem, error)
+	GetFeaturedItems() ([]models.ShopItem, error)
 	UpdateItem(itemID string, updates models.UpdateShopItemRequest) (models.ShopItem, error)
 	DeactivateItem(itemID string) error
 
@@ -27,23 +71,32 @@ type ShopRepository interface {
 	AddItemToInventory(userID string, itemID string, quantity int, expiresAt *time.Time) error
 	UpdateInventoryQuantity(inventoryID int, quantity int) error
 	EquipItem(inventoryID int, equip bool) error
+	UnequipAll(userID string) (int64, error)
 	GetEquippedItems(userID string) ([]models.UserInventoryWithItem, error)
+	GetEquippedCosmeticsForUsers(userIDs []string) (map[string][]models.EquippedCosmetic, error)
 	UseItem(inventoryID int) error
 	DeleteInventoryItem(inventoryID int) error
 
 	// Purchases
 	CreatePurchase(purchase models.PurchaseRecord) error
-	GetUserPurchaseHistory(userID string) ([]models.PurchaseRecordWithItem, error)
+	PurchaseItem(purchase models.PurchaseRecord, addToInventory bool, limits PurchaseLimits) (creditsRemaining int, err error)
+	GetLastPurchaseTime(userID string, itemID string) (*time.Time, error)
+	GetUserPurchaseHistory(userID string, after *models.PageCursor, limit int) ([]models.PurchaseRecordWithItem, error)
+	CountUserPurchases(userID string) (int, error)
+	GetPurchase(purchaseID string, userID string) (models.PurchaseRecordWithItem, error)
 	GetPurchasesByItem(itemID string) ([]models.PurchaseRecord, error)
+	GetUserSpendingSummary(userID string) (models.SpendingSummary, error)
+	RefundPurchase(purchaseID string, force bool) (models.RefundResult, error)
+	CompensatePurchasers(itemID string, since time.Time, percent int) (usersRefunded int, creditsReturned int, err error)
 }
 
 // ShopDatabase implements ShopRepository
 type ShopDatabase struct {
-	database *sql.DB
+	database Querier
 }
 
 // NewShopDatabase creates a new shop database instance
-func NewShopDatabase(db *sql.DB) (ShopDatabase, error) {
+func NewShopDatabase(db Querier) (ShopDatabase, error) {
 	return ShopDatabase{database: db}, nil
 }
 
@@ -54,11 +107,13 @@ func (sd ShopDatabase) CreateItem(item models.ShopItem) (models.ShopItem, error)
 	query := `
 		INSERT INTO shop_items (
 			item_id, item_type, name, description, credit_cost, rarity,
-			metadata, is_active, is_limited_edition, stock_quantity,
+			metadata, is_active, is_limited_edition, is_featured, stock_quantity,
+			available_from, available_until,
 			created_at, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
 		RETURNING item_id, item_type, name, description, credit_cost, rarity,
-			metadata, is_active, is_limited_edition, stock_quantity,
+			metadata, is_active, is_limited_edition, is_featured, stock_quantity,
+			available_from, available_until,
 			created_at, updated_at`
 
 	row := sd.database.QueryRow(
@@ -72,7 +127,10 @@ func (sd ShopDatabase) CreateItem(item models.ShopItem) (models.ShopItem, error)
 		item.Metadata,
 		item.IsActive,
 		item.IsLimitedEdition,
+		item.IsFeatured,
 		item.StockQuantity,
+		item.AvailableFrom,
+		item.AvailableUntil,
 		item.CreatedAt,
 		item.UpdatedAt,
 	)
@@ -88,7 +146,10 @@ func (sd ShopDatabase) CreateItem(item models.ShopItem) (models.ShopItem, error)
 		&created.Metadata,
 		&created.IsActive,
 		&created.IsLimitedEdition,
+		&created.IsFeatured,
 		&created.StockQuantity,
+		&created.AvailableFrom,
+		&created.AvailableUntil,
 		&created.CreatedAt,
 		&created.UpdatedAt,
 	)
@@ -104,7 +165,8 @@ func (sd ShopDatabase) CreateItem(item models.ShopItem) (models.ShopItem, error)
 func (sd ShopDatabase) GetItem(itemID string) (models.ShopItem, error) {
 	query := `
 		SELECT item_id, item_type, name, description, credit_cost, rarity,
-			metadata, is_active, is_limited_edition, stock_quantity,
+			metadata, is_active, is_limited_edition, is_featured, stock_quantity,
+			available_from, available_until,
 			created_at, updated_at
 		FROM shop_items
 		WHERE item_id = $1`
@@ -120,7 +182,10 @@ func (sd ShopDatabase) GetItem(itemID string) (models.ShopItem, error) {
 		&item.Metadata,
 		&item.IsActive,
 		&item.IsLimitedEdition,
+		&item.IsFeatured,
 		&item.StockQuantity,
+		&item.AvailableFrom,
+		&item.AvailableUntil,
 		&item.CreatedAt,
 		&item.UpdatedAt,
 	)
@@ -135,11 +200,29 @@ func (sd ShopDatabase) GetItem(itemID string) (models.ShopItem, error) {
 	return item, nil
 }
 
+// GetActiveItemsByIDs retrieves the shop items matching the given IDs, skipping IDs that don't exist
+// and items that are inactive or outside their availability window, as GetActiveItems does
+func (sd ShopDatabase) GetActiveItemsByIDs(itemIDs []string) ([]models.ShopItem, error) {
+	query := `
+		SELECT item_id, item_type, name, description, credit_cost, rarity,
+			metadata, is_active, is_limited_edition, is_featured, stock_quantity,
+			available_from, available_until,
+			created_at, updated_at
+		FROM shop_items
+		WHERE item_id = ANY($1) AND is_active = true
+			AND (available_from IS NULL OR available_from <= NOW())
+			AND (available_until IS NULL OR available_until > NOW())
+		ORDER BY created_at DESC`
+
+	return sd.queryItems(query, pq.Array(itemIDs))
+}
+
 // GetAllItems retrieves all shop items
 func (sd ShopDatabase) GetAllItems() ([]models.ShopItem, error) {
 	query := `
 		SELECT item_id, item_type, name, description, credit_cost, rarity,
-			metadata, is_active, is_limited_edition, stock_quantity,
+			metadata, is_active, is_limited_edition, is_featured, stock_quantity,
+			available_from, available_until,
 			created_at, updated_at
 		FROM shop_items
 		ORDER BY created_at DESC`
@@ -151,7 +234,8 @@ func (sd ShopDatabase) GetAllItems() ([]models.ShopItem, error) {
 func (sd ShopDatabase) GetItemsByType(itemType string) ([]models.ShopItem, error) {
 	query := `
 		SELECT item_id, item_type, name, description, credit_cost, rarity,
-			metadata, is_active, is_limited_edition, stock_quantity,
+			metadata, is_active, is_limited_edition, is_featured, stock_quantity,
+			available_from, available_until,
 			created_at, updated_at
 		FROM shop_items
 		WHERE item_type = $1
@@ -166,14 +250,50 @@ func (sd ShopDatabase) GetItemsByType(itemType string) ([]models.ShopItem, error
 	return sd.scanItems(rows)
 }
 
-// GetActiveItems retrieves all active shop items
+// GetActiveItems retrieves all active shop items whose availability window is open
 func (sd ShopDatabase) GetActiveItems() ([]models.ShopItem, error) {
 	query := `
 		SELECT item_id, item_type, name, description, credit_cost, rarity,
-			metadata, is_active, is_limited_edition, stock_quantity,
+			metadata, is_active, is_limited_edition, is_featured, stock_quantity,
+			available_from, available_until,
 			created_at, updated_at
 		FROM shop_items
 		WHERE is_active = true
+			AND (available_from IS NULL OR available_from <= NOW())
+			AND (available_until IS NULL OR available_until > NOW())
+		ORDER BY rarity DESC, created_at DESC`
+
+	return sd.queryItems(query)
+}
+
+// GetUnownedActiveItems retrieves the active shop items a user has none of in their inventory
+func (sd ShopDatabase) GetUnownedActiveItems(userID string) ([]models.ShopItem, error) {
+	query := `
+		SELECT si.item_id, si.item_type, si.name, si.description, si.credit_cost, si.rarity,
+			si.metadata, si.is_active, si.is_limited_edition, si.is_featured, si.stock_quantity,
+			si.available_from, si.available_until,
+			si.created_at, si.updated_at
+		FROM shop_items si
+		LEFT JOIN user_inventory ui ON ui.item_id = si.item_id AND ui.user_id = $1
+		WHERE si.is_active = true AND ui.inventory_id IS NULL
+			AND (si.available_from IS NULL OR si.available_from <= NOW())
+			AND (si.available_until IS NULL OR si.available_until > NOW())
+		ORDER BY si.rarity DESC, si.created_at DESC`
+
+	return sd.queryItems(query, userID)
+}
+
+// GetFeaturedItems retrieves the active shop items admins have marked as featured
+func (sd ShopDatabase) GetFeaturedItems() ([]models.ShopItem, error) {
+	query := `
+		SELECT item_id, item_type, name, description, credit_cost, rarity,
+			metadata, is_active, is_limited_edition, is_featured, stock_quantity,
+			available_from, available_until,
+			created_at, updated_at
+		FROM shop_items
+		WHERE is_active = true AND is_featured = true
+			AND (available_from IS NULL OR available_from <= NOW())
+			AND (available_until IS NULL OR available_until > NOW())
 		ORDER BY rarity DESC, created_at DESC`
 
 	return sd.queryItems(query)
@@ -208,11 +328,16 @@ func (sd ShopDatabase) UpdateItem(itemID string, updates models.UpdateShopItemRe
 	}
 	if updates.Metadata != nil {
 		query += fmt.Sprintf(", metadata = $%d", argIndex)
-		args = append(args, updates.Metadata)
+		if models.IsJSONNull(updates.Metadata) {
+			args = append(args, nil)
+		} else {
+			args = append(args, updates.Metadata)
+		}
 		argIndex++
 	}
 	if updates.IsActive != nil {
-		query += fmt.Sprintf(", is_active = $%d", argIndex)
+		// An admin's choice replaces any sold-out deactivation, so refunds won't override it
+		query += fmt.Sprintf(", is_active = $%d, deactivated_by_stock = false", argIndex)
 		args = append(args, *updates.IsActive)
 		argIndex++
 	}
@@ -221,13 +346,41 @@ func (sd ShopDatabase) UpdateItem(itemID string, updates models.UpdateShopItemRe
 		args = append(args, *updates.IsLimitedEdition)
 		argIndex++
 	}
-	if updates.StockQuantity != nil {
+	if updates.IsFeatured != nil {
+		query += fmt.Sprintf(", is_featured = $%d", argIndex)
+		args = append(args, *updates.IsFeatured)
+		argIndex++
+	}
+	if updates.StockQuantity.Set {
 		query += fmt.Sprintf(", stock_quantity = $%d", argIndex)
-		args = append(args, updates.StockQuantity)
+		if updates.StockQuantity.Null {
+			args = append(args, nil)
+		} else {
+			args = append(args, updates.StockQuantity.Value)
+		}
+		argIndex++
+	}
+
+	if updates.AvailableFrom.Set {
+		query += fmt.Sprintf(", available_from = $%d", argIndex)
+		if updates.AvailableFrom.Null {
+			args = append(args, nil)
+		} else {
+			args = append(args, updates.AvailableFrom.Value)
+		}
+		argIndex++
+	}
+	if updates.AvailableUntil.Set {
+		query += fmt.Sprintf(", available_until = $%d", argIndex)
+		if updates.AvailableUntil.Null {
+			args = append(args, nil)
+		} else {
+			args = append(args, updates.AvailableUntil.Value)
+		}
 		argIndex++
 	}
 
-	query += fmt.Sprintf(" WHERE item_id = $%d RETURNING item_id, item_type, name, description, credit_cost, rarity, metadata, is_active, is_limited_edition, stock_quantity, created_at, updated_at", argIndex)
+	query += fmt.Sprintf(" WHERE item_id = $%d RETURNING item_id, item_type, name, description, credit_cost, rarity, metadata, is_active, is_limited_edition, is_featured, stock_quantity, available_from, available_until, created_at, updated_at", argIndex)
 	args = append(args, itemID)
 
 	var item models.ShopItem
@@ -241,7 +394,10 @@ func (sd ShopDatabase) UpdateItem(itemID string, updates models.UpdateShopItemRe
 		&item.Metadata,
 		&item.IsActive,
 		&item.IsLimitedEdition,
+		&item.IsFeatured,
 		&item.StockQuantity,
+		&item.AvailableFrom,
+		&item.AvailableUntil,
 		&item.CreatedAt,
 		&item.UpdatedAt,
 	)
@@ -255,7 +411,7 @@ func (sd ShopDatabase) UpdateItem(itemID string, updates models.UpdateShopItemRe
 
 // DeactivateItem soft deletes a shop item by setting is_active to false
 func (sd ShopDatabase) DeactivateItem(itemID string) error {
-	query := `UPDATE shop_items SET is_active = false, updated_at = $1 WHERE item_id = $2`
+	query := `UPDATE shop_items SET is_active = false, deactivated_by_stock = false, updated_at = $1 WHERE item_id = $2`
 	_, err := sd.database.Exec(query, time.Now(), itemID)
 	if err != nil {
 		return fmt.Errorf("failed to deactivate item: %v", err)
@@ -272,8 +428,8 @@ func (sd ShopDatabase) GetUserInventory(userID string) ([]models.UserInventoryWi
 			ui.inventory_id, ui.user_id, ui.item_id, ui.quantity,
 			ui.is_equipped, ui.acquired_at, ui.expires_at, ui.used_count,
 			si.item_id, si.item_type, si.name, si.description, si.credit_cost,
-			si.rarity, si.metadata, si.is_active, si.is_limited_edition,
-			si.stock_quantity, si.created_at, si.updated_at
+			si.rarity, si.metadata, si.is_active, si.is_limited_edition, si.is_featured,
+			si.stock_quantity, si.available_from, si.available_until, si.created_at, si.updated_at
 		FROM user_inventory ui
 		JOIN shop_items si ON ui.item_id = si.item_id
 		WHERE ui.user_id = $1
@@ -285,7 +441,7 @@ func (sd ShopDatabase) GetUserInventory(userID string) ([]models.UserInventoryWi
 	}
 	defer rows.Close()
 
-	var inventory []models.UserInventoryWithItem
+	inventory := []models.UserInventoryWithItem{}
 	for rows.Next() {
 		var item models.UserInventoryWithItem
 		err := rows.Scan(
@@ -306,7 +462,10 @@ func (sd ShopDatabase) GetUserInventory(userID string) ([]models.UserInventoryWi
 			&item.ShopItem.Metadata,
 			&item.ShopItem.IsActive,
 			&item.ShopItem.IsLimitedEdition,
+			&item.ShopItem.IsFeatured,
 			&item.ShopItem.StockQuantity,
+			&item.ShopItem.AvailableFrom,
+			&item.ShopItem.AvailableUntil,
 			&item.ShopItem.CreatedAt,
 			&item.ShopItem.UpdatedAt,
 		)
@@ -419,6 +578,21 @@ func (sd ShopDatabase) EquipItem(inventoryID int, equip bool) error {
 	return nil
 }
 
+// UnequipAll unequips every equipped item owned by a user and returns how many were changed
+func (sd ShopDatabase) UnequipAll(userID string) (int64, error) {
+	query := `UPDATE user_inventory SET is_equipped = false WHERE user_id = $1 AND is_equipped = true`
+	result, err := sd.database.Exec(query, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to unequip items: %v", err)
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to count unequipped items: %v", err)
+	}
+	return count, nil
+}
+
 // GetEquippedItems retrieves all equipped items for a user
 func (sd ShopDatabase) GetEquippedItems(userID string) ([]models.UserInventoryWithItem, error) {
 	query := `
@@ -426,8 +600,8 @@ func (sd ShopDatabase) GetEquippedItems(userID string) ([]models.UserInventoryWi
 			ui.inventory_id, ui.user_id, ui.item_id, ui.quantity,
 			ui.is_equipped, ui.acquired_at, ui.expires_at, ui.used_count,
 			si.item_id, si.item_type, si.name, si.description, si.credit_cost,
-			si.rarity, si.metadata, si.is_active, si.is_limited_edition,
-			si.stock_quantity, si.created_at, si.updated_at
+			si.rarity, si.metadata, si.is_active, si.is_limited_edition, si.is_featured,
+			si.stock_quantity, si.available_from, si.available_until, si.created_at, si.updated_at
 		FROM user_inventory ui
 		JOIN shop_items si ON ui.item_id = si.item_id
 		WHERE ui.user_id = $1 AND ui.is_equipped = true`
@@ -438,7 +612,7 @@ func (sd ShopDatabase) GetEquippedItems(userID string) ([]models.UserInventoryWi
 	}
 	defer rows.Close()
 
-	var items []models.UserInventoryWithItem
+	items := []models.UserInventoryWithItem{}
 	for rows.Next() {
 		var item models.UserInventoryWithItem
 		err := rows.Scan(
@@ -459,7 +633,10 @@ func (sd ShopDatabase) GetEquippedItems(userID string) ([]models.UserInventoryWi
 			&item.ShopItem.Metadata,
 			&item.ShopItem.IsActive,
 			&item.ShopItem.IsLimitedEdition,
+			&item.ShopItem.IsFeatured,
 			&item.ShopItem.StockQuantity,
+			&item.ShopItem.AvailableFrom,
+			&item.ShopItem.AvailableUntil,
 			&item.ShopItem.CreatedAt,
 			&item.ShopItem.UpdatedAt,
 		)
@@ -472,6 +649,42 @@ func (sd ShopDatabase) GetEquippedItems(userID string) ([]models.UserInventoryWi
 	return items, nil
 }
 
+// GetEquippedCosmeticsForUsers retrieves the equipped avatar hats and skins for many users in one
+// query, keyed by user ID. Users with nothing equipped are absent from the map.
+func (sd ShopDatabase) GetEquippedCosmeticsForUsers(userIDs []string) (map[string][]models.EquippedCosmetic, error) {
+	cosmetics := make(map[string][]models.EquippedCosmetic)
+	if len(userIDs) == 0 {
+		return cosmetics, nil
+	}
+
+	query := `
+		SELECT ui.user_id, si.item_id, si.item_type, si.name, si.rarity, si.metadata
+		FROM user_inventory ui
+		JOIN shop_items si ON ui.item_id = si.item_id
+		WHERE ui.user_id = ANY($1)
+			AND ui.is_equipped = true
+			AND si.item_type IN ($2, $3)
+			AND (ui.expires_at IS NULL OR ui.expires_at > NOW())
+		ORDER BY ui.user_id, si.item_type`
+
+	rows, err := sd.database.Query(query, pq.Array(userIDs), models.ItemTypeAvatarHat, models.ItemTypeAvatarSkin)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get equipped cosmetics: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var userID string
+		var cosmetic models.EquippedCosmetic
+		if err := rows.Scan(&userID, &cosmetic.ItemID, &cosmetic.ItemType, &cosmetic.Name, &cosmetic.Rarity, &cosmetic.Metadata); err != nil {
+			return nil, fmt.Errorf("failed to scan equipped cosmetic: %v", err)
+		}
+		cosmetics[userID] = append(cosmetics[userID], cosmetic)
+	}
+
+	return cosmetics, rows.Err()
+}
+
 // UseItem increments the used_count for a consumable item
 func (sd ShopDatabase) UseItem(inventoryID int) error {
 	query := `
@@ -511,8 +724,8 @@ func (sd ShopDatabase) DeleteInventoryItem(inventoryID int) error {
 // CreatePurchase records a purchase transaction
 func (sd ShopDatabase) CreatePurchase(purchase models.PurchaseRecord) error {
 	query := `
-		INSERT INTO purchase_history (purchase_id, user_id, item_id, quantity, credits_spent, purchased_at)
-		VALUES ($1, $2, $3, $4, $5, $6)`
+		INSERT INTO purchase_history (purchase_id, user_id, item_id, quantity, credits_spent, purchased_at, status)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`
 
 	_, err := sd.database.Exec(
 		query,
@@ -522,6 +735,7 @@ func (sd ShopDatabase) CreatePurchase(purchase models.PurchaseRecord) error {
 		purchase.Quantity,
 		purchase.CreditsSpent,
 		purchase.PurchasedAt,
+		models.PurchaseStatusCompleted,
 	)
 
 	if err != nil {
@@ -531,27 +745,91 @@ func (sd ShopDatabase) CreatePurchase(purchase models.PurchaseRecord) error {
 	return nil
 }
 
-// GetUserPurchaseHistory retrieves purchase history for a user
-func (sd ShopDatabase) GetUserPurchaseHistory(userID string) ([]models.PurchaseRecordWithItem, error) {
+// PurchaseItem checks the item's availability window, charges the user, takes limited stock,
+// optionally adds the items to their inventory and records the purchase in a single transaction.
+// Credits and stock are checked by the updates themselves, so concurrent purchases can't overdraw
+// either, and the per-user limits are checked in the same transaction. Returns the user's remaining
+// credits.
+func (sd ShopDatabase) PurchaseItem(purchase models.PurchaseRecord, addToInventory bool, limits PurchaseLimits) (int, error) {
+	var creditsRemaining int
+	err := inTx(sd.database, func(tx *sql.Tx) error {
+		txShop := ShopDatabase{database: tx}
+		// Checked against the same NOW() the shop listings filter on
+		if err := txShop.checkAvailableNow(purchase.ItemID); err != nil {
+			return err
+		}
+		var err error
+		// Charging first locks the user's row, so their concurrent purchases queue behind the limit checks
+		creditsRemaining, err = txShop.deductCredits(purchase.UserID, purchase.CreditsSpent)
+		if err != nil {
+			return err
+		}
+		if limits.MaxPerUser > 0 {
+			if err := txShop.checkPerUserLimit(purchase.UserID, purchase.ItemID, purchase.Quantity, limits.MaxPerUser); err != nil {
+				return err
+			}
+		}
+		if limits.Cooldown > 0 {
+			lastPurchase, err := txShop.GetLastPurchaseTime(purchase.UserID, purchase.ItemID)
+			if err != nil {
+				return err
+			}
+			if lastPurchase != nil {
+				if wait := limits.Cooldown - purchase.PurchasedAt.Sub(*lastPurchase); wait > 0 {
+					return PurchaseCooldownError{RetryAfter: wait}
+				}
+			}
+		}
+		if err := txShop.takeStock(purchase.ItemID, purchase.Quantity); err != nil {
+			return err
+		}
+		if addToInventory {
+			if err := txShop.AddItemToInventory(purchase.UserID, purchase.ItemID, purchase.Quantity, nil); err != nil {
+				return err
+			}
+		}
+		return txShop.CreatePurchase(purchase)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return creditsRemaining, nil
+}
+
+// GetUserPurchaseHistory retrieves a user's purchases newest first, starting after the cursor when
+// one is given. A limit of 0 returns every purchase.
+func (sd ShopDatabase) GetUserPurchaseHistory(userID string, after *models.PageCursor, limit int) ([]models.PurchaseRecordWithItem, error) {
+	var afterTime *time.Time
+	var afterID *string
+	if after != nil {
+		afterTime, afterID = &after.At, &after.ID
+	}
+	var limitArg *int
+	if limit > 0 {
+		limitArg = &limit
+	}
+
 	query := `
 		SELECT 
 			ph.purchase_id, ph.user_id, ph.item_id, ph.quantity,
-			ph.credits_spent, ph.purchased_at,
+			ph.credits_spent, ph.purchased_at, ph.status, ph.refunded_at,
 			si.item_id, si.item_type, si.name, si.description, si.credit_cost,
-			si.rarity, si.metadata, si.is_active, si.is_limited_edition,
-			si.stock_quantity, si.created_at, si.updated_at
+			si.rarity, si.metadata, si.is_active, si.is_limited_edition, si.is_featured,
+			si.stock_quantity, si.available_from, si.available_until, si.created_at, si.updated_at
 		FROM purchase_history ph
 		JOIN shop_items si ON ph.item_id = si.item_id
 		WHERE ph.user_id = $1
-		ORDER BY ph.purchased_at DESC`
+			AND ($2::timestamp IS NULL OR (ph.purchased_at, ph.purchase_id) < ($2, $3))
+		ORDER BY ph.purchased_at DESC, ph.purchase_id DESC
+		LIMIT $4`
 
-	rows, err := sd.database.Query(query, userID)
+	rows, err := sd.database.Query(query, userID, afterTime, afterID, limitArg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get purchase history: %v", err)
 	}
 	defer rows.Close()
 
-	var purchases []models.PurchaseRecordWithItem
+	purchases := []models.PurchaseRecordWithItem{}
 	for rows.Next() {
 		var purchase models.PurchaseRecordWithItem
 		err := rows.Scan(
@@ -561,6 +839,8 @@ func (sd ShopDatabase) GetUserPurchaseHistory(userID string) ([]models.PurchaseR
 			&purchase.Quantity,
 			&purchase.CreditsSpent,
 			&purchase.PurchasedAt,
+			&purchase.Status,
+			&purchase.RefundedAt,
 			&purchase.ShopItem.ItemID,
 			&purchase.ShopItem.ItemType,
 			&purchase.ShopItem.Name,
@@ -570,7 +850,10 @@ func (sd ShopDatabase) GetUserPurchaseHistory(userID string) ([]models.PurchaseR
 			&purchase.ShopItem.Metadata,
 			&purchase.ShopItem.IsActive,
 			&purchase.ShopItem.IsLimitedEdition,
+			&purchase.ShopItem.IsFeatured,
 			&purchase.ShopItem.StockQuantity,
+			&purchase.ShopItem.AvailableFrom,
+			&purchase.ShopItem.AvailableUntil,
 			&purchase.ShopItem.CreatedAt,
 			&purchase.ShopItem.UpdatedAt,
 		)
@@ -583,10 +866,70 @@ func (sd ShopDatabase) GetUserPurchaseHistory(userID string) ([]models.PurchaseR
 	return purchases, nil
 }
 
+// CountUserPurchases counts a user's purchases, the total GetUserPurchaseHistory pages through
+func (sd ShopDatabase) CountUserPurchases(userID string) (int, error) {
+	var count int
+	err := sd.database.QueryRow(`SELECT COUNT(*) FROM purchase_history WHERE user_id = $1`, userID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count purchases: %v", err)
+	}
+	return count, nil
+}
+
+// GetPurchase retrieves a single purchase with item details, scoped to the purchasing user
+func (sd ShopDatabase) GetPurchase(purchaseID string, userID string) (models.PurchaseRecordWithItem, error) {
+	query := `
+		SELECT 
+			ph.purchase_id, ph.user_id, ph.item_id, ph.quantity,
+			ph.credits_spent, ph.purchased_at, ph.status, ph.refunded_at,
+			si.item_id, si.item_type, si.name, si.description, si.credit_cost,
+			si.rarity, si.metadata, si.is_active, si.is_limited_edition, si.is_featured,
+			si.stock_quantity, si.available_from, si.available_until, si.created_at, si.updated_at
+		FROM purchase_history ph
+		JOIN shop_items si ON ph.item_id = si.item_id
+		WHERE ph.purchase_id = $1 AND ph.user_id = $2`
+
+	var purchase models.PurchaseRecordWithItem
+	err := sd.database.QueryRow(query, purchaseID, userID).Scan(
+		&purchase.PurchaseID,
+		&purchase.UserID,
+		&purchase.ItemID,
+		&purchase.Quantity,
+		&purchase.CreditsSpent,
+		&purchase.PurchasedAt,
+		&purchase.Status,
+		&purchase.RefundedAt,
+		&purchase.ShopItem.ItemID,
+		&purchase.ShopItem.ItemType,
+		&purchase.ShopItem.Name,
+		&purchase.ShopItem.Description,
+		&purchase.ShopItem.CreditCost,
+		&purchase.ShopItem.Rarity,
+		&purchase.ShopItem.Metadata,
+		&purchase.ShopItem.IsActive,
+		&purchase.ShopItem.IsLimitedEdition,
+		&purchase.ShopItem.IsFeatured,
+		&purchase.ShopItem.StockQuantity,
+		&purchase.ShopItem.AvailableFrom,
+		&purchase.ShopItem.AvailableUntil,
+		&purchase.ShopItem.CreatedAt,
+		&purchase.ShopItem.UpdatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return models.PurchaseRecordWithItem{}, NoRowsError{true, err}
+	}
+	if err != nil {
+		return models.PurchaseRecordWithItem{}, fmt.Errorf("failed to get purchase: %v", err)
+	}
+
+	return purchase, nil
+}
+
 // GetPurchasesByItem retrieves all purchases of a specific item
 func (sd ShopDatabase) GetPurchasesByItem(itemID string) ([]models.PurchaseRecord, error) {
 	query := `
-		SELECT purchase_id, user_id, item_id, quantity, credits_spent, purchased_at
+		SELECT purchase_id, user_id, item_id, quantity, credits_spent, purchased_at, status, refunded_at
 		FROM purchase_history
 		WHERE item_id = $1
 		ORDER BY purchased_at DESC`
@@ -597,7 +940,7 @@ func (sd ShopDatabase) GetPurchasesByItem(itemID string) ([]models.PurchaseRecor
 	}
 	defer rows.Close()
 
-	var purchases []models.PurchaseRecord
+	purchases := []models.PurchaseRecord{}
 	for rows.Next() {
 		var purchase models.PurchaseRecord
 		err := rows.Scan(
@@ -607,6 +950,8 @@ func (sd ShopDatabase) GetPurchasesByItem(itemID string) ([]models.PurchaseRecor
 			&purchase.Quantity,
 			&purchase.CreditsSpent,
 			&purchase.PurchasedAt,
+			&purchase.Status,
+			&purchase.RefundedAt,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan purchase: %v", err)
@@ -617,6 +962,219 @@ func (sd ShopDatabase) GetPurchasesByItem(itemID string) ([]models.PurchaseRecor
 	return purchases, nil
 }
 
+// RefundPurchase reverses a purchase in a single transaction: the items are taken back out of the
+// user's inventory, the credits are returned, limited stock is restored (relisting the item if
+// selling out had deactivated it) and the purchase is marked refunded. Unless force is set, the refund is refused when the user no longer holds every purchased
+// item unused; a forced refund removes whatever is left.
+func (sd ShopDatabase) RefundPurchase(purchaseID string, force bool) (models.RefundResult, error) {
+	var result models.RefundResult
+	err := inTx(sd.database, func(tx *sql.Tx) error {
+		// Lock the purchase so it can't be refunded twice concurrently
+		var purchase models.PurchaseRecord
+		err := tx.QueryRow(`
+			SELECT purchase_id, user_id, item_id, quantity, credits_spent, purchased_at, status, refunded_at
+			FROM purchase_history
+			WHERE purchase_id = $1
+			FOR UPDATE`, purchaseID).Scan(
+			&purchase.PurchaseID,
+			&purchase.UserID,
+			&purchase.ItemID,
+			&purchase.Quantity,
+			&purchase.CreditsSpent,
+			&purchase.PurchasedAt,
+			&purchase.Status,
+			&purchase.RefundedAt,
+		)
+		if err == sql.ErrNoRows {
+			return NoRowsError{true, err}
+		}
+		if err != nil {
+			return fmt.Errorf("failed to lock purchase: %v", err)
+		}
+		// Compensated purchases already got credits back, so they can't be refunded again
+		if purchase.Status != models.PurchaseStatusCompleted {
+			return ErrPurchaseAlreadyRefunded
+		}
+
+		// Auto-applied items never reach the inventory, so a missing row counts as consumed
+		var inventoryID, held, usedCount int
+		err = tx.QueryRow(`
+			SELECT inventory_id, quantity, used_count
+			FROM user_inventory
+			WHERE user_id = $1 AND item_id = $2
+			FOR UPDATE`, purchase.UserID, purchase.ItemID).Scan(&inventoryID, &held, &usedCount)
+		if err != nil && err != sql.ErrNoRows {
+			return fmt.Errorf("failed to lock inventory item: %v", err)
+		}
+		missing := err == sql.ErrNoRows
+
+		if !force && (missing || usedCount > 0 || held < purchase.Quantity) {
+			return ErrPurchaseItemConsumed
+		}
+
+		result = models.RefundResult{CreditsRefunded: purchase.CreditsSpent}
+
+		if !missing {
+			result.ItemsRemoved = purchase.Quantity
+			if held < result.ItemsRemoved {
+				result.ItemsRemoved = held
+			}
+
+			if held-result.ItemsRemoved == 0 {
+				_, err = tx.Exec(`DELETE FROM user_inventory WHERE inventory_id = $1`, inventoryID)
+			} else {
+				_, err = tx.Exec(`UPDATE user_inventory SET quantity = quantity - $2 WHERE inventory_id = $1`, inventoryID, result.ItemsRemoved)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to remove refunded item: %v", err)
+			}
+		}
+
+		err = tx.QueryRow(`
+			UPDATE users SET credits = credits + $2, updated_at = NOW()
+			WHERE user_id = $1
+			RETURNING credits`, purchase.UserID, purchase.CreditsSpent).Scan(&result.UserCredits)
+		if err != nil {
+			return fmt.Errorf("failed to refund credits: %v", err)
+		}
+
+		// Only items with limited stock track a quantity. Restored stock is never 0, so an item that
+		// selling out deactivated is listed again; one an admin deactivated stays hidden.
+		var stock int
+		err = tx.QueryRow(`
+			UPDATE shop_items
+			SET stock_quantity = stock_quantity + $2,
+				is_active = is_active OR deactivated_by_stock,
+				deactivated_by_stock = false,
+				updated_at = NOW()
+			WHERE item_id = $1 AND stock_quantity IS NOT NULL
+			RETURNING stock_quantity`, purchase.ItemID, purchase.Quantity).Scan(&stock)
+		if err != nil && err != sql.ErrNoRows {
+			return fmt.Errorf("failed to restore stock: %v", err)
+		}
+		if err == nil {
+			result.StockQuantity = &stock
+		}
+
+		err = tx.QueryRow(`
+			UPDATE purchase_history SET status = $2, refunded_at = NOW()
+			WHERE purchase_id = $1
+			RETURNING status, refunded_at`, purchaseID, models.PurchaseStatusRefunded).Scan(&purchase.Status, &purchase.RefundedAt)
+		if err != nil {
+			return fmt.Errorf("failed to mark purchase refunded: %v", err)
+		}
+
+		result.Purchase = purchase
+		return nil
+	})
+	if err != nil {
+		return models.RefundResult{}, err
+	}
+	return result, nil
+}
+
+// CompensatePurchasers returns percent of the credits spent on an item to everyone who bought it
+// since the given time, one transaction per purchaser. Compensated purchases are marked so running
+// it again pays nobody twice; purchasers keep their items.
+func (sd ShopDatabase) CompensatePurchasers(itemID string, since time.Time, percent int) (int, int, error) {
+	rows, err := sd.database.Query(`
+		SELECT DISTINCT user_id
+		FROM purchase_history
+		WHERE item_id = $1 AND purchased_at >= $2 AND status = $3`, itemID, since, models.PurchaseStatusCompleted)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to find recent purchasers: %v", err)
+	}
+	var userIDs []string
+	for rows.Next() {
+		var userID string
+		if err := rows.Scan(&userID); err != nil {
+			rows.Close()
+			return 0, 0, fmt.Errorf("failed to scan purchaser: %v", err)
+		}
+		userIDs = append(userIDs, userID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, 0, fmt.Errorf("failed to find recent purchasers: %v", err)
+	}
+
+	usersRefunded, creditsReturned := 0, 0
+	for _, userID := range userIDs {
+		credits, err := sd.compensatePurchaser(userID, itemID, since, percent)
+		if err != nil {
+			return usersRefunded, creditsReturned, err
+		}
+		if credits > 0 {
+			usersRefunded++
+			creditsReturned += credits
+		}
+	}
+
+	return usersRefunded, creditsReturned, nil
+}
+
+// compensatePurchaser refunds one user's qualifying purchases of an item and marks them compensated
+func (sd ShopDatabase) compensatePurchaser(userID, itemID string, since time.Time, percent int) (int, error) {
+	var credits int
+	err := inTx(sd.database, func(tx *sql.Tx) error {
+		// Marking the purchases is what makes a second run a no-op, so do it before paying out
+		err := tx.QueryRow(`
+			WITH compensated AS (
+				UPDATE purchase_history
+				SET status = $5, refunded_at = NOW()
+				WHERE user_id = $1 AND item_id = $2 AND purchased_at >= $3 AND status = $6
+				RETURNING credits_spent * $4 / 100 AS credits
+			)
+			SELECT COALESCE(SUM(credits), 0) FROM compensated`,
+			userID, itemID, since, percent, models.PurchaseStatusCompensated, models.PurchaseStatusCompleted).Scan(&credits)
+		if err != nil {
+			return fmt.Errorf("failed to mark purchases compensated: %v", err)
+		}
+
+		if credits > 0 {
+			_, err = tx.Exec(`UPDATE users SET credits = credits + $2, updated_at = NOW() WHERE user_id = $1`, userID, credits)
+			if err != nil {
+				return fmt.Errorf("failed to return credits: %v", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return credits, nil
+}
+
+// GetUserSpendingSummary totals a user's purchases per item type. Refunded purchases are left out;
+// compensated ones still count in full since the user kept the items
+func (sd ShopDatabase) GetUserSpendingSummary(userID string) (models.SpendingSummary, error) {
+	rows, err := sd.database.Query(`
+		SELECT si.item_type, COUNT(*), COALESCE(SUM(ph.credits_spent), 0)
+		FROM purchase_history ph
+		JOIN shop_items si ON si.item_id = ph.item_id
+		WHERE ph.user_id = $1 AND ph.status <> $2
+		GROUP BY si.item_type
+		ORDER BY SUM(ph.credits_spent) DESC, si.item_type ASC`,
+		userID, models.PurchaseStatusRefunded)
+	if err != nil {
+		return models.SpendingSummary{}, fmt.Errorf("failed to get spending summary: %v", err)
+	}
+	defer rows.Close()
+
+	summary := models.SpendingSummary{ByType: []models.SpendingByType{}}
+	for rows.Next() {
+		var byType models.SpendingByType
+		if err := rows.Scan(&byType.ItemType, &byType.Purchases, &byType.CreditsSpent); err != nil {
+			return models.SpendingSummary{}, fmt.Errorf("failed to scan spending summary: %v", err)
+		}
+		summary.TotalCreditsSpent += byType.CreditsSpent
+		summary.PurchaseCount += byType.Purchases
+		summary.ByType = append(summary.ByType, byType)
+	}
+
+	return summary, rows.Err()
+}
+
 // ============= HELPER FUNCTIONS =============
 
 // queryItems executes a query and returns shop items
@@ -632,7 +1190,7 @@ func (sd ShopDatabase) queryItems(query string, args ...interface{}) ([]models.S
 
 // scanItems scans rows into ShopItem slice
 func (sd ShopDatabase) scanItems(rows *sql.Rows) ([]models.ShopItem, error) {
-	var items []models.ShopItem
+	items := []models.ShopItem{}
 	for rows.Next() {
 		var item models.ShopItem
 		var metadataBytes []byte
@@ -647,7 +1205,10 @@ func (sd ShopDatabase) scanItems(rows *sql.Rows) ([]models.ShopItem, error) {
 			&metadataBytes,
 			&item.IsActive,
 			&item.IsLimitedEdition,
+			&item.IsFeatured,
 			&item.StockQuantity,
+			&item.AvailableFrom,
+			&item.AvailableUntil,
 			&item.CreatedAt,
 			&item.UpdatedAt,
 		)
@@ -669,3 +1230,104 @@ func (sd ShopDatabase) scanItems(rows *sql.Rows) ([]models.ShopItem, error) {
 
 	return items, nil
 }
+
+// deductCredits takes credits from a user, failing with ErrInsufficientCredits rather than going negative
+func (sd ShopDatabase) deductCredits(userID string, amount int) (int, error) {
+	var remaining int
+	err := sd.database.QueryRow(`
+		UPDATE users SET credits = credits - $2, updated_at = NOW()
+		WHERE user_id = $1 AND credits >= $2
+		RETURNING credits`, userID, amount).Scan(&remaining)
+	if err == sql.ErrNoRows {
+		return 0, ErrInsufficientCredits
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to deduct credits: %v", err)
+	}
+	return remaining, nil
+}
+
+// GetLastPurchaseTime returns when the user last bought the item, or nil if they never have
+func (sd ShopDatabase) GetLastPurchaseTime(userID string, itemID string) (*time.Time, error) {
+	var lastPurchase *time.Time
+	err := sd.database.QueryRow(`
+		SELECT MAX(purchased_at) FROM purchase_history
+		WHERE user_id = $1 AND item_id = $2`, userID, itemID).Scan(&lastPurchase)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last purchase time: %v", err)
+	}
+	return lastPurchase, nil
+}
+
+// checkPerUserLimit fails with ErrPurchaseLimitReached if adding quantity to what the user already
+// holds would exceed maxPerUser
+func (sd ShopDatabase) checkPerUserLimit(userID string, itemID string, quantity int, maxPerUser int) error {
+	var owned int
+	err := sd.database.QueryRow(`
+		SELECT COALESCE(SUM(quantity), 0) FROM user_inventory
+		WHERE user_id = $1 AND item_id = $2`, userID, itemID).Scan(&owned)
+	if err != nil {
+		return fmt.Errorf("failed to count owned items: %v", err)
+	}
+	if owned+quantity > maxPerUser {
+		return ErrPurchaseLimitReached
+	}
+	return nil
+}
+
+// checkAvailableNow returns ErrItemUnavailable unless the item's availability window is open
+func (sd ShopDatabase) checkAvailableNow(itemID string) error {
+	var available bool
+	err := sd.database.QueryRow(`
+		SELECT (available_from IS NULL OR available_from <= NOW())
+			AND (available_until IS NULL OR available_until > NOW())
+		FROM shop_items
+		WHERE item_id = $1`, itemID).Scan(&available)
+	if err == sql.ErrNoRows {
+		return NoRowsError{true, err}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to check item availability: %v", err)
+	}
+	if !available {
+		return ErrItemUnavailable
+	}
+	return nil
+}
+
+// takeStock takes quantity from a limited item's stock, failing with ErrInsufficientStock if fewer are
+// left. A nil stock_quantity means unlimited and is left untouched; an item whose stock reaches 0 is
+// sold out and deactivated in the same statement, and flagged so a refund can reactivate it.
+func (sd ShopDatabase) takeStock(itemID string, quantity int) error {
+	result, err := sd.database.Exec(`
+		UPDATE shop_items
+		SET stock_quantity = stock_quantity - $2,
+			is_active = is_active AND stock_quantity - $2 > 0,
+			deactivated_by_stock = deactivated_by_stock OR (is_active AND stock_quantity - $2 = 0),
+			updated_at = NOW()
+		WHERE item_id = $1 AND stock_quantity IS NOT NULL AND stock_quantity >= $2`, itemID, quantity)
+	if err != nil {
+		return fmt.Errorf("failed to update stock: %v", err)
+	}
+	taken, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %v", err)
+	}
+	if taken > 0 {
+		return nil
+	}
+
+	// Nothing was taken: either the item is unlimited or it's short
+	var unlimited bool
+	err = sd.database.QueryRow(`SELECT stock_quantity IS NULL FROM shop_items WHERE item_id = $1`, itemID).Scan(&unlimited)
+	if err == sql.ErrNoRows {
+		return NoRowsError{true, err}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to check stock: %v", err)
+	}
+	if !unlimited {
+		return ErrInsufficientStock
+	}
+	return nil
+}
@@ -0,0 +1,246 @@
+package datastore
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/color-game/api/models"
+	"github.com/google/uuid"
+)
+
+// createTestUser inserts a user holding credits and removes them, with everything they own, when the test ends
+func createTestUser(t *testing.T, db *sql.DB, credits int) string {
+	t.Helper()
+	userID := uuid.New().String()
+	_, err := db.Exec(`
+		INSERT INTO users (user_id, username, email, password_hash, credits)
+		VALUES ($1, $2, $3, 'x', $4)`, userID, "test-"+userID, userID+"@example.com", credits)
+	if err != nil {
+		t.Fatalf("failed to create test user: %v", err)
+	}
+	t.Cleanup(func() { db.Exec(`DELETE FROM users WHERE user_id = $1`, userID) })
+	return userID
+}
+
+// createTestItem inserts an active shop item built from item, filling in the ID and required fields
+func createTestItem(t *testing.T, db *sql.DB, item models.ShopItem) models.ShopItem {
+	t.Helper()
+	item.ItemID = "test-" + uuid.New().String()
+	item.ItemType = "badge"
+	item.Name = item.ItemID
+	item.Rarity = models.RarityCommon
+	item.IsActive = true
+	item.CreatedAt = time.Now()
+	item.UpdatedAt = item.CreatedAt
+
+	created, err := ShopDatabase{database: db}.CreateItem(item)
+	if err != nil {
+		t.Fatalf("failed to create test item: %v", err)
+	}
+	// Purchases reference the item without cascading, so they go first
+	t.Cleanup(func() {
+		db.Exec(`DELETE FROM purchase_history WHERE item_id = $1`, created.ItemID)
+		db.Exec(`DELETE FROM shop_items WHERE item_id = $1`, created.ItemID)
+	})
+	return created
+}
+
+func testPurchase(userID string, item models.ShopItem, quantity int) models.PurchaseRecord {
+	return models.PurchaseRecord{
+		PurchaseID:   uuid.New().String(),
+		UserID:       userID,
+		ItemID:       item.ItemID,
+		Quantity:     quantity,
+		CreditsSpent: item.CreditCost * quantity,
+		PurchasedAt:  time.Now(),
+	}
+}
+
+func TestPurchaseItemAvailabilityWindow(t *testing.T) {
+	db := openTestDB(t)
+	shop := ShopDatabase{database: db}
+
+	hourAgo := time.Now().Add(-time.Hour)
+	hourAhead := time.Now().Add(time.Hour)
+	twoHoursAgo := time.Now().Add(-2 * time.Hour)
+	twoHoursAhead := time.Now().Add(2 * time.Hour)
+
+	tests := []struct {
+		name     string
+		from     *time.Time
+		until    *time.Time
+		wantErr  error
+		credited int
+	}{
+		{"before the window opens", &hourAhead, &twoHoursAhead, ErrItemUnavailable, 100},
+		{"inside the window", &hourAgo, &hourAhead, nil, 90},
+		{"after the window closes", &twoHoursAgo, &hourAgo, ErrItemUnavailable, 100},
+		{"open-ended window", &hourAgo, nil, nil, 90},
+		{"no window", nil, nil, nil, 90},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userID := createTestUser(t, db, 100)
+			item := createTestItem(t, db, models.ShopItem{CreditCost: 10, AvailableFrom: tt.from, AvailableUntil: tt.until})
+
+			remaining, err := shop.PurchaseItem(testPurchase(userID, item, 1), true, PurchaseLimits{})
+			if err != tt.wantErr {
+				t.Fatalf("PurchaseItem error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && remaining != tt.credited {
+				t.Errorf("remaining credits = %d, want %d", remaining, tt.credited)
+			}
+
+			// A refused purchase must leave the user's credits alone
+			var credits int
+			if err := db.QueryRow(`SELECT credits FROM users WHERE user_id = $1`, userID).Scan(&credits); err != nil {
+				t.Fatalf("failed to read credits: %v", err)
+			}
+			if credits != tt.credited {
+				t.Errorf("credits after purchase = %d, want %d", credits, tt.credited)
+			}
+		})
+	}
+}
+
+func TestGetActiveItemsByIDs(t *testing.T) {
+	db := openTestDB(t)
+	shop := ShopDatabase{database: db}
+
+	hourAgo := time.Now().Add(-time.Hour)
+	hourAhead := time.Now().Add(time.Hour)
+
+	listed := createTestItem(t, db, models.ShopItem{})
+	windowOpen := createTestItem(t, db, models.ShopItem{AvailableFrom: &hourAgo, AvailableUntil: &hourAhead})
+	notYetOpen := createTestItem(t, db, models.ShopItem{AvailableFrom: &hourAhead})
+	closed := createTestItem(t, db, models.ShopItem{AvailableUntil: &hourAgo})
+	inactive := createTestItem(t, db, models.ShopItem{})
+	if _, err := db.Exec(`UPDATE shop_items SET is_active = false WHERE item_id = $1`, inactive.ItemID); err != nil {
+		t.Fatalf("failed to deactivate item: %v", err)
+	}
+
+	items, err := shop.GetActiveItemsByIDs([]string{
+		listed.ItemID, windowOpen.ItemID, notYetOpen.ItemID, closed.ItemID, inactive.ItemID, "no-such-item",
+	})
+	if err != nil {
+		t.Fatalf("GetActiveItemsByIDs error = %v", err)
+	}
+
+	got := map[string]bool{}
+	for _, item := range items {
+		got[item.ItemID] = true
+	}
+	want := map[string]bool{listed.ItemID: true, windowOpen.ItemID: true}
+	if len(got) != len(want) || !got[listed.ItemID] || !got[windowOpen.ItemID] {
+		t.Errorf("got items %v, want only %v", got, want)
+	}
+}
+
+// itemStock reads an item's stock and whether it is listed
+func itemStock(t *testing.T, db *sql.DB, itemID string) (*int, bool) {
+	t.Helper()
+	var stock *int
+	var active bool
+	err := db.QueryRow(`SELECT stock_quantity, is_active FROM shop_items WHERE item_id = $1`, itemID).Scan(&stock, &active)
+	if err != nil {
+		t.Fatalf("failed to read stock: %v", err)
+	}
+	return stock, active
+}
+
+func TestTakeStock(t *testing.T) {
+	db := openTestDB(t)
+	shop := ShopDatabase{database: db}
+	stock := func(n int) *int { return &n }
+
+	tests := []struct {
+		name       string
+		stock      *int
+		take       int
+		wantErr    error
+		wantStock  *int
+		wantActive bool
+	}{
+		{"unlimited is left untouched", nil, 5, nil, nil, true},
+		{"more than enough", stock(5), 2, nil, stock(3), true},
+		{"exactly enough sells out", stock(3), 3, nil, stock(0), false},
+		{"insufficient", stock(2), 3, ErrInsufficientStock, stock(2), true},
+		{"already sold out", stock(0), 1, ErrInsufficientStock, stock(0), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := createTestItem(t, db, models.ShopItem{StockQuantity: tt.stock})
+
+			if err := shop.takeStock(item.ItemID, tt.take); err != tt.wantErr {
+				t.Fatalf("takeStock error = %v, want %v", err, tt.wantErr)
+			}
+
+			gotStock, active := itemStock(t, db, item.ItemID)
+			if (gotStock == nil) != (tt.wantStock == nil) || (gotStock != nil && *gotStock != *tt.wantStock) {
+				t.Errorf("stock = %v, want %v", gotStock, tt.wantStock)
+			}
+			if active != tt.wantActive {
+				t.Errorf("is_active = %v, want %v", active, tt.wantActive)
+			}
+		})
+	}
+
+	t.Run("unknown item", func(t *testing.T) {
+		if _, ok := shop.takeStock("no-such-item", 1).(NoRowsError); !ok {
+			t.Errorf("takeStock on a missing item should return NoRowsError")
+		}
+	})
+}
+
+func TestRefundPurchaseRestocksSoldOutItem(t *testing.T) {
+	db := openTestDB(t)
+	shop := ShopDatabase{database: db}
+
+	tests := []struct {
+		name       string
+		stock      int
+		afterBuy   func(itemID string) error
+		wantActive bool
+	}{
+		{"selling out is undone", 1, nil, true},
+		{"admin deactivation after selling out is kept", 1, shop.DeactivateItem, false},
+		{"admin deactivation with stock left is kept", 2, shop.DeactivateItem, false},
+		{"admin reactivation clears the flag", 1, func(itemID string) error {
+			active := true
+			_, err := shop.UpdateItem(itemID, models.UpdateShopItemRequest{IsActive: &active})
+			return err
+		}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userID := createTestUser(t, db, 100)
+			stock := tt.stock
+			item := createTestItem(t, db, models.ShopItem{CreditCost: 10, StockQuantity: &stock})
+
+			purchase := testPurchase(userID, item, 1)
+			if _, err := shop.PurchaseItem(purchase, true, PurchaseLimits{}); err != nil {
+				t.Fatalf("PurchaseItem error = %v", err)
+			}
+			if tt.afterBuy != nil {
+				if err := tt.afterBuy(item.ItemID); err != nil {
+					t.Fatalf("failed to update item: %v", err)
+				}
+			}
+
+			result, err := shop.RefundPurchase(purchase.PurchaseID, false)
+			if err != nil {
+				t.Fatalf("RefundPurchase error = %v", err)
+			}
+			if result.StockQuantity == nil || *result.StockQuantity != tt.stock {
+				t.Errorf("refunded stock = %v, want %d", result.StockQuantity, tt.stock)
+			}
+			if _, active := itemStock(t, db, item.ItemID); active != tt.wantActive {
+				t.Errorf("is_active after refund = %v, want %v", active, tt.wantActive)
+			}
+		})
+	}
+}
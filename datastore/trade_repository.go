@@ -0,0 +1,265 @@
+package datastore
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/color-game/api/models"
+)
+
+// ErrTradeNotPending is returned when a trade has already been accepted, declined or cancelled
+var ErrTradeNotPending = errors.New("trade is no longer pending")
+
+// ErrTradeAssetsUnavailable is returned when either party no longer has what the trade promised
+var ErrTradeAssetsUnavailable = errors.New("one of the players no longer has the offered items or credits")
+
+// TradeRepository defines the interface for trade-related database operations
+type TradeRepository interface {
+	CreateTrade(trade models.Trade) (models.Trade, error)
+	GetTrade(tradeID int) (models.Trade, error)
+	ListTrades(userID string, status string) ([]models.Trade, error)
+	UpdateTradeStatus(tradeID int, status string) (models.Trade, error)
+	AcceptTrade(tradeID int) (models.Trade, error)
+}
+
+type TradeDatabase struct {
+	database Querier
+}
+
+func NewTradeDatabase(db Querier) (TradeDatabase, error) {
+	return TradeDatabase{database: db}, nil
+}
+
+const tradeColumns = `trade_id, proposer_id, recipient_id,
+	offered_item_id, offered_quantity, offered_credits,
+	requested_item_id, requested_quantity, requested_credits,
+	status, created_at, responded_at`
+
+// CreateTrade inserts a new pending trade
+func (td TradeDatabase) CreateTrade(trade models.Trade) (models.Trade, error) {
+	sqlStatement := `
+		INSERT INTO trades (proposer_id, recipient_id,
+			offered_item_id, offered_quantity, offered_credits,
+			requested_item_id, requested_quantity, requested_credits, status)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING ` + tradeColumns
+
+	row := td.database.QueryRow(sqlStatement,
+		trade.ProposerID,
+		trade.RecipientID,
+		trade.OfferedItemID,
+		trade.OfferedQuantity,
+		trade.OfferedCredits,
+		trade.RequestedItemID,
+		trade.RequestedQuantity,
+		trade.RequestedCredits,
+		models.TradeStatusPending,
+	)
+
+	created, err := scanTrade(row)
+	if err != nil {
+		return models.Trade{}, fmt.Errorf("failed to create trade: %v", err)
+	}
+	return created, nil
+}
+
+// GetTrade retrieves a single trade by ID
+func (td TradeDatabase) GetTrade(tradeID int) (models.Trade, error) {
+	sqlStatement := `SELECT ` + tradeColumns + ` FROM trades WHERE trade_id = $1`
+
+	trade, err := scanTrade(td.database.QueryRow(sqlStatement, tradeID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.Trade{}, NoRowsError{true, err}
+		}
+		return models.Trade{}, fmt.Errorf("failed to get trade: %v", err)
+	}
+	return trade, nil
+}
+
+// ListTrades retrieves trades the user proposed or received, optionally filtered by status
+func (td TradeDatabase) ListTrades(userID string, status string) ([]models.Trade, error) {
+	sqlStatement := `
+		SELECT ` + tradeColumns + `
+		FROM trades
+		WHERE (proposer_id = $1 OR recipient_id = $1)
+			AND ($2 = '' OR status = $2)
+		ORDER BY created_at DESC`
+
+	rows, err := td.database.Query(sqlStatement, userID, status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list trades: %v", err)
+	}
+	defer rows.Close()
+
+	trades := []models.Trade{}
+	for rows.Next() {
+		trade, err := scanTrade(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan trade: %v", err)
+		}
+		trades = append(trades, trade)
+	}
+
+	return trades, rows.Err()
+}
+
+// UpdateTradeStatus moves a pending trade to declined or cancelled. Nothing changes hands.
+func (td TradeDatabase) UpdateTradeStatus(tradeID int, status string) (models.Trade, error) {
+	if status != models.TradeStatusDeclined && status != models.TradeStatusCancelled {
+		return models.Trade{}, fmt.Errorf("invalid status")
+	}
+
+	sqlStatement := `
+		UPDATE trades
+		SET status = $2, responded_at = NOW()
+		WHERE trade_id = $1 AND status = $3
+		RETURNING ` + tradeColumns
+
+	trade, err := scanTrade(td.database.QueryRow(sqlStatement, tradeID, status, models.TradeStatusPending))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.Trade{}, ErrTradeNotPending
+		}
+		return models.Trade{}, fmt.Errorf("failed to update trade: %v", err)
+	}
+	return trade, nil
+}
+
+// AcceptTrade swaps both sides' items and credits and marks the trade accepted in a single transaction.
+// Ownership is re-validated here so an item used or traded away since the proposal fails the trade.
+func (td TradeDatabase) AcceptTrade(tradeID int) (models.Trade, error) {
+	var accepted models.Trade
+	err := inTx(td.database, func(tx *sql.Tx) error {
+		// Lock the trade so two concurrent accepts can't both succeed
+		trade, err := scanTrade(tx.QueryRow(`SELECT `+tradeColumns+` FROM trades WHERE trade_id = $1 FOR UPDATE`, tradeID))
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return NoRowsError{true, err}
+			}
+			return fmt.Errorf("failed to lock trade: %v", err)
+		}
+		if trade.Status != models.TradeStatusPending {
+			return ErrTradeNotPending
+		}
+
+		// Lock both users in a stable order to avoid deadlocks with other trades
+		rows, err := tx.Query(`
+			SELECT user_id, credits FROM users
+			WHERE user_id IN ($1, $2)
+			ORDER BY user_id
+			FOR UPDATE`, trade.ProposerID, trade.RecipientID)
+		if err != nil {
+			return fmt.Errorf("failed to lock users: %v", err)
+		}
+		credits := make(map[string]int)
+		for rows.Next() {
+			var userID string
+			var balance int
+			if err := rows.Scan(&userID, &balance); err != nil {
+				rows.Close()
+				return fmt.Errorf("failed to scan user credits: %v", err)
+			}
+			credits[userID] = balance
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("failed to lock users: %v", err)
+		}
+
+		if credits[trade.ProposerID] < trade.OfferedCredits || credits[trade.RecipientID] < trade.RequestedCredits {
+			return ErrTradeAssetsUnavailable
+		}
+
+		if trade.OfferedItemID != nil {
+			if err := transferInventoryItem(tx, trade.ProposerID, trade.RecipientID, *trade.OfferedItemID, trade.OfferedQuantity); err != nil {
+				return err
+			}
+		}
+		if trade.RequestedItemID != nil {
+			if err := transferInventoryItem(tx, trade.RecipientID, trade.ProposerID, *trade.RequestedItemID, trade.RequestedQuantity); err != nil {
+				return err
+			}
+		}
+
+		creditStatement := `UPDATE users SET credits = credits - $2 + $3, updated_at = NOW() WHERE user_id = $1`
+		if _, err := tx.Exec(creditStatement, trade.ProposerID, trade.OfferedCredits, trade.RequestedCredits); err != nil {
+			return fmt.Errorf("failed to update proposer credits: %v", err)
+		}
+		if _, err := tx.Exec(creditStatement, trade.RecipientID, trade.RequestedCredits, trade.OfferedCredits); err != nil {
+			return fmt.Errorf("failed to update recipient credits: %v", err)
+		}
+
+		accepted, err = scanTrade(tx.QueryRow(`
+			UPDATE trades
+			SET status = $2, responded_at = NOW()
+			WHERE trade_id = $1
+			RETURNING `+tradeColumns, tradeID, models.TradeStatusAccepted))
+		if err != nil {
+			return fmt.Errorf("failed to accept trade: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return models.Trade{}, err
+	}
+	return accepted, nil
+}
+
+// transferInventoryItem moves quantity of an item from one user's inventory to another's
+func transferInventoryItem(tx *sql.Tx, fromUserID, toUserID, itemID string, quantity int) error {
+	var remaining int
+	err := tx.QueryRow(`
+		UPDATE user_inventory
+		SET quantity = quantity - $3
+		WHERE user_id = $1 AND item_id = $2 AND quantity >= $3
+			AND (expires_at IS NULL OR expires_at > NOW())
+		RETURNING quantity`, fromUserID, itemID, quantity).Scan(&remaining)
+	if err == sql.ErrNoRows {
+		return ErrTradeAssetsUnavailable
+	}
+	if err != nil {
+		return fmt.Errorf("failed to remove traded item: %v", err)
+	}
+
+	if remaining == 0 {
+		if _, err := tx.Exec(`DELETE FROM user_inventory WHERE user_id = $1 AND item_id = $2`, fromUserID, itemID); err != nil {
+			return fmt.Errorf("failed to remove traded item: %v", err)
+		}
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO user_inventory (user_id, item_id, quantity)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, item_id)
+		DO UPDATE SET quantity = user_inventory.quantity + EXCLUDED.quantity`, toUserID, itemID, quantity)
+	if err != nil {
+		return fmt.Errorf("failed to add traded item: %v", err)
+	}
+	return nil
+}
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanTrade(row rowScanner) (models.Trade, error) {
+	var trade models.Trade
+	err := row.Scan(
+		&trade.TradeID,
+		&trade.ProposerID,
+		&trade.RecipientID,
+		&trade.OfferedItemID,
+		&trade.OfferedQuantity,
+		&trade.OfferedCredits,
+		&trade.RequestedItemID,
+		&trade.RequestedQuantity,
+		&trade.RequestedCredits,
+		&trade.Status,
+		&trade.CreatedAt,
+		&trade.RespondedAt,
+	)
+	return trade, err
+}
@@ -0,0 +1,56 @@
+package datastore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// Querier is the subset of *sql.DB and *sql.Tx the repositories use, so the same repository
+// method can run standalone or as one step of a larger transaction
+type Querier interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// WithTx runs fn inside a transaction, committing if it returns nil and rolling back otherwise.
+// A panic in fn also rolls the transaction back before being re-raised.
+func WithTx(db *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %v", err)
+	}
+	return nil
+}
+
+// inTx runs fn in a new transaction, or directly in the caller's when q is already a *sql.Tx,
+// in which case committing or rolling back is left to whoever began it
+func inTx(q Querier, fn func(tx *sql.Tx) error) error {
+	switch q := q.(type) {
+	case *sql.Tx:
+		return fn(q)
+	case *sql.DB:
+		return WithTx(q, fn)
+	default:
+		return fmt.Errorf("cannot begin a transaction on %T", q)
+	}
+}
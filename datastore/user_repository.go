@@ -13,11 +13,16 @@ import (
 
 type UserRepository interface {
 	Create(user models.User) (models.User, error)
+	CreateWithInviteCode(user models.User, code string) (models.User, error)
 	Get(userID string) (models.User, error)
 	GetUserByEmail(email string) (models.User, error)
 	GetUserByUsername(username string) (models.User, error)
 	DeleteUserByID(userID string) error
 	Update(user models.User) (models.User, error)
+	SetAvatarURL(userID string, avatarURL string) error
+	SetHideFromActivityFeed(userID string, hide bool) error
+	GetUserLevelsPage(afterUserID string, limit int) ([]models.UserSummary, error)
+	SetLevelIfPointsUnchanged(userID string, points int, level int) (bool, error)
 	ValidateAndGetUser(userLogin models.Credentials) (models.User, error)
 	GetAllUsers() ([]models.User, error)
 
@@ -25,9 +30,16 @@ type UserRepository interface {
 	CreateDevice(device models.UserDevice) error
 	GetDeviceByFingerprint(userID string, fingerprint string) (models.UserDevice, error)
 	DeleteDevice(deviceID string) error
+	GetDevicesByUser(userID string) ([]models.UserDevice, error)
+	DeleteDevicesByUser(userID string) (int64, error)
+
+	// Token revocation
+	RevokeToken(jti string, userID string, expiresAt time.Time) error
+	IsTokenRevoked(jti string) (bool, error)
+	DeleteExpiredRevokedTokens() (int64, error)
 }
 
-func NewUserDatabase(db *sql.DB) (UserDatabase, error) {
+func NewUserDatabase(db Querier) (UserDatabase, error) {
 	var UserDatabase UserDatabase
 	UserDatabase.database = db
 	return UserDatabase, nil
@@ -43,7 +55,7 @@ func (nr NoRowsError) Error() string {
 }
 
 type UserDatabase struct {
-	database *sql.DB
+	database Querier
 }
 
 func (pgdb UserDatabase) Create(user models.User) (models.User, error) {
@@ -95,6 +107,55 @@ func (pgdb UserDatabase) Create(user models.User) (models.User, error) {
 	return user, nil
 }
 
+// CreateWithInviteCode consumes an unused, unexpired invite code and creates the user in one
+// transaction, so a code can never be spent twice or lost to a failed signup
+func (pgdb UserDatabase) CreateWithInviteCode(user models.User, code string) (models.User, error) {
+	err := inTx(pgdb.database, func(tx *sql.Tx) error {
+		// The user has to exist before the code can reference it, so insert first
+		_, err := tx.Exec(`
+			INSERT INTO users (
+				user_id, username, email, password_hash, kind, approved,
+				points, level, credits, created_at, updated_at
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`,
+			user.UserID,
+			user.Username,
+			user.Email,
+			user.HashedPassword,
+			user.Kind,
+			user.Approved,
+			user.Points,
+			user.Level,
+			user.Credits,
+			user.CreatedAt,
+			user.UpdatedAt,
+		)
+		if err != nil {
+			return err
+		}
+
+		result, err := tx.Exec(`
+			UPDATE invite_codes
+			SET used_by = $2, used_at = NOW()
+			WHERE code = $1 AND used_at IS NULL
+				AND (expires_at IS NULL OR expires_at > NOW())`, code, user.UserID)
+		if err != nil {
+			return fmt.Errorf("failed to consume invite code: %v", err)
+		}
+		consumed, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to check rows affected: %v", err)
+		}
+		if consumed == 0 {
+			return ErrInviteCodeInvalid
+		}
+		return nil
+	})
+	if err != nil {
+		return user, err
+	}
+	return user, nil
+}
+
 func (pgdb UserDatabase) Get(userID string) (models.User, error) {
 	db := pgdb.database
 
@@ -109,6 +170,8 @@ func (pgdb UserDatabase) Get(userID string) (models.User, error) {
 		points,
 		level,
 		credits,
+		avatar_url,
+		hide_from_activity_feed,
 		created_at,
 		updated_at
 	FROM users 
@@ -127,6 +190,8 @@ func (pgdb UserDatabase) Get(userID string) (models.User, error) {
 		&user.Points,
 		&user.Level,
 		&user.Credits,
+		&user.AvatarURL,
+		&user.HideFromFeed,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -154,6 +219,8 @@ func (pgdb UserDatabase) GetAllUsers() ([]models.User, error) {
 		points,
 		level,
 		credits,
+		avatar_url,
+		hide_from_activity_feed,
 		created_at,
 		updated_at
 	FROM users
@@ -165,7 +232,7 @@ func (pgdb UserDatabase) GetAllUsers() ([]models.User, error) {
 	}
 	defer rows.Close()
 
-	var users []models.User
+	users := []models.User{}
 	for rows.Next() {
 		var user models.User
 		scanErr := rows.Scan(
@@ -178,6 +245,8 @@ func (pgdb UserDatabase) GetAllUsers() ([]models.User, error) {
 			&user.Points,
 			&user.Level,
 			&user.Credits,
+			&user.AvatarURL,
+			&user.HideFromFeed,
 			&user.CreatedAt,
 			&user.UpdatedAt,
 		)
@@ -207,6 +276,8 @@ func (pgdb UserDatabase) GetUserByEmail(email string) (models.User, error) {
 			points,
 			level,
 			credits,
+			avatar_url,
+			hide_from_activity_feed,
 			created_at,
 			updated_at
 		FROM users
@@ -225,6 +296,8 @@ func (pgdb UserDatabase) GetUserByEmail(email string) (models.User, error) {
 		&user.Points,
 		&user.Level,
 		&user.Credits,
+		&user.AvatarURL,
+		&user.HideFromFeed,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -253,6 +326,8 @@ func (pgdb UserDatabase) GetUserByUsername(username string) (models.User, error)
 			points,
 			level,
 			credits,
+			avatar_url,
+			hide_from_activity_feed,
 			created_at,
 			updated_at
 		FROM users
@@ -271,6 +346,8 @@ func (pgdb UserDatabase) GetUserByUsername(username string) (models.User, error)
 		&user.Points,
 		&user.Level,
 		&user.Credits,
+		&user.AvatarURL,
+		&user.HideFromFeed,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -327,6 +404,78 @@ func (pgdb UserDatabase) Update(user models.User) (models.User, error) {
 	return user, nil
 }
 
+// SetAvatarURL replaces the user's avatar; an empty url clears it
+func (pgdb UserDatabase) SetAvatarURL(userID string, avatarURL string) error {
+	result, err := pgdb.database.Exec(`
+		UPDATE users
+		SET avatar_url = $2, updated_at = NOW()
+		WHERE user_id = $1`, userID, avatarURL)
+	if err != nil {
+		return fmt.Errorf("failed to update avatar: %v", err)
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return NoRowsError{true, sql.ErrNoRows}
+	}
+	return nil
+}
+
+// SetHideFromActivityFeed opts the user out of, or back into, the public recent activity feed
+func (pgdb UserDatabase) SetHideFromActivityFeed(userID string, hide bool) error {
+	result, err := pgdb.database.Exec(`
+		UPDATE users
+		SET hide_from_activity_feed = $2, updated_at = NOW()
+		WHERE user_id = $1`, userID, hide)
+	if err != nil {
+		return fmt.Errorf("failed to update activity feed preference: %v", err)
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return NoRowsError{true, sql.ErrNoRows}
+	}
+	return nil
+}
+
+// GetUserLevelsPage returns the points and level of up to limit users ordered by ID, starting after
+// afterUserID, so maintenance jobs can walk every user without holding one long query open
+func (pgdb UserDatabase) GetUserLevelsPage(afterUserID string, limit int) ([]models.UserSummary, error) {
+	rows, err := pgdb.database.Query(`
+		SELECT user_id, username, points, level
+		FROM users
+		WHERE user_id > $1
+		ORDER BY user_id
+		LIMIT $2`, afterUserID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list user levels: %v", err)
+	}
+	defer rows.Close()
+
+	users := []models.UserSummary{}
+	for rows.Next() {
+		var user models.UserSummary
+		if err := rows.Scan(&user.UserID, &user.Username, &user.Points, &user.Level); err != nil {
+			return nil, fmt.Errorf("failed to scan user level: %v", err)
+		}
+		users = append(users, user)
+	}
+	return users, rows.Err()
+}
+
+// SetLevelIfPointsUnchanged corrects a user's level, unless their points changed since they were
+// read; a reward landing in between already set the level from the new total
+func (pgdb UserDatabase) SetLevelIfPointsUnchanged(userID string, points int, level int) (bool, error) {
+	result, err := pgdb.database.Exec(`
+		UPDATE users
+		SET level = $3, updated_at = NOW()
+		WHERE user_id = $1 AND points = $2 AND level <> $3`, userID, points, level)
+	if err != nil {
+		return false, fmt.Errorf("failed to set level: %v", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to set level: %v", err)
+	}
+	return rows > 0, nil
+}
+
 func (pgdb UserDatabase) ValidateAndGetUser(credentials models.Credentials) (models.User, error) {
 	db := pgdb.database
 	sqlStatement := `
@@ -340,6 +489,8 @@ func (pgdb UserDatabase) ValidateAndGetUser(credentials models.Credentials) (mod
 		points,
 		level,
 		credits,
+		avatar_url,
+		hide_from_activity_feed,
 		created_at,
 		updated_at
 	FROM users
@@ -359,6 +510,8 @@ func (pgdb UserDatabase) ValidateAndGetUser(credentials models.Credentials) (mod
 		&user.Points,
 		&user.Level,
 		&user.Credits,
+		&user.AvatarURL,
+		&user.HideFromFeed,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -416,3 +569,77 @@ func (pgdb UserDatabase) DeleteDevice(deviceID string) error {
 
 	return err
 }
+
+// GetDevicesByUser retrieves all devices registered to a user
+func (pgdb UserDatabase) GetDevicesByUser(userID string) ([]models.UserDevice, error) {
+	db := pgdb.database
+
+	sqlStatement := `
+		SELECT id, user_id, device_data, fingerprint, expiry
+		FROM user_devices
+		WHERE user_id = $1
+		ORDER BY expiry DESC`
+
+	rows, err := db.Query(sqlStatement, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	devices := []models.UserDevice{}
+	for rows.Next() {
+		var device models.UserDevice
+		if err := rows.Scan(&device.ID, &device.UserID, &device.DeviceData, &device.Fingerprint, &device.Expiry); err != nil {
+			return nil, err
+		}
+		devices = append(devices, device)
+	}
+
+	return devices, rows.Err()
+}
+
+// DeleteDevicesByUser removes every device for a user, returning how many were removed
+func (pgdb UserDatabase) DeleteDevicesByUser(userID string) (int64, error) {
+	db := pgdb.database
+
+	result, err := db.Exec(`DELETE FROM user_devices WHERE user_id = $1`, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// RevokeToken adds a token's jti to the denylist until it expires
+func (pgdb UserDatabase) RevokeToken(jti string, userID string, expiresAt time.Time) error {
+	db := pgdb.database
+
+	sqlStatement := `
+		INSERT INTO revoked_tokens (jti, user_id, expires_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (jti) DO NOTHING`
+
+	_, err := db.Exec(sqlStatement, jti, userID, expiresAt)
+	return err
+}
+
+// IsTokenRevoked reports whether a token's jti is on the denylist
+func (pgdb UserDatabase) IsTokenRevoked(jti string) (bool, error) {
+	db := pgdb.database
+
+	var revoked bool
+	err := db.QueryRow(`SELECT EXISTS(SELECT 1 FROM revoked_tokens WHERE jti = $1)`, jti).Scan(&revoked)
+	return revoked, err
+}
+
+// DeleteExpiredRevokedTokens removes denylist entries for tokens that have expired on their own
+func (pgdb UserDatabase) DeleteExpiredRevokedTokens() (int64, error) {
+	db := pgdb.database
+
+	result, err := db.Exec(`DELETE FROM revoked_tokens WHERE expires_at < NOW()`)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
@@ -7,10 +7,13 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/color-game/api/api"
+	"github.com/color-game/api/colorapi"
 	"github.com/color-game/api/datastore"
 	"github.com/color-game/api/migrations"
+	"github.com/color-game/api/models"
 	"github.com/color-game/api/scheduler"
 	"github.com/joho/godotenv"
 )
@@ -21,27 +24,94 @@ func main() {
 
 	// Get configuration from environment
 	config := api.Config{
-		HTTPPort:           getEnv("HTTP_PORT", ":8080"),
-		DatabaseType:       getEnv("DB_TYPE", "postgres"),
-		DatabaseUser:       getEnv("DB_USER", "postgres"),
-		DatabasePassword:   getEnv("DB_PASSWORD", ""),
-		DatabaseName:       getEnv("DB_NAME", "colorgame"),
-		SSLMode:            getEnv("SSL_MODE", "disable"),
-		JwtSecret:          getEnv("JWT_SECRET", "your-secret-key-change-this"),
-		JwtAccessDuration:  getEnvInt("JWT_ACCESS_DURATION", 900),     // 15 minutes
-		JwtRefreshDuration: getEnvInt("JWT_REFRESH_DURATION", 604800), // 7 days
-		JwtDomain:          getEnv("JWT_DOMAIN", ""),
-		AllowedOrigins:     getEnvSlice("ALLOWED_ORIGINS", "http://localhost:3000,http://localhost:5173"),
-		DevMode:            getEnvBool("DEV_MODE", true),
+		HTTPPort:            getEnv("HTTP_PORT", ":8080"),
+		DatabaseType:        getEnv("DB_TYPE", "postgres"),
+		DatabaseUser:        getEnv("DB_USER", "postgres"),
+		DatabasePassword:    getEnv("DB_PASSWORD", ""),
+		DatabaseName:        getEnv("DB_NAME", "colorgame"),
+		SSLMode:             getEnv("SSL_MODE", "disable"),
+		JwtSecret:           getEnv("JWT_SECRET", api.DefaultJwtSecret),
+		JwtAccessDuration:   getEnvInt("JWT_ACCESS_DURATION", 900),     // 15 minutes
+		JwtRefreshDuration:  getEnvInt("JWT_REFRESH_DURATION", 604800), // 7 days
+		JwtDomain:           getEnv("JWT_DOMAIN", ""),
+		JwtIssuer:           getEnv("JWT_ISSUER", "color-game-api"),
+		JwtAudience:         getEnv("JWT_AUDIENCE", "color-game"),
+		AllowedOrigins:      getEnvSlice("ALLOWED_ORIGINS", "http://localhost:3000,http://localhost:5173"),
+		AllowedMethods:      getEnvSlice("ALLOWED_METHODS", api.DefaultAllowedMethods),
+		AllowedHeaders:      getEnvSlice("ALLOWED_HEADERS", api.DefaultAllowedHeaders),
+		DevMode:             getEnvBool("DEV_MODE", true),
+		SignupEnabled:       getEnvBool("SIGNUP_ENABLED", true),
+		SignupInviteOnly:    getEnvBool("SIGNUP_INVITE_ONLY", false),
+		ColorAPIBaseURL:     getEnv("COLOR_API_BASE_URL", colorapi.DefaultBaseURL),
+		ColorSchemeMode:     getEnv("COLOR_SCHEME_MODE", "analogic"),
+		ColorSchemeCount:    getEnvInt("COLOR_SCHEME_COUNT", 6),
+		ColorSchemeRotation: getEnvList("COLOR_SCHEME_ROTATION"),
+		ColorCandidates:     getEnvInt("COLOR_CANDIDATES", 1),
+		LeaderboardMaxLimit: getEnvInt("LEADERBOARD_MAX_LIMIT", 500),
+		ScoreRetentionDays:  getEnvInt("SCORE_RETENTION_DAYS", 90),
+		MaxFriends:          getEnvInt("MAX_FRIENDS", 200),
+
+		MaxDailyFriendRequests: getEnvInt("MAX_DAILY_FRIEND_REQUESTS", 20),
+
+		DeterministicDailyColor: getEnvBool("DETERMINISTIC_DAILY_COLOR", false),
+
+		DailyColorArchiveDays: getEnvInt("DAILY_COLOR_ARCHIVE_DAYS", 30),
+		ScoreGraceMinutes:     getEnvInt("SCORE_GRACE_MINUTES", 0),
+
+		ExtraAttemptCreditCost:       getEnvInt("EXTRA_ATTEMPT_CREDIT_COST", 100),
+		DeactivationRefundWindowDays: getEnvInt("DEACTIVATION_REFUND_WINDOW_DAYS", 7),
+
+		SignupCreditBonus: getEnvInt("SIGNUP_CREDIT_BONUS", 0),
+		SignupPointBonus:  getEnvInt("SIGNUP_POINT_BONUS", 0),
+
+		PointsPerScorePoint:  getEnvFloat("POINTS_PER_SCORE_POINT", 1),
+		CreditsPerScorePoint: getEnvFloat("CREDITS_PER_SCORE_POINT", 0.5),
+		LevelCurve:           getEnvIntSlice("LEVEL_CURVE"),
+		LevelAttemptBonus:    getEnvIntSlice("LEVEL_ATTEMPT_BONUS"),
+
+		PasswordPolicy: models.PasswordPolicy{
+			MinLength:        getEnvInt("PASSWORD_MIN_LENGTH", 8),
+			RequireMixedCase: getEnvBool("PASSWORD_REQUIRE_MIXED_CASE", false),
+			RequireDigit:     getEnvBool("PASSWORD_REQUIRE_DIGIT", false),
+			RequireSymbol:    getEnvBool("PASSWORD_REQUIRE_SYMBOL", false),
+		},
+
+		MaxConcurrentRequests: getEnvInt("MAX_CONCURRENT_REQUESTS", 1000),
+		ResponseEnvelope:      getEnvBool("RESPONSE_ENVELOPE", false),
+
+		MaintenanceMode: getEnvBool("MAINTENANCE_MODE", false),
+
+		RequestTimeout: getEnvInt("REQUEST_TIMEOUT", 25),
+
+		ServerReadTimeout:       getEnvInt("SERVER_READ_TIMEOUT", 10),
+		ServerReadHeaderTimeout: getEnvInt("SERVER_READ_HEADER_TIMEOUT", 5),
+		ServerWriteTimeout:      getEnvInt("SERVER_WRITE_TIMEOUT", 30),
+		ServerIdleTimeout:       getEnvInt("SERVER_IDLE_TIMEOUT", 60),
+	}
+
+	for _, warning := range config.Warnings() {
+		log.Printf("WARNING: %s", warning)
+	}
+
+	// Refuse to start with an unsafe or inconsistent configuration
+	if problems := config.Validate(); len(problems) > 0 {
+		for _, problem := range problems {
+			log.Printf("Invalid configuration: %v", problem)
+		}
+		log.Fatalf("Refusing to start with %d configuration problem(s)", len(problems))
 	}
 
 	// Create database connection
-	connStr := datastore.BuildDBConnStr(
+	connStr, connStrErr := datastore.BuildDBConnStr(
+		config.DatabaseType,
 		config.DatabasePassword,
 		config.DatabaseUser,
 		config.DatabaseName,
 		config.SSLMode,
 	)
+	if connStrErr != nil {
+		log.Fatalf("Invalid database configuration: %v", connStrErr)
+	}
 
 	dbConn, dbErr := datastore.NewDB(config.DatabaseType, connStr)
 	if dbErr != nil {
@@ -91,6 +161,55 @@ func main() {
 		log.Fatalf("Failed to create shop repository: %v", shopRepoErr)
 	}
 
+	// Create reward event repository
+	rewardEventRepo, rewardEventRepoErr := datastore.NewRewardEventDatabase(dbConn)
+	if rewardEventRepoErr != nil {
+		log.Fatalf("Failed to create reward event repository: %v", rewardEventRepoErr)
+	}
+
+	// Create trade repository
+	tradeRepo, tradeRepoErr := datastore.NewTradeDatabase(dbConn)
+	if tradeRepoErr != nil {
+		log.Fatalf("Failed to create trade repository: %v", tradeRepoErr)
+	}
+
+	inviteCodeRepo, inviteCodeRepoErr := datastore.NewInviteCodeDatabase(dbConn)
+	if inviteCodeRepoErr != nil {
+		log.Fatalf("Failed to create invite code repository: %v", inviteCodeRepoErr)
+	}
+
+	curatedColorRepo, curatedColorRepoErr := datastore.NewCuratedColorDatabase(dbConn)
+	if curatedColorRepoErr != nil {
+		log.Fatalf("Failed to create curated color repository: %v", curatedColorRepoErr)
+	}
+
+	auditLogRepo, auditLogRepoErr := datastore.NewAuditLogDatabase(dbConn)
+	if auditLogRepoErr != nil {
+		log.Fatalf("Failed to create audit log repository: %v", auditLogRepoErr)
+	}
+
+	settingsRepo, settingsRepoErr := datastore.NewSettingsDatabase(dbConn)
+	if settingsRepoErr != nil {
+		log.Fatalf("Failed to create settings repository: %v", settingsRepoErr)
+	}
+
+	// Create external color API client
+	colorAPI, colorAPIErr := colorapi.NewClient(config.ColorAPIBaseURL, config.ColorSchemeMode, config.ColorSchemeCount)
+	if colorAPIErr != nil {
+		log.Fatalf("Failed to configure color API client: %v", colorAPIErr)
+	}
+	if err := colorAPI.SetRotation(config.ColorSchemeRotation); err != nil {
+		log.Fatalf("Failed to configure color API client: %v", err)
+	}
+
+	// Create scheduler for daily color generation
+	colorScheduler := scheduler.NewScheduler(dailyColorRepo, dailyScoreRepo, userRepo, colorAPI, config.ScoreRetentionDays)
+	colorScheduler.ColorCandidates = config.ColorCandidates
+	colorScheduler.DeterministicColors = config.DeterministicDailyColor
+	colorScheduler.LeaderboardRepo = dailyLeaderboardRepo
+	colorScheduler.LeaderboardFinalizeDelay = time.Duration(config.ScoreGraceMinutes) * time.Minute
+	colorScheduler.CuratedColorRepo = curatedColorRepo
+
 	// Create application
 	app := &api.Application{
 		Config:               config,
@@ -100,10 +219,17 @@ func main() {
 		DailyLeaderboardRepo: dailyLeaderboardRepo,
 		ShopRepo:             shopRepo,
 		FriendRepo:           friendRepo,
+		RewardEventRepo:      rewardEventRepo,
+		TradeRepo:            tradeRepo,
+		InviteCodeRepo:       inviteCodeRepo,
+		CuratedColorRepo:     curatedColorRepo,
+		AuditLogRepo:         auditLogRepo,
+		SettingsRepo:         settingsRepo,
+		Scheduler:            colorScheduler,
+		ColorAPI:             colorAPI,
 	}
 
 	// Start scheduler for daily color generation
-	colorScheduler := scheduler.NewScheduler(dailyColorRepo)
 	colorScheduler.Start()
 
 	// Create and start server
@@ -135,6 +261,18 @@ func getEnvInt(key string, defaultValue int) int {
 	return intVal
 }
 
+func getEnvFloat(key string, defaultValue float64) float64 {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	floatVal, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return defaultValue
+	}
+	return floatVal
+}
+
 func getEnvBool(key string, defaultValue bool) bool {
 	value := os.Getenv(key)
 	if value == "" {
@@ -147,6 +285,36 @@ func getEnvBool(key string, defaultValue bool) bool {
 	return boolVal
 }
 
+// getEnvIntSlice parses a comma-separated list of integers, returning nil when unset or malformed
+func getEnvIntSlice(key string) []int {
+	value := os.Getenv(key)
+	if value == "" {
+		return nil
+	}
+
+	var ints []int
+	for _, part := range strings.Split(value, ",") {
+		intVal, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			log.Printf("Ignoring malformed %s: %v", key, err)
+			return nil
+		}
+		ints = append(ints, intVal)
+	}
+	return ints
+}
+
+// getEnvList splits a comma-separated value into trimmed, non-empty entries, returning nil when unset
+func getEnvList(key string) []string {
+	var list []string
+	for _, part := range strings.Split(os.Getenv(key), ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			list = append(list, part)
+		}
+	}
+	return list
+}
+
 func getEnvSlice(key, defaultValue string) []string {
 	value := os.Getenv(key)
 	if value == "" {
@@ -0,0 +1,17 @@
+-- Migration: Link each daily score to the daily color it was scored against
+-- Lets us detect attempts that were scored against a color that has since been regenerated
+
+ALTER TABLE daily_scores
+    ADD COLUMN IF NOT EXISTS daily_color_id INTEGER REFERENCES daily_color(id) ON DELETE SET NULL;
+
+-- Backfill existing attempts with the color stored for their date when the target still matches
+UPDATE daily_scores ds
+SET daily_color_id = dc.id
+FROM daily_color dc
+WHERE ds.daily_color_id IS NULL
+    AND dc.date = ds.date
+    AND dc.r = ds.target_color_r
+    AND dc.g = ds.target_color_g
+    AND dc.b = ds.target_color_b;
+
+CREATE INDEX IF NOT EXISTS idx_daily_scores_daily_color_id ON daily_scores(daily_color_id);
@@ -0,0 +1,5 @@
+-- Migration: Track where each daily color came from
+-- Lets admins confirm whether the scheduler or a manual generation produced the day's color
+
+ALTER TABLE daily_color
+    ADD COLUMN IF NOT EXISTS source VARCHAR(50) NOT NULL DEFAULT 'external_api';
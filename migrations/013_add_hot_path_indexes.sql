@@ -0,0 +1,53 @@
+-- Migration: Add composite and partial indexes for hot query paths
+--
+-- Expected EXPLAIN plan changes once tables are large enough for the planner to prefer indexes:
+--
+-- daily_leaderboard ranking (GetLeaderboardByDate / GetUserRankByDate)
+--   before: Index Scan on idx_daily_leaderboard_date_score -> Incremental Sort on
+--           (best_score DESC, attempts_used, created_at) -> WindowAgg
+--   after:  Index Scan on idx_daily_leaderboard_ranking -> WindowAgg (no sort node)
+--
+-- daily_scores best attempt per color (GetUserBestScoreForColor)
+--   before: Bitmap Heap Scan on idx_daily_scores_user_date -> Filter daily_color_id -> Sort
+--   after:  Index Scan on idx_daily_scores_user_date_color_score -> Limit
+--
+-- daily_scores all attempts for a date (GetAllScoresByDate)
+--   before: Bitmap Heap Scan on idx_daily_scores_date -> Sort (score DESC, created_at)
+--   after:  Index Scan on idx_daily_scores_date_score
+--
+-- friendships lookups (GetFriendshipBetween, ListFriends, GetFriendActivities)
+--   before: BitmapOr of idx_friendships_requester_status / idx_friendships_addressee_status
+--           with a recheck on the other user id
+--   after:  BitmapOr of the pair indexes below, or the partial accepted-only indexes
+--           when filtering on status = 'accepted'
+--
+-- purchase_history per user (GetUserPurchaseHistory)
+--   before: Bitmap Heap Scan on idx_purchase_history_user_id -> Sort (purchased_at DESC)
+--   after:  Index Scan on idx_purchase_history_user_purchased_at
+
+CREATE INDEX IF NOT EXISTS idx_daily_leaderboard_ranking
+    ON daily_leaderboard (date, best_score DESC, attempts_used ASC, created_at ASC);
+
+CREATE INDEX IF NOT EXISTS idx_daily_scores_user_date_color_score
+    ON daily_scores (user_id, date, daily_color_id, score DESC, attempt_number ASC);
+
+CREATE INDEX IF NOT EXISTS idx_daily_scores_date_score
+    ON daily_scores (date, score DESC, created_at ASC);
+
+CREATE INDEX IF NOT EXISTS idx_friendships_requester_addressee_status
+    ON friendships (requester_id, addressee_id, status);
+
+CREATE INDEX IF NOT EXISTS idx_friendships_addressee_requester_status
+    ON friendships (addressee_id, requester_id, status);
+
+CREATE INDEX IF NOT EXISTS idx_friendships_requester_accepted
+    ON friendships (requester_id) WHERE status = 'accepted';
+
+CREATE INDEX IF NOT EXISTS idx_friendships_addressee_accepted
+    ON friendships (addressee_id) WHERE status = 'accepted';
+
+CREATE INDEX IF NOT EXISTS idx_purchase_history_user_purchased_at
+    ON purchase_history (user_id, purchased_at DESC);
+
+CREATE INDEX IF NOT EXISTS idx_user_inventory_user_equipped
+    ON user_inventory (user_id) WHERE is_equipped = true;
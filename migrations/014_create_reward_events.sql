@@ -0,0 +1,15 @@
+-- Migration: Create reward_events table
+-- Time-boxed events that multiply the points and credits earned when a day is finalized
+
+CREATE TABLE IF NOT EXISTS reward_events (
+    event_id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    multiplier NUMERIC(6, 2) NOT NULL CHECK (multiplier > 0),
+    starts_on DATE NOT NULL,
+    ends_on DATE NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CHECK (ends_on >= starts_on)
+);
+
+CREATE INDEX IF NOT EXISTS idx_reward_events_window ON reward_events(starts_on, ends_on);
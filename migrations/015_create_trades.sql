@@ -0,0 +1,27 @@
+-- Migration: Create player-to-player trades
+-- A trade is proposed by one player and accepted or declined by the other.
+-- Ownership is re-checked when the trade is accepted, not only when proposed.
+
+CREATE TABLE IF NOT EXISTS trades (
+    trade_id SERIAL PRIMARY KEY,
+    proposer_id VARCHAR(255) NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    recipient_id VARCHAR(255) NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    offered_item_id VARCHAR(255) REFERENCES shop_items(item_id) ON DELETE CASCADE,
+    offered_quantity INTEGER NOT NULL DEFAULT 0,
+    offered_credits INTEGER NOT NULL DEFAULT 0,
+    requested_item_id VARCHAR(255) REFERENCES shop_items(item_id) ON DELETE CASCADE,
+    requested_quantity INTEGER NOT NULL DEFAULT 0,
+    requested_credits INTEGER NOT NULL DEFAULT 0,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending', -- 'pending', 'accepted', 'declined', 'cancelled'
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    responded_at TIMESTAMP,
+    CHECK (proposer_id <> recipient_id),
+    CHECK (offered_quantity >= 0 AND offered_credits >= 0),
+    CHECK (requested_quantity >= 0 AND requested_credits >= 0),
+    -- Each side must put something on the table
+    CHECK (offered_item_id IS NOT NULL OR offered_credits > 0),
+    CHECK (requested_item_id IS NOT NULL OR requested_credits > 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_trades_proposer_status ON trades(proposer_id, status);
+CREATE INDEX IF NOT EXISTS idx_trades_recipient_status ON trades(recipient_id, status);
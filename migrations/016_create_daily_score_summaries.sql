@@ -0,0 +1,15 @@
+-- Migration: Create daily_score_summaries table
+-- Raw daily_scores attempts older than the retention window are rolled up here
+-- (one row per user per day) and then deleted. daily_leaderboard is left untouched.
+
+CREATE TABLE IF NOT EXISTS daily_score_summaries (
+    id SERIAL PRIMARY KEY,
+    user_id VARCHAR(255) NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    date DATE NOT NULL,
+    best_score INTEGER NOT NULL CHECK (best_score >= 0 AND best_score <= 100),
+    attempts_used INTEGER NOT NULL CHECK (attempts_used >= 1),
+    archived_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE(user_id, date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_daily_score_summaries_date ON daily_score_summaries(date);
@@ -0,0 +1,13 @@
+-- Migration: Add trigram index for case-insensitive username search
+-- Requires the pg_trgm extension. Creating it needs a role with CREATE privilege on the
+-- database (or a superuser on PostgreSQL < 13); run CREATE EXTENSION pg_trgm manually if the app role lacks it.
+--
+-- Expected plan for SearchUsersForFriend (LOWER(username) LIKE '%term%'):
+--   before: Seq Scan on users, Filter: lower(username) ~~ '%term%'
+--   after:  Bitmap Heap Scan on users -> Bitmap Index Scan on idx_users_username_trgm
+-- Terms shorter than 3 characters produce no trigrams and still fall back to a scan.
+
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS idx_users_username_trgm
+    ON users USING GIN (LOWER(username) gin_trgm_ops);
@@ -0,0 +1,5 @@
+-- Migration: Track purchase status so admins can refund purchases
+-- Existing purchases are all completed; refunded purchases keep their row for auditing
+
+ALTER TABLE purchase_history ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'completed';
+ALTER TABLE purchase_history ADD COLUMN IF NOT EXISTS refunded_at TIMESTAMP;
@@ -0,0 +1,5 @@
+-- Migration: Record which color harmony mode each daily color's palette uses
+-- Existing colors were generated with the default analogic scheme
+
+ALTER TABLE daily_color
+    ADD COLUMN IF NOT EXISTS scheme_mode VARCHAR(50) NOT NULL DEFAULT 'analogic';
@@ -0,0 +1,13 @@
+-- Migration: Create invite_codes for invite-only signup
+-- A code is consumed by exactly one signup; used_by/used_at are set in the same transaction that creates the user
+
+CREATE TABLE IF NOT EXISTS invite_codes (
+    code VARCHAR(32) PRIMARY KEY,
+    created_by VARCHAR(255) REFERENCES users(user_id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP,
+    used_by VARCHAR(255) REFERENCES users(user_id) ON DELETE SET NULL,
+    used_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_invite_codes_unused ON invite_codes(created_at) WHERE used_at IS NULL;
@@ -0,0 +1,23 @@
+-- Migration: Store a difficulty label with each daily color
+-- Mirrors models.ClassifyColorDifficulty so colors generated before this migration get the same label
+
+ALTER TABLE daily_color
+    ADD COLUMN IF NOT EXISTS difficulty VARCHAR(16) NOT NULL DEFAULT 'medium';
+
+WITH hsl AS (
+    SELECT id,
+        (GREATEST(r, g, b) + LEAST(r, g, b)) / 510.0 AS lightness,
+        CASE WHEN GREATEST(r, g, b) = LEAST(r, g, b) THEN 0
+            ELSE (GREATEST(r, g, b) - LEAST(r, g, b)) / 255.0
+                / (1 - ABS((GREATEST(r, g, b) + LEAST(r, g, b)) / 255.0 - 1))
+        END AS saturation
+    FROM daily_color
+)
+UPDATE daily_color dc
+SET difficulty = CASE
+    WHEN hsl.saturation < 0.25 AND hsl.lightness BETWEEN 0.2 AND 0.8 THEN 'hard'
+    WHEN hsl.saturation >= 0.6 AND hsl.lightness BETWEEN 0.3 AND 0.7 THEN 'easy'
+    ELSE 'medium'
+END
+FROM hsl
+WHERE dc.id = hsl.id;
@@ -0,0 +1,5 @@
+-- Migration: Add an optional profile avatar to users
+-- Empty means the user has not set one; clients fall back to their own default
+
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS avatar_url VARCHAR(2048) NOT NULL DEFAULT '';
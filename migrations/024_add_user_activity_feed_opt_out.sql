@@ -0,0 +1,4 @@
+-- Migration: Let users keep their scores out of the public recent activity feed
+
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS hide_from_activity_feed BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,6 @@
+-- Migration: Let admins feature shop items for merchandising
+
+ALTER TABLE shop_items
+    ADD COLUMN IF NOT EXISTS is_featured BOOLEAN NOT NULL DEFAULT false;
+
+CREATE INDEX IF NOT EXISTS idx_shop_items_featured ON shop_items(is_featured) WHERE is_featured;
@@ -0,0 +1,21 @@
+-- Migration: Create daily leaderboard snapshots
+-- At rollover the scheduler copies the finished day's daily_leaderboard, ranks included, into
+-- daily_leaderboard_snapshots and records the day in daily_leaderboard_finals. Past days are served
+-- from the snapshot, so later edits to daily_leaderboard can't change a day's final standings.
+
+CREATE TABLE IF NOT EXISTS daily_leaderboard_snapshots (
+    date DATE NOT NULL,
+    rank INTEGER NOT NULL,
+    user_id VARCHAR(255) NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    best_score INTEGER NOT NULL,
+    attempts_used INTEGER NOT NULL,
+    PRIMARY KEY (date, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_daily_leaderboard_snapshots_date_rank ON daily_leaderboard_snapshots(date, rank);
+
+CREATE TABLE IF NOT EXISTS daily_leaderboard_finals (
+    date DATE PRIMARY KEY,
+    entry_count INTEGER NOT NULL,
+    finalized_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,18 @@
+-- Migration: Create curated_colors table
+-- Content designers queue hand-picked daily colors here. The scheduler uses the entry scheduled for
+-- the day if there is one, otherwise the oldest unscheduled entry, and falls back to a random color
+-- once the queue is empty. used_on records the day an entry became the daily color.
+
+CREATE TABLE IF NOT EXISTS curated_colors (
+    id SERIAL PRIMARY KEY,
+    color_name VARCHAR(255) NOT NULL,
+    r INTEGER NOT NULL CHECK (r >= 0 AND r <= 255),
+    g INTEGER NOT NULL CHECK (g >= 0 AND g <= 255),
+    b INTEGER NOT NULL CHECK (b >= 0 AND b <= 255),
+    scheduled_for DATE UNIQUE,
+    used_on DATE,
+    created_by VARCHAR(255) REFERENCES users(user_id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_curated_colors_pending ON curated_colors(id) WHERE used_on IS NULL;
@@ -0,0 +1,15 @@
+-- Migration: Create admin_audit_log table
+-- Records sensitive admin actions such as impersonating a user. Rows are never updated or deleted
+-- by the API; admin_id and target_user_id survive their users being deleted.
+
+CREATE TABLE IF NOT EXISTS admin_audit_log (
+    id SERIAL PRIMARY KEY,
+    admin_id VARCHAR(255) NOT NULL,
+    action VARCHAR(100) NOT NULL,
+    target_user_id VARCHAR(255),
+    details JSONB,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_admin_audit_log_admin ON admin_audit_log(admin_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_admin_audit_log_target ON admin_audit_log(target_user_id, created_at);
@@ -0,0 +1,10 @@
+-- Migration: Create app_settings table
+-- Operator settings that can change while the server runs, shared by every instance. Values are
+-- stored as text and parsed by the API; a missing row means the environment default applies.
+
+CREATE TABLE IF NOT EXISTS app_settings (
+    key VARCHAR(100) PRIMARY KEY,
+    value TEXT NOT NULL,
+    updated_by VARCHAR(255),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
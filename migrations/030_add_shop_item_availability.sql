@@ -0,0 +1,12 @@
+-- Migration: Add availability windows to shop items
+-- An active item is only listed and purchasable from available_from until available_until.
+-- Either bound may be NULL for a window that is open at that end. The bounds are instants, compared
+-- against NOW() in both listings and purchases, so they don't depend on any server's time zone.
+
+ALTER TABLE shop_items
+    ADD COLUMN IF NOT EXISTS available_from TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS available_until TIMESTAMPTZ;
+
+ALTER TABLE shop_items
+    ADD CONSTRAINT shop_items_availability_window_check
+    CHECK (available_from IS NULL OR available_until IS NULL OR available_until > available_from);
@@ -0,0 +1,6 @@
+-- Migration: Remember which shop items were hidden by selling out
+-- A purchase that takes the last of an item's stock deactivates it. The flag marks that deactivation
+-- so a refund restoring stock can list the item again without undoing an admin's deactivation.
+
+ALTER TABLE shop_items
+    ADD COLUMN IF NOT EXISTS deactivated_by_stock BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Audited admin actions
+const (
+	AuditActionImpersonate   = "impersonate"
+	AuditActionUpdateSetting = "update_setting"
+	AuditActionDeleteSetting = "delete_setting"
+	AuditActionRepairLevels  = "repair_levels"
+)
+
+// AuditEntry records one sensitive action taken by an admin
+type AuditEntry struct {
+	ID           int             `json:"id"`
+	AdminID      string          `json:"adminId"`
+	Action       string          `json:"action"`
+	TargetUserID *string         `json:"targetUserId,omitempty"`
+	Details      json.RawMessage `json:"details,omitempty"`
+	CreatedAt    time.Time       `json:"createdAt"`
+}
+
+// ImpersonationRequest is an admin request for a token acting as another user
+type ImpersonationRequest struct {
+	UserID string `json:"userId"`
+	Reason string `json:"reason"`
+}
+
+// ImpersonationResponse carries a short-lived, read-only access token for the impersonated user
+type ImpersonationResponse struct {
+	AccessToken    string    `json:"accessToken"`
+	ExpiresAt      time.Time `json:"expiresAt"`
+	UserID         string    `json:"userId"`
+	ImpersonatedBy string    `json:"impersonatedBy"`
+	AuditID        int       `json:"auditId"`
+}
@@ -1,22 +1,151 @@
 package models
 
-import "time"
+import (
+	"crypto/sha256"
+	"fmt"
+	"time"
+)
+
+// Daily color difficulty labels
+const (
+	DifficultyEasy   = "easy"
+	DifficultyMedium = "medium"
+	DifficultyHard   = "hard"
+)
+
+// Daily color sources
+const (
+	DailyColorSourceExternalAPI = "external_api"
+	DailyColorSourceCurated     = "curated"
+	// Derived from the date alone, without calling the color API
+	DailyColorSourceDeterministic = "deterministic"
+)
 
 // DailyColor represents a color of the day for the game
 type DailyColor struct {
-	ID        int       `json:"id"`
-	Date      time.Time `json:"date"`
-	ColorName string    `json:"color_name"`
-	R         int       `json:"r"`
-	G         int       `json:"g"`
-	B         int       `json:"b"`
-	CreatedAt time.Time `json:"created_at"`
+	ID         int       `json:"id"`
+	Date       time.Time `json:"date"`
+	ColorName  string    `json:"color_name"`
+	R          int       `json:"r"`
+	G          int       `json:"g"`
+	B          int       `json:"b"`
+	Source     string    `json:"source"`
+	SchemeMode string    `json:"scheme_mode"`
+	Difficulty string    `json:"difficulty"`
+	CreatedAt  time.Time `json:"created_at"`
 }
 
 // DailyColorResponse is the simplified response for API endpoints
 type DailyColorResponse struct {
-	Date      string `json:"date"`
+	Date       string `json:"date"`
+	ColorName  string `json:"color_name"`
+	RGB        string `json:"rgb"`
+	Hex        string `json:"hex"`
+	SchemeMode string `json:"scheme_mode,omitempty"`
+	Difficulty string `json:"difficulty,omitempty"`
+}
+
+// DailyColorStatus reports whether today's color exists and when the scheduler runs next
+type DailyColorStatus struct {
+	Date             string     `json:"date"`
+	Exists           bool       `json:"exists"`
+	ColorName        string     `json:"color_name,omitempty"`
+	Source           string     `json:"source,omitempty"`
+	CreatedAt        *time.Time `json:"created_at,omitempty"`
+	NextGenerationAt *time.Time `json:"next_generation_at,omitempty"`
+	LastRunAt        *time.Time `json:"last_run_at,omitempty"`
+	LastRunError     string     `json:"last_run_error,omitempty"`
+}
+
+// DailyColorBackfillRequest is an admin request to fill in missing daily colors for a date range
+type DailyColorBackfillRequest struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// DailyColorBackfillResult summarises a backfill. Failed maps each date that couldn't be filled to its error.
+type DailyColorBackfillResult struct {
+	Filled  []string          `json:"filled"`
+	Skipped []string          `json:"skipped"`
+	Failed  map[string]string `json:"failed,omitempty"`
+}
+
+// ClassifyColorDifficulty labels how hard a color is to match, from its HSL saturation and lightness.
+// Muted mid tones (greys, taupes, dusty shades) are hard because the hue barely shows; vivid colors of
+// middling lightness are easy; everything else is medium. The label never affects scoring.
+func ClassifyColorDifficulty(r, g, b int) string {
+	max, min := r, r
+	for _, c := range []int{g, b} {
+		if c > max {
+			max = c
+		}
+		if c < min {
+			min = c
+		}
+	}
+
+	lightness := float64(max+min) / 510
+	saturation := 0.0
+	if max != min {
+		saturation = float64(max-min) / 255 / (1 - abs(float64(max+min)/255-1))
+	}
+
+	switch {
+	case saturation < 0.25 && lightness >= 0.2 && lightness <= 0.8:
+		return DifficultyHard
+	case saturation >= 0.6 && lightness >= 0.3 && lightness <= 0.7:
+		return DifficultyEasy
+	default:
+		return DifficultyMedium
+	}
+}
+
+// DeterministicDailyColor derives a day's color from its date alone: the first three bytes of the SHA-256
+// of the date in YYYY-MM-DD form become R, G and B. Every server, in any time zone, gets the same color
+// for the same calendar date. The color is named by its hex code, since there is no color API to name it.
+func DeterministicDailyColor(date time.Time) DailyColor {
+	day := date.Format("2006-01-02")
+	sum := sha256.Sum256([]byte(day))
+	r, g, b := int(sum[0]), int(sum[1]), int(sum[2])
+
+	return DailyColor{
+		Date:       date,
+		ColorName:  fmt.Sprintf("#%02X%02X%02X", r, g, b),
+		R:          r,
+		G:          g,
+		B:          b,
+		Source:     DailyColorSourceDeterministic,
+		Difficulty: ClassifyColorDifficulty(r, g, b),
+		CreatedAt:  time.Now(),
+	}
+}
+
+func abs(x float64) float64 {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// CuratedColor is a hand-picked color waiting to become a daily color. Entries with ScheduledFor are
+// used on that day; the rest are used in the order they were queued.
+type CuratedColor struct {
+	ID           int        `json:"id"`
+	ColorName    string     `json:"color_name"`
+	R            int        `json:"r"`
+	G            int        `json:"g"`
+	B            int        `json:"b"`
+	ScheduledFor *time.Time `json:"scheduled_for,omitempty"`
+	UsedOn       *time.Time `json:"used_on,omitempty"`
+	CreatedBy    *string    `json:"created_by,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// CuratedColorRequest is an admin request to queue a curated color, optionally for a specific date
+type CuratedColorRequest struct {
 	ColorName string `json:"color_name"`
-	RGB       string `json:"rgb"`
-	Hex       string `json:"hex"`
+	R         int    `json:"r"`
+	G         int    `json:"g"`
+	B         int    `json:"b"`
+	Date      string `json:"date,omitempty"` // YYYY-MM-DD; omit to append to the queue
 }
@@ -0,0 +1,86 @@
+package models
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestClassifyColorDifficulty(t *testing.T) {
+	tests := []struct {
+		name    string
+		r, g, b int
+		want    string
+	}{
+		{"mid grey is hard", 128, 128, 128, DifficultyHard},
+		{"muted taupe is hard", 72, 60, 50, DifficultyHard},
+		{"grey at the dark edge of the hard band", 51, 51, 51, DifficultyHard},
+		{"grey just below the hard band", 50, 50, 50, DifficultyMedium},
+		{"black is medium", 0, 0, 0, DifficultyMedium},
+		{"white is medium", 255, 255, 255, DifficultyMedium},
+		{"pure red is easy", 255, 0, 0, DifficultyEasy},
+		{"vivid teal is easy", 0, 160, 160, DifficultyEasy},
+		{"dark teal is medium", 0, 128, 128, DifficultyMedium},
+		{"deep navy is medium", 0, 0, 80, DifficultyMedium},
+		{"pale pink is medium", 255, 200, 200, DifficultyMedium},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ClassifyColorDifficulty(tt.r, tt.g, tt.b); got != tt.want {
+				t.Errorf("ClassifyColorDifficulty(%d, %d, %d) = %q, want %q", tt.r, tt.g, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeterministicDailyColor(t *testing.T) {
+	day := time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)
+
+	first := DeterministicDailyColor(day)
+	if first.Source != DailyColorSourceDeterministic {
+		t.Errorf("source = %q, want %q", first.Source, DailyColorSourceDeterministic)
+	}
+	for _, c := range []int{first.R, first.G, first.B} {
+		if c < 0 || c > 255 {
+			t.Errorf("channel %d is out of range in %+v", c, first)
+		}
+	}
+	if want := ClassifyColorDifficulty(first.R, first.G, first.B); first.Difficulty != want {
+		t.Errorf("difficulty = %q, want %q", first.Difficulty, want)
+	}
+
+	sameDay := []struct {
+		name string
+		date time.Time
+	}{
+		{"the same date again", day},
+		{"later the same day", day.Add(23*time.Hour + 59*time.Minute)},
+		{"the same calendar date in another zone", time.Date(2026, 10, 15, 0, 0, 0, 0, time.FixedZone("UTC-7", -7*60*60))},
+	}
+	for _, tt := range sameDay {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DeterministicDailyColor(tt.date)
+			if got.R != first.R || got.G != first.G || got.B != first.B || got.ColorName != first.ColorName {
+				t.Errorf("got %s (%d, %d, %d), want %s (%d, %d, %d)", got.ColorName, got.R, got.G, got.B, first.ColorName, first.R, first.G, first.B)
+			}
+		})
+	}
+
+	t.Run("the name is the hex code", func(t *testing.T) {
+		want := fmt.Sprintf("#%02X%02X%02X", first.R, first.G, first.B)
+		if first.ColorName != want {
+			t.Errorf("name = %q, want %q", first.ColorName, want)
+		}
+	})
+
+	t.Run("a week of dates gets more than one color", func(t *testing.T) {
+		seen := map[string]bool{}
+		for i := 0; i < 7; i++ {
+			seen[DeterministicDailyColor(day.AddDate(0, 0, i)).ColorName] = true
+		}
+		if len(seen) < 2 {
+			t.Errorf("seven days produced only %d distinct colors", len(seen))
+		}
+	})
+}
@@ -6,6 +6,7 @@ import "time"
 type DailyScore struct {
 	ID              int       `json:"id"`
 	UserID          string    `json:"user_id"`
+	DailyColorID    *int      `json:"daily_color_id,omitempty"`
 	Date            time.Time `json:"date"`
 	AttemptNumber   int       `json:"attempt_number"`
 	Score           int       `json:"score"`
@@ -28,6 +29,16 @@ type DailyAttemptModifier struct {
 	UpdatedAt     time.Time `json:"updated_at"`
 }
 
+// DailyScoreSummary is one user's day rolled up from raw attempts once they pass the retention window
+type DailyScoreSummary struct {
+	ID           int       `json:"id"`
+	UserID       string    `json:"user_id"`
+	Date         time.Time `json:"date"`
+	BestScore    int       `json:"best_score"`
+	AttemptsUsed int       `json:"attempts_used"`
+	ArchivedAt   time.Time `json:"archived_at"`
+}
+
 // DailyLeaderboard represents a user's best score for a specific day
 type DailyLeaderboard struct {
 	ID           int       `json:"id"`
@@ -44,28 +55,74 @@ type ScoreSubmissionRequest struct {
 	SubmittedColorR int `json:"submitted_color_r"`
 	SubmittedColorG int `json:"submitted_color_g"`
 	SubmittedColorB int `json:"submitted_color_b"`
+	// Day the guess is for, YYYY-MM-DD. Omitted means today; yesterday is accepted during the grace window.
+	Date string `json:"date,omitempty"`
 }
 
 // ScoreSubmissionResponse represents the response after submitting a score
 type ScoreSubmissionResponse struct {
+	Date             string  `json:"date"`
+	Score            int     `json:"score"`
+	AttemptNumber    int     `json:"attempt_number"`
+	AttemptsLeft     int     `json:"attempts_left"`
+	MaxAttempts      int     `json:"max_attempts,omitempty"`
+	BestScore        int     `json:"best_score"`
+	IsNewBest        bool    `json:"is_new_best"`
+	SubmittedColor   string  `json:"submitted_color"`
+	TargetColor      string  `json:"target_color"`
+	Message          string  `json:"message"`
+	RewardMultiplier float64 `json:"reward_multiplier"`
+	RewardEvent      string  `json:"reward_event,omitempty"`
+	PointsAwarded    int     `json:"points_awarded,omitempty"`
+	CreditsAwarded   int     `json:"credits_awarded,omitempty"`
+}
+
+// ChannelDelta is how far one RGB channel of a guess was from the target. Delta is submitted minus
+// target, so a negative value means the guess was too dark in that channel.
+type ChannelDelta struct {
+	Channel   string `json:"channel"` // "r", "g" or "b"
+	Submitted int    `json:"submitted"`
+	Target    int    `json:"target"`
+	Delta     int    `json:"delta"`
+	AbsDelta  int    `json:"abs_delta"`
+}
+
+// AttemptBreakdown splits an attempt's error by channel. WorstChannel is the channel furthest from the
+// target, or empty for an exact match.
+type AttemptBreakdown struct {
+	AttemptID     int            `json:"attempt_id"`
+	Date          string         `json:"date"`
+	AttemptNumber int            `json:"attempt_number"`
+	Score         int            `json:"score"`
+	Channels      []ChannelDelta `json:"channels"`
+	WorstChannel  string         `json:"worst_channel,omitempty"`
+}
+
+// ScorePreviewResponse is a projected score that doesn't use an attempt. The target color is withheld.
+type ScorePreviewResponse struct {
 	Score          int    `json:"score"`
-	AttemptNumber  int    `json:"attempt_number"`
-	AttemptsLeft   int    `json:"attempts_left"`
-	MaxAttempts    int    `json:"max_attempts,omitempty"`
-	BestScore      int    `json:"best_score"`
-	IsNewBest      bool   `json:"is_new_best"`
 	SubmittedColor string `json:"submitted_color"`
-	TargetColor    string `json:"target_color"`
 	Message        string `json:"message"`
 }
 
 // LeaderboardEntry represents a single entry in the leaderboard
 type LeaderboardEntry struct {
-	Rank         int    `json:"rank"`
-	UserID       string `json:"user_id"`
-	Username     string `json:"username"`
-	BestScore    int    `json:"best_score"`
-	AttemptsUsed int    `json:"attempts_used"`
+	Rank         int                `json:"rank"`
+	UserID       string             `json:"user_id"`
+	Username     string             `json:"username"`
+	AvatarURL    string             `json:"avatar_url,omitempty"`
+	BestScore    int                `json:"best_score"`
+	AttemptsUsed int                `json:"attempts_used"`
+	Percentile   float64            `json:"percentile"` // share of the day's other players ranked below, 0-100
+	Cosmetics    []EquippedCosmetic `json:"cosmetics"`
+}
+
+// RecentScore is one entry in the public recent activity feed
+type RecentScore struct {
+	Username  string    `json:"username"`
+	AvatarURL string    `json:"avatar_url,omitempty"`
+	Score     int       `json:"score"`
+	ScoredAt  time.Time `json:"scored_at"`
 }
 
 // UserScoreHistory represents a user's score history for a specific day
@@ -78,3 +135,65 @@ type UserScoreHistory struct {
 	ExtraAttempts int          `json:"extra_attempts"`
 	MaxAttempts   int          `json:"max_attempts"`
 }
+
+// GameStatus tells a client whether the user can play today's game and how far through it they are
+type GameStatus struct {
+	Date                 string `json:"date"`
+	ColorAvailable       bool   `json:"color_available"`
+	CanPlay              bool   `json:"can_play"`
+	AttemptsUsed         int    `json:"attempts_used"`
+	AttemptsLeft         int    `json:"attempts_left"`
+	MaxAttempts          int    `json:"max_attempts"`
+	BestScore            int    `json:"best_score"`
+	HasPerfectScore      bool   `json:"has_perfect_score"`
+	SecondsUntilRollover int    `json:"seconds_until_rollover"`
+}
+
+// PersonalBest is a user's best daily score ever and where it ranked on its day
+type PersonalBest struct {
+	Date         string `json:"date"`
+	BestScore    int    `json:"best_score"`
+	AttemptsUsed int    `json:"attempts_used"`
+	Rank         int    `json:"rank"`
+	TotalPlayers int    `json:"total_players"`
+}
+
+// ScoreBucket counts the players whose best score today falls in [min_score, max_score]
+type ScoreBucket struct {
+	MinScore int `json:"min_score"`
+	MaxScore int `json:"max_score"`
+	Players  int `json:"players"`
+}
+
+// ScoreDistributionResponse is today's best-score histogram and where the caller sits in it.
+// Rank and percentile are omitted until the caller has a score today.
+type ScoreDistributionResponse struct {
+	Date         string        `json:"date"`
+	TotalPlayers int           `json:"total_players"`
+	Buckets      []ScoreBucket `json:"buckets"`
+	BestScore    *int          `json:"best_score,omitempty"`
+	Rank         *int          `json:"rank,omitempty"`
+	Percentile   *float64      `json:"percentile,omitempty"`
+}
+
+// GlobalStats are platform-wide totals for the public stats page
+type GlobalStats struct {
+	TotalUsers           int       `json:"total_users"`
+	GamesPlayed          int       `json:"games_played"` // one per player per day played
+	TotalAttempts        int       `json:"total_attempts"`
+	HighestScore         int       `json:"highest_score"`
+	MostCommonColor      string    `json:"most_common_color,omitempty"`
+	MostCommonColorCount int       `json:"most_common_color_count"`
+	ComputedAt           time.Time `json:"computed_at"`
+}
+
+// ScoringParameters describe how a guess is scored, so clients can reproduce the calculation locally.
+// score = round((1 - distance / max_distance) * max_score), clamped to [0, max_score], where distance
+// is the Euclidean distance between the two colors' RGB channels.
+type ScoringParameters struct {
+	Mode        string  `json:"mode"`  // how distance is measured, e.g. "euclidean_rgb"
+	Curve       string  `json:"curve"` // how distance maps to a score, e.g. "linear"
+	MaxDistance float64 `json:"max_distance"`
+	MaxScore    int     `json:"max_score"`
+	Formula     string  `json:"formula"`
+}
@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// ExportedFriendship is a friendship in a data export. Only the other user's ID and username are
+// included, since the rest of their profile isn't the exporting user's data.
+type ExportedFriendship struct {
+	FriendshipID int        `json:"friendshipId"`
+	UserID       string     `json:"userId"`
+	Username     string     `json:"username"`
+	Status       string     `json:"status"`
+	Direction    string     `json:"direction,omitempty"` // "incoming" or "outgoing" for pending requests
+	CreatedAt    time.Time  `json:"createdAt"`
+	RespondedAt  *time.Time `json:"respondedAt,omitempty"`
+}
+
+// UserDataExport is everything stored about a user, for data portability requests. Scores holds
+// the raw attempts still kept; older days survive only as ScoreSummaries.
+type UserDataExport struct {
+	ExportedAt     time.Time                `json:"exportedAt"`
+	Profile        User                     `json:"profile"`
+	Scores         []DailyScore             `json:"scores"`
+	ScoreSummaries []DailyScoreSummary      `json:"scoreSummaries"`
+	Leaderboard    []DailyLeaderboard       `json:"leaderboard"`
+	Activity       []FriendActivityRecord   `json:"activity"`
+	Purchases      []PurchaseRecordWithItem `json:"purchases"`
+	Inventory      []UserInventoryWithItem  `json:"inventory"`
+	Friendships    []ExportedFriendship     `json:"friendships"`
+	Devices        []UserDevice             `json:"devices"`
+}
@@ -18,6 +18,23 @@ type Friendship struct {
 	RespondedAt  *time.Time `json:"respondedAt,omitempty" db:"responded_at"`
 }
 
+// Per-target outcomes of a batch friend request
+const (
+	FriendRequestResultCreated   = "created"
+	FriendRequestResultDuplicate = "duplicate"
+	FriendRequestResultBlocked   = "blocked" // friend or daily request limit reached
+	FriendRequestResultNotFound  = "not-found"
+	FriendRequestResultInvalid   = "invalid" // e.g. the sender's own ID
+)
+
+// FriendRequestBatchResult is the outcome of one target in a batch friend request
+type FriendRequestBatchResult struct {
+	TargetUserID string      `json:"targetUserId"`
+	Result       string      `json:"result"`
+	Friendship   *Friendship `json:"friendship,omitempty"`
+	Reason       string      `json:"reason,omitempty"`
+}
+
 // FriendSummary represents an accepted friendship with the other user's summary
 type FriendSummary struct {
 	FriendshipID int         `json:"friendshipId"`
@@ -56,3 +73,47 @@ type FriendActivityEntry struct {
 	AttemptsUsed int    `json:"attemptsUsed"`
 	Date         string `json:"date"`
 }
+
+// FriendActivityRecord is one of a user's own days as recorded for their friends' activity feeds
+type FriendActivityRecord struct {
+	Date         time.Time `json:"date"`
+	BestScore    int       `json:"bestScore"`
+	AttemptsUsed int       `json:"attemptsUsed"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+// FriendDayEntry reports whether a friend has played on a given day and, if so, how they did
+type FriendDayEntry struct {
+	Friend       UserSummary `json:"friend"`
+	Played       bool        `json:"played"`
+	BestScore    *int        `json:"bestScore,omitempty"`
+	AttemptsUsed int         `json:"attemptsUsed"`
+}
+
+// FriendsTodayResponse groups the caller's friends by whether they have played today
+type FriendsTodayResponse struct {
+	Date        string           `json:"date"`
+	PlayedCount int              `json:"playedCount"`
+	FriendCount int              `json:"friendCount"`
+	Played      []FriendDayEntry `json:"played"`
+	NotPlayed   []FriendDayEntry `json:"notPlayed"`
+}
+
+// HeadToHeadSide is one player's half of a head-to-head record
+type HeadToHeadSide struct {
+	UserID       string  `json:"userId"`
+	Wins         int     `json:"wins"`
+	DaysPlayed   int     `json:"daysPlayed"`
+	AverageScore float64 `json:"averageScore"`
+}
+
+// HeadToHead compares two players' daily best scores over a date range. Wins and ties only count days
+// both of them played; averages cover every day each of them played.
+type HeadToHead struct {
+	From         string         `json:"from"`
+	To           string         `json:"to"`
+	User         HeadToHeadSide `json:"user"`
+	Opponent     HeadToHeadSide `json:"opponent"`
+	Ties         int            `json:"ties"`
+	DaysCompared int            `json:"daysCompared"`
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"time"
+)
+
+// InviteCode is a single-use code that lets someone sign up while signups are invite-only
+type InviteCode struct {
+	Code      string     `json:"code"`
+	CreatedBy *string    `json:"createdBy,omitempty"`
+	CreatedAt time.Time  `json:"createdAt"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+	UsedBy    *string    `json:"usedBy,omitempty"`
+	UsedAt    *time.Time `json:"usedAt,omitempty"`
+}
+
+// CreateInviteCodesRequest is an admin request to mint invite codes
+type CreateInviteCodesRequest struct {
+	Count         int `json:"count"`
+	ExpiresInDays int `json:"expiresInDays"` // 0 means the codes never expire
+}
+
+// NewInviteCode generates a random, human-typeable invite code
+func NewInviteCode() (string, error) {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return strings.ToUpper(hex.EncodeToString(b)), nil
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
 var JWT = struct {
@@ -22,21 +23,38 @@ type JWTClaims struct {
 	DeviceFingerprint string `json:"deviceFingerprint"`
 	Scope             string `json:"scope"`
 	TokenType         string `json:"tokenType"`
+	// Set on impersonation tokens to the admin acting as the user; such tokens are read-only
+	ImpersonatedBy string `json:"impersonatedBy,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// NewTokenID generates a unique jti for a token so it can be individually revoked
+func NewTokenID() string {
+	return uuid.New().String()
+}
+
 type JWTRefreshResponse struct {
 	Expiry  time.Time `json:"expiry"`
 	Refresh string    `json:"refresh"`
 }
 
-func ValidateJWTToken(tokenString string, secret string) (*JWTClaims, error) {
+// ValidateJWTToken parses a token and checks its signature, expiry, issuer and audience.
+// An empty issuer or audience skips that check.
+func ValidateJWTToken(tokenString string, secret string, issuer string, audience string) (*JWTClaims, error) {
+	var opts []jwt.ParserOption
+	if issuer != "" {
+		opts = append(opts, jwt.WithIssuer(issuer))
+	}
+	if audience != "" {
+		opts = append(opts, jwt.WithAudience(audience))
+	}
+
 	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
 		return []byte(secret), nil
-	})
+	}, opts...)
 
 	if err != nil || !token.Valid {
 		return nil, fmt.Errorf("invalid token")
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// Optional is a request field that tells apart being omitted, being sent as null and being sent
+// with a value, which a plain pointer can't: encoding/json leaves a pointer nil in both of the
+// first two cases. Set is false when omitted; Null is true for an explicit null.
+type Optional[T any] struct {
+	Set   bool
+	Null  bool
+	Value T
+}
+
+// UnmarshalJSON is only called when the field is present, which is what marks it as Set
+func (o *Optional[T]) UnmarshalJSON(data []byte) error {
+	o.Set = true
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		o.Null = true
+		return nil
+	}
+	return json.Unmarshal(data, &o.Value)
+}
+
+// MarshalJSON writes null unless a value was set
+func (o Optional[T]) MarshalJSON() ([]byte, error) {
+	if !o.Set || o.Null {
+		return []byte("null"), nil
+	}
+	return json.Marshal(o.Value)
+}
+
+// IsJSONNull reports whether raw is an explicit JSON null, as opposed to omitted or a value
+func IsJSONNull(raw json.RawMessage) bool {
+	return bytes.Equal(bytes.TrimSpace(raw), []byte("null"))
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// PageCursor marks where the previous page of a newest-first list ended: the sort time and the
+// unique ID of its last row, which breaks ties between rows sharing a timestamp
+type PageCursor struct {
+	At time.Time `json:"at"`
+	ID string    `json:"id"`
+}
+
+// PageMeta describes how to fetch the page after the current one. NextCursor is empty on the last page.
+type PageMeta struct {
+	Limit      int    `json:"limit"`
+	NextCursor string `json:"nextCursor,omitempty"`
+}
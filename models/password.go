@@ -0,0 +1,67 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MaxPasswordBytes is bcrypt's input limit; anything beyond it would be silently ignored
+const MaxPasswordBytes = 72
+
+// ErrPasswordTooLong rejects passwords bcrypt would otherwise truncate, so two long passwords
+// sharing their first 72 bytes can never hash the same
+var ErrPasswordTooLong = fmt.Errorf("password must be at most %d bytes", MaxPasswordBytes)
+
+// PasswordPolicy is the set of strength rules a new password must meet
+type PasswordPolicy struct {
+	MinLength        int // in characters, not bytes
+	RequireMixedCase bool
+	RequireDigit     bool
+	RequireSymbol    bool
+}
+
+// ValidatePassword checks password against policy, naming every rule it fails
+func ValidatePassword(password string, policy PasswordPolicy) error {
+	if password == "" {
+		return errors.New("password is required")
+	}
+
+	var problems []string
+	if utf8.RuneCountInString(password) < policy.MinLength {
+		problems = append(problems, fmt.Sprintf("must be at least %d characters", policy.MinLength))
+	}
+	if len(password) > MaxPasswordBytes {
+		problems = append(problems, fmt.Sprintf("must be at most %d bytes", MaxPasswordBytes))
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, char := range password {
+		switch {
+		case unicode.IsUpper(char):
+			hasUpper = true
+		case unicode.IsLower(char):
+			hasLower = true
+		case unicode.IsDigit(char):
+			hasDigit = true
+		case !unicode.IsLetter(char) && !unicode.IsSpace(char):
+			hasSymbol = true
+		}
+	}
+	if policy.RequireMixedCase && !(hasUpper && hasLower) {
+		problems = append(problems, "must contain both upper and lower case letters")
+	}
+	if policy.RequireDigit && !hasDigit {
+		problems = append(problems, "must contain a digit")
+	}
+	if policy.RequireSymbol && !hasSymbol {
+		problems = append(problems, "must contain a symbol")
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("password %s", strings.Join(problems, ", "))
+	}
+	return nil
+}
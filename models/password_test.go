@@ -0,0 +1,47 @@
+package models
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidatePassword(t *testing.T) {
+	lengthOnly := PasswordPolicy{MinLength: 8}
+	strict := PasswordPolicy{MinLength: 10, RequireMixedCase: true, RequireDigit: true, RequireSymbol: true}
+
+	tests := []struct {
+		name     string
+		password string
+		policy   PasswordPolicy
+		wantErr  string // empty when the password should pass
+	}{
+		{"empty password", "", lengthOnly, "password is required"},
+		{"long enough", "correcthorse", lengthOnly, ""},
+		{"exactly the minimum", "abcdefgh", lengthOnly, ""},
+		{"too short", "abc", lengthOnly, "password must be at least 8 characters"},
+		{"length counts characters, not bytes", "ééééééé", lengthOnly, "password must be at least 8 characters"},
+		{"multi-byte characters reach the minimum", "éééééééé", lengthOnly, ""},
+		{"at the bcrypt limit", strings.Repeat("a", MaxPasswordBytes), lengthOnly, ""},
+		{"past the bcrypt limit", strings.Repeat("a", MaxPasswordBytes+1), lengthOnly, "password must be at most 72 bytes"},
+		{"meets every rule", "Correct-Horse-9", strict, ""},
+		{"missing upper case", "correct-horse-9", strict, "password must contain both upper and lower case letters"},
+		{"missing a digit", "Correct-Horse", strict, "password must contain a digit"},
+		{"missing a symbol", "CorrectHorse9", strict, "password must contain a symbol"},
+		{"every failure is named", "abc", strict, "password must be at least 10 characters, must contain both upper and lower case letters, must contain a digit, must contain a symbol"},
+		{"spaces don't count as symbols", "Correct Horse 9", strict, "password must contain a symbol"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidatePassword(tt.password, tt.policy)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("ValidatePassword(%q) = %v, want no error", tt.password, err)
+			case tt.wantErr != "" && err == nil:
+				t.Errorf("ValidatePassword(%q) passed, want %q", tt.password, tt.wantErr)
+			case tt.wantErr != "" && err.Error() != tt.wantErr:
+				t.Errorf("ValidatePassword(%q) = %q, want %q", tt.password, err.Error(), tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// DefaultRewardMultiplier applies when no reward event is active
+const DefaultRewardMultiplier = 1.0
+
+// RewardEvent boosts the points and credits earned on every day within its window
+type RewardEvent struct {
+	EventID    int       `json:"eventId" db:"event_id"`
+	Name       string    `json:"name" db:"name"`
+	Multiplier float64   `json:"multiplier" db:"multiplier"`
+	StartsOn   time.Time `json:"startsOn" db:"starts_on"`
+	EndsOn     time.Time `json:"endsOn" db:"ends_on"`
+	CreatedAt  time.Time `json:"createdAt" db:"created_at"`
+	UpdatedAt  time.Time `json:"updatedAt" db:"updated_at"`
+}
+
+// CreateRewardEventRequest represents the request to create a reward event
+type CreateRewardEventRequest struct {
+	Name       string  `json:"name"`
+	Multiplier float64 `json:"multiplier"`
+	StartsOn   string  `json:"startsOn"` // YYYY-MM-DD
+	EndsOn     string  `json:"endsOn"`   // YYYY-MM-DD
+}
+
+// UpdateRewardEventRequest represents the request to update a reward event
+type UpdateRewardEventRequest struct {
+	Name       *string  `json:"name,omitempty"`
+	Multiplier *float64 `json:"multiplier,omitempty"`
+	StartsOn   *string  `json:"startsOn,omitempty"`
+	EndsOn     *string  `json:"endsOn,omitempty"`
+}
@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// Keys of runtime settings stored in app_settings
+const (
+	SettingMaintenanceMode      = "maintenance_mode"
+	SettingBaseDailyAttempts    = "base_daily_attempts"
+	SettingPointsPerScorePoint  = "points_per_score_point"
+	SettingCreditsPerScorePoint = "credits_per_score_point"
+)
+
+// Setting is one runtime setting, stored as text
+type Setting struct {
+	Key       string    `json:"key"`
+	Value     string    `json:"value"`
+	UpdatedBy *string   `json:"updatedBy,omitempty"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// RuntimeSetting describes a runtime setting and its effective value for the admin settings API
+type RuntimeSetting struct {
+	Key         string     `json:"key"`
+	Type        string     `json:"type"` // "bool", "int" or "float"
+	Description string     `json:"description"`
+	Value       string     `json:"value"`
+	Default     string     `json:"default"`    // from the environment
+	Overridden  bool       `json:"overridden"` // Value was set at runtime rather than coming from Default
+	UpdatedBy   *string    `json:"updatedBy,omitempty"`
+	UpdatedAt   *time.Time `json:"updatedAt,omitempty"`
+}
+
+// MaintenanceStatus reports whether gameplay is paused
+type MaintenanceStatus struct {
+	Enabled   bool       `json:"enabled"`
+	UpdatedBy *string    `json:"updatedBy,omitempty"`
+	UpdatedAt *time.Time `json:"updatedAt,omitempty"` // unset while the MAINTENANCE_MODE default applies
+}
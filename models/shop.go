@@ -23,6 +23,27 @@ const (
 	RarityLegendary = "legendary"
 )
 
+// Rarities lists every rarity from most to least common
+var Rarities = []string{RarityCommon, RarityRare, RarityEpic, RarityLegendary}
+
+// IsValidRarity reports whether rarity is one of Rarities
+func IsValidRarity(rarity string) bool {
+	for _, r := range Rarities {
+		if r == rarity {
+			return true
+		}
+	}
+	return false
+}
+
+// RarityTier groups the active shop items of one rarity. Share is the tier's percentage of all active items.
+type RarityTier struct {
+	Rarity string     `json:"rarity"`
+	Count  int        `json:"count"`
+	Share  float64    `json:"share"`
+	Items  []ShopItem `json:"items"`
+}
+
 // ShopItem represents an item available for purchase in the shop
 type ShopItem struct {
 	ItemID           string          `json:"itemId" db:"item_id"`
@@ -34,9 +55,23 @@ type ShopItem struct {
 	Metadata         json.RawMessage `json:"metadata" db:"metadata"`
 	IsActive         bool            `json:"isActive" db:"is_active"`
 	IsLimitedEdition bool            `json:"isLimitedEdition" db:"is_limited_edition"`
-	StockQuantity    *int            `json:"stockQuantity,omitempty" db:"stock_quantity"`
+	IsFeatured       bool            `json:"isFeatured" db:"is_featured"`
+	StockQuantity    *int            `json:"stockQuantity,omitempty" db:"stock_quantity"` // nil is unlimited, 0 is sold out; public responses show it only for limited editions
 	CreatedAt        time.Time       `json:"createdAt" db:"created_at"`
 	UpdatedAt        time.Time       `json:"updatedAt" db:"updated_at"`
+
+	// The item is listed and purchasable only inside this window; a nil bound leaves that end open
+	AvailableFrom  *time.Time `json:"availableFrom,omitempty" db:"available_from"`
+	AvailableUntil *time.Time `json:"availableUntil,omitempty" db:"available_until"`
+}
+
+// EquippedCosmetic is the slice of an equipped avatar item needed to render it next to a player
+type EquippedCosmetic struct {
+	ItemID   string          `json:"itemId"`
+	ItemType string          `json:"itemType"`
+	Name     string          `json:"name"`
+	Rarity   string          `json:"rarity"`
+	Metadata json.RawMessage `json:"metadata"`
 }
 
 // CreateShopItemRequest represents the request to create a new shop item
@@ -48,10 +83,17 @@ type CreateShopItemRequest struct {
 	Rarity           string          `json:"rarity"`
 	Metadata         json.RawMessage `json:"metadata"`
 	IsLimitedEdition bool            `json:"isLimitedEdition"`
+	IsFeatured       bool            `json:"isFeatured"`
 	StockQuantity    *int            `json:"stockQuantity,omitempty"`
+
+	AvailableFrom  *time.Time `json:"availableFrom,omitempty"`
+	AvailableUntil *time.Time `json:"availableUntil,omitempty"`
 }
 
-// UpdateShopItemRequest represents the request to update a shop item
+// UpdateShopItemRequest represents the request to update a shop item.
+// Omitted fields are left unchanged. An explicit null clears metadata, makes stockQuantity
+// unlimited and opens that end of the availability window; the other fields can't be cleared,
+// so null leaves them unchanged too.
 type UpdateShopItemRequest struct {
 	Name             *string         `json:"name,omitempty"`
 	Description      *string         `json:"description,omitempty"`
@@ -60,7 +102,16 @@ type UpdateShopItemRequest struct {
 	Metadata         json.RawMessage `json:"metadata,omitempty"`
 	IsActive         *bool           `json:"isActive,omitempty"`
 	IsLimitedEdition *bool           `json:"isLimitedEdition,omitempty"`
-	StockQuantity    *int            `json:"stockQuantity,omitempty"`
+	IsFeatured       *bool           `json:"isFeatured,omitempty"`
+	StockQuantity    Optional[int]   `json:"stockQuantity"`
+
+	AvailableFrom  Optional[time.Time] `json:"availableFrom"`
+	AvailableUntil Optional[time.Time] `json:"availableUntil"`
+}
+
+// BatchShopItemsRequest represents a request for several shop items at once
+type BatchShopItemsRequest struct {
+	ItemIDs []string `json:"itemIds"`
 }
 
 // UserInventoryItem represents an item owned by a user
@@ -87,14 +138,23 @@ type PurchaseRequest struct {
 	Quantity int    `json:"quantity"`
 }
 
+// Purchase statuses
+const (
+	PurchaseStatusCompleted   = "completed"
+	PurchaseStatusRefunded    = "refunded"
+	PurchaseStatusCompensated = "compensated" // credits returned after the item was deactivated; items kept
+)
+
 // PurchaseRecord represents a purchase transaction
 type PurchaseRecord struct {
-	PurchaseID   string    `json:"purchaseId" db:"purchase_id"`
-	UserID       string    `json:"userId" db:"user_id"`
-	ItemID       string    `json:"itemId" db:"item_id"`
-	Quantity     int       `json:"quantity" db:"quantity"`
-	CreditsSpent int       `json:"creditsSpent" db:"credits_spent"`
-	PurchasedAt  time.Time `json:"purchasedAt" db:"purchased_at"`
+	PurchaseID   string     `json:"purchaseId" db:"purchase_id"`
+	UserID       string     `json:"userId" db:"user_id"`
+	ItemID       string     `json:"itemId" db:"item_id"`
+	Quantity     int        `json:"quantity" db:"quantity"`
+	CreditsSpent int        `json:"creditsSpent" db:"credits_spent"`
+	PurchasedAt  time.Time  `json:"purchasedAt" db:"purchased_at"`
+	Status       string     `json:"status" db:"status"`
+	RefundedAt   *time.Time `json:"refundedAt,omitempty" db:"refunded_at"`
 }
 
 // PurchaseRecordWithItem represents purchase history with full item details
@@ -103,6 +163,44 @@ type PurchaseRecordWithItem struct {
 	ShopItem ShopItem `json:"item"`
 }
 
+// SpendingByType is a user's spending on one item type
+type SpendingByType struct {
+	ItemType     string `json:"itemType"`
+	Purchases    int    `json:"purchases"`
+	CreditsSpent int    `json:"creditsSpent"`
+}
+
+// SpendingSummary totals a user's purchases, leaving out refunded ones
+type SpendingSummary struct {
+	TotalCreditsSpent int              `json:"totalCreditsSpent"`
+	PurchaseCount     int              `json:"purchaseCount"`
+	ByType            []SpendingByType `json:"byType"`
+}
+
+// RefundPurchaseRequest represents an admin request to refund a purchase.
+// Force refunds even when some of the purchased items have already been used.
+type RefundPurchaseRequest struct {
+	PurchaseID string `json:"purchaseId"`
+	Force      bool   `json:"force"`
+}
+
+// RefundResult describes the balances affected by a refund
+type RefundResult struct {
+	Purchase        PurchaseRecord `json:"purchase"`
+	CreditsRefunded int            `json:"creditsRefunded"`
+	UserCredits     int            `json:"userCredits"`
+	ItemsRemoved    int            `json:"itemsRemoved"`
+	StockQuantity   *int           `json:"stockQuantity,omitempty"`
+}
+
+// CompensationResult summarises credits returned to recent purchasers of a deactivated item
+type CompensationResult struct {
+	RefundPercent   int `json:"refundPercent"`
+	WindowDays      int `json:"windowDays"`
+	UsersRefunded   int `json:"usersRefunded"`
+	CreditsReturned int `json:"creditsReturned"`
+}
+
 // EquipItemRequest represents a request to equip/unequip an item
 type EquipItemRequest struct {
 	InventoryID int  `json:"inventoryId"`
@@ -125,6 +223,15 @@ type UseItemResponse struct {
 	InventoryItem  *UserInventoryItem `json:"inventory,omitempty"`
 }
 
+// ItemPreview describes what using an inventory item would do, without using it
+type ItemPreview struct {
+	InventoryID int            `json:"inventoryId"`
+	Item        *ShopItem      `json:"item,omitempty"`
+	Usable      bool           `json:"usable"`
+	Description string         `json:"description"`
+	Projected   map[string]any `json:"projected,omitempty"`
+}
+
 // GenerateItemID creates a new unique ID for a shop item
 func GenerateItemID() string {
 	return uuid.New().String()
@@ -148,7 +255,10 @@ func NewShopItem(req CreateShopItemRequest) ShopItem {
 		Metadata:         req.Metadata,
 		IsActive:         true,
 		IsLimitedEdition: req.IsLimitedEdition,
+		IsFeatured:       req.IsFeatured,
 		StockQuantity:    req.StockQuantity,
+		AvailableFrom:    req.AvailableFrom,
+		AvailableUntil:   req.AvailableUntil,
 		CreatedAt:        now,
 		UpdatedAt:        now,
 	}
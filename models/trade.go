@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+const (
+	TradeStatusPending   = "pending"
+	TradeStatusAccepted  = "accepted"
+	TradeStatusDeclined  = "declined"
+	TradeStatusCancelled = "cancelled"
+)
+
+// Trade represents a two-sided item/credit exchange between players
+type Trade struct {
+	TradeID           int        `json:"tradeId" db:"trade_id"`
+	ProposerID        string     `json:"proposerId" db:"proposer_id"`
+	RecipientID       string     `json:"recipientId" db:"recipient_id"`
+	OfferedItemID     *string    `json:"offeredItemId,omitempty" db:"offered_item_id"`
+	OfferedQuantity   int        `json:"offeredQuantity" db:"offered_quantity"`
+	OfferedCredits    int        `json:"offeredCredits" db:"offered_credits"`
+	RequestedItemID   *string    `json:"requestedItemId,omitempty" db:"requested_item_id"`
+	RequestedQuantity int        `json:"requestedQuantity" db:"requested_quantity"`
+	RequestedCredits  int        `json:"requestedCredits" db:"requested_credits"`
+	Status            string     `json:"status" db:"status"`
+	CreatedAt         time.Time  `json:"createdAt" db:"created_at"`
+	RespondedAt       *time.Time `json:"respondedAt,omitempty" db:"responded_at"`
+}
+
+// ProposeTradeRequest represents the request body for proposing a trade
+type ProposeTradeRequest struct {
+	RecipientID       string  `json:"recipientId"`
+	OfferedItemID     *string `json:"offeredItemId,omitempty"`
+	OfferedQuantity   int     `json:"offeredQuantity,omitempty"`
+	OfferedCredits    int     `json:"offeredCredits,omitempty"`
+	RequestedItemID   *string `json:"requestedItemId,omitempty"`
+	RequestedQuantity int     `json:"requestedQuantity,omitempty"`
+	RequestedCredits  int     `json:"requestedCredits,omitempty"`
+}
+
+// RespondTradeRequest represents the request body for accepting, declining or cancelling a trade
+type RespondTradeRequest struct {
+	TradeID int    `json:"tradeId"`
+	Action  string `json:"action"` // "accept", "decline" or "cancel"
+}
@@ -21,9 +21,10 @@ type Credentials struct {
 }
 
 type UserSignupRequest struct {
-	Username string `json:"username"`
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	Username   string `json:"username"`
+	Email      string `json:"email"`
+	Password   string `json:"password"`
+	InviteCode string `json:"inviteCode,omitempty"` // required while signups are invite-only
 }
 
 type UserUpdateRequest struct {
@@ -31,6 +32,16 @@ type UserUpdateRequest struct {
 	Email    string `json:"email"`
 }
 
+// PrivacyUpdateRequest changes the user's privacy preferences
+type PrivacyUpdateRequest struct {
+	HideFromActivityFeed bool `json:"hideFromActivityFeed"`
+}
+
+// AvatarUpdateRequest sets the user's profile image; an empty URL clears it
+type AvatarUpdateRequest struct {
+	AvatarURL string `json:"avatarUrl"`
+}
+
 type User struct {
 	UserID         string    `json:"userId" db:"user_id"`
 	Username       string    `json:"username" db:"username"`
@@ -41,15 +52,29 @@ type User struct {
 	Points         int       `json:"points" db:"points"`
 	Level          int       `json:"level" db:"level"`
 	Credits        int       `json:"credits" db:"credits"`
+	AvatarURL      string    `json:"avatarUrl,omitempty" db:"avatar_url"`
+	HideFromFeed   bool      `json:"hideFromActivityFeed" db:"hide_from_activity_feed"`
 	CreatedAt      time.Time `json:"createdAt" db:"created_at"`
 	UpdatedAt      time.Time `json:"updatedAt" db:"updated_at"`
 }
 
 type UserSummary struct {
-	UserID   string `json:"userId" db:"user_id"`
-	Username string `json:"username" db:"username"`
-	Points   int    `json:"points" db:"points"`
-	Level    int    `json:"level" db:"level"`
+	UserID    string             `json:"userId" db:"user_id"`
+	Username  string             `json:"username" db:"username"`
+	Points    int                `json:"points" db:"points"`
+	Level     int                `json:"level" db:"level"`
+	AvatarURL string             `json:"avatarUrl,omitempty" db:"avatar_url"`
+	Cosmetics []EquippedCosmetic `json:"cosmetics,omitempty"`
+}
+
+// LevelProgress describes how far a user is through their current level
+type LevelProgress struct {
+	Level             int     `json:"level"`
+	Points            int     `json:"points"`
+	PointsIntoLevel   int     `json:"pointsIntoLevel"`
+	PointsToNextLevel int     `json:"pointsToNextLevel"`
+	PointsPerLevel    int     `json:"pointsPerLevel"` // points needed to clear the current level
+	ProgressPercent   float64 `json:"progressPercent"`
 }
 
 type UserDevice struct {
@@ -77,7 +102,7 @@ func NewUser(userSignup UserSignupRequest) (User, error) {
 	userkey := user.GenerateKey()
 	hashedPassword, hashErr := user.GenerateHash(userSignup.Password)
 	if hashErr != nil {
-		return User{}, fmt.Errorf("error hashing password %v", hashErr)
+		return User{}, fmt.Errorf("error hashing password %w", hashErr)
 	}
 	user = User{
 		UserID:         userkey,
@@ -96,6 +121,9 @@ func NewUser(userSignup UserSignupRequest) (User, error) {
 }
 
 func (user User) GenerateHash(password string) (string, error) {
+	if len(password) > MaxPasswordBytes {
+		return "", ErrPasswordTooLong
+	}
 	hashedPassword, hashErr := bcrypt.GenerateFromPassword([]byte(password), 8)
 	if hashErr != nil {
 		return "", fmt.Errorf("error hashing password %v", hashErr)
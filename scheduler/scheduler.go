@@ -1,27 +1,57 @@
 package scheduler
 
 import (
-	"encoding/json"
-	"fmt"
 	"log"
-	"math/rand"
-	"net/http"
+	"sync"
 	"time"
 
+	"github.com/color-game/api/colorapi"
 	"github.com/color-game/api/datastore"
 	"github.com/color-game/api/models"
 )
 
+// BackfillInterval spaces out color API calls during a backfill so the external API isn't flooded
+const BackfillInterval = 500 * time.Millisecond
+
 type Scheduler struct {
-	DailyColorRepo datastore.DailyColorRepository
-	ticker         *time.Ticker
-	done           chan bool
+	DailyColorRepo     datastore.DailyColorRepository
+	DailyScoreRepo     datastore.DailyScoreRepository
+	UserRepo           datastore.UserRepository
+	LeaderboardRepo    datastore.DailyLeaderboardRepository // when set, the finished day's standings are frozen at rollover
+	CuratedColorRepo   datastore.CuratedColorRepository     // when set, curated colors are used before random ones
+	ColorAPI           colorapi.Service
+	ScoreRetentionDays int // raw attempts older than this are archived nightly; 0 disables
+	ColorCandidates    int // random colors sampled per day, keeping the best-named; 1 or less samples once
+	ticker             *time.Ticker
+	done               chan bool
+
+	// How long after rollover the finished day is frozen, so late guesses in a grace window still count
+	LeaderboardFinalizeDelay time.Duration
+
+	// Derive each day's color from its date instead of calling the color API
+	DeterministicColors bool
+
+	mu        sync.RWMutex
+	nextRunAt time.Time
+	lastRunAt time.Time
+	lastErr   error
+}
+
+// Status is a snapshot of the scheduler's run history
+type Status struct {
+	NextRunAt time.Time
+	LastRunAt time.Time
+	LastError error
 }
 
-func NewScheduler(repo datastore.DailyColorRepository) *Scheduler {
+func NewScheduler(repo datastore.DailyColorRepository, scoreRepo datastore.DailyScoreRepository, userRepo datastore.UserRepository, colorAPI colorapi.Service, scoreRetentionDays int) *Scheduler {
 	return &Scheduler{
-		DailyColorRepo: repo,
-		done:           make(chan bool),
+		DailyColorRepo:     repo,
+		DailyScoreRepo:     scoreRepo,
+		UserRepo:           userRepo,
+		ColorAPI:           colorAPI,
+		ScoreRetentionDays: scoreRetentionDays,
+		done:               make(chan bool),
 	}
 }
 
@@ -33,10 +63,11 @@ func (s *Scheduler) Start() {
 	durationUntilMidnight := nextMidnight.Sub(now)
 
 	log.Printf("Scheduler started. Next daily color generation in %v", durationUntilMidnight)
+	s.setNextRun(nextMidnight)
 
 	// Wait until midnight, then generate first color
 	time.AfterFunc(durationUntilMidnight, func() {
-		s.GenerateDailyColor()
+		s.runDailyGeneration()
 
 		// After first run, schedule to run every 24 hours
 		s.ticker = time.NewTicker(24 * time.Hour)
@@ -44,7 +75,7 @@ func (s *Scheduler) Start() {
 			for {
 				select {
 				case <-s.ticker.C:
-					s.GenerateDailyColor()
+					s.runDailyGeneration()
 				case <-s.done:
 					return
 				}
@@ -62,6 +93,42 @@ func (s *Scheduler) Stop() {
 	log.Println("Scheduler stopped")
 }
 
+// Status returns when the scheduler last ran, whether that run failed, and when it runs next
+func (s *Scheduler) Status() Status {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return Status{
+		NextRunAt: s.nextRunAt,
+		LastRunAt: s.lastRunAt,
+		LastError: s.lastErr,
+	}
+}
+
+func (s *Scheduler) setNextRun(next time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextRunAt = next
+}
+
+// runDailyGeneration generates the daily color, runs nightly cleanup and records the outcome for Status
+func (s *Scheduler) runDailyGeneration() {
+	err := s.GenerateDailyColor()
+	if s.LeaderboardFinalizeDelay > 0 {
+		time.AfterFunc(s.LeaderboardFinalizeDelay, s.FinalizeLeaderboard)
+	} else {
+		s.FinalizeLeaderboard()
+	}
+	s.ArchiveOldScores()
+	s.PurgeExpiredRevokedTokens()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastRunAt = time.Now()
+	s.lastErr = err
+	s.nextRunAt = s.lastRunAt.Add(24 * time.Hour)
+}
+
 // GenerateDailyColor generates and saves a new daily color
 func (s *Scheduler) GenerateDailyColor() error {
 	log.Println("Generating daily color...")
@@ -76,60 +143,210 @@ func (s *Scheduler) GenerateDailyColor() error {
 		return nil
 	}
 
-	// Generate random RGB values
-	r := rand.Intn(256)
-	g := rand.Intn(256)
-	b := rand.Intn(256)
-
-	// Build the URL for thecolorapi.com
-	url := fmt.Sprintf("https://www.thecolorapi.com/scheme?rgb=%d,%d,%d&mode=analogic&count=6&format=json", r, g, b)
-
-	// Make HTTP request to the color API
-	resp, err := http.Get(url)
+	savedColor, err := s.CreateColorForDate(normalizedToday)
 	if err != nil {
-		log.Printf("Error fetching color from API: %v", err)
 		return err
 	}
-	defer resp.Body.Close()
 
-	// Check status code
-	if resp.StatusCode != http.StatusOK {
-		err := fmt.Errorf("color API returned status: %d", resp.StatusCode)
-		log.Printf("Error: %v", err)
-		return err
+	log.Printf("Successfully generated daily color: %s (RGB: %d,%d,%d) for %s",
+		savedColor.ColorName, savedColor.R, savedColor.G, savedColor.B,
+		savedColor.Date.Format("2006-01-02"))
+
+	return nil
+}
+
+// BackfillDailyColors generates a color for every day between from and to (inclusive) that doesn't
+// already have one. Existing colors are never overwritten. Calls to the color API are spaced
+// BackfillInterval apart, so large ranges take a while.
+func (s *Scheduler) BackfillDailyColors(from, to time.Time) models.DailyColorBackfillResult {
+	result := models.DailyColorBackfillResult{
+		Filled:  []string{},
+		Skipped: []string{},
+		Failed:  map[string]string{},
 	}
 
-	// Parse the response
-	var colorResponse models.ColorAPIResponse
-	if err := json.NewDecoder(resp.Body).Decode(&colorResponse); err != nil {
-		log.Printf("Error parsing color API response: %v", err)
-		return err
+	from = time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, from.Location())
+	calls := 0
+	for date := from; !date.After(to); date = date.AddDate(0, 0, 1) {
+		day := date.Format("2006-01-02")
+
+		existingColor, err := s.DailyColorRepo.GetByDate(date)
+		if err == nil && existingColor.ID != 0 {
+			result.Skipped = append(result.Skipped, day)
+			continue
+		}
+		if _, ok := err.(datastore.NoRowsError); err != nil && !ok {
+			result.Failed[day] = err.Error()
+			continue
+		}
+
+		if calls > 0 {
+			time.Sleep(BackfillInterval)
+		}
+		calls++
+
+		if _, err := s.CreateColorForDate(date); err != nil {
+			result.Failed[day] = err.Error()
+			continue
+		}
+		result.Filled = append(result.Filled, day)
+	}
+
+	log.Printf("Backfilled daily colors from %s to %s: %d filled, %d skipped, %d failed",
+		from.Format("2006-01-02"), to.Format("2006-01-02"), len(result.Filled), len(result.Skipped), len(result.Failed))
+
+	return result
+}
+
+// CreateColorForDate saves the color for date: a curated color when one applies, otherwise the color
+// derived from the date in deterministic mode, or else the best of ColorCandidates random palettes
+// from the color API. Past days only take curated colors scheduled for that exact date, so
+// backfills don't drain the queue meant for upcoming days. It is the one place a day's color is
+// chosen, for the nightly run, backfills and the admin endpoint alike.
+func (s *Scheduler) CreateColorForDate(date time.Time) (models.DailyColor, error) {
+	if s.CuratedColorRepo != nil {
+		now := time.Now()
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+		curatedColor, found, err := s.CuratedColorRepo.CreateDailyColorFromCurated(date, s.ColorAPI.ModeForDate(date), !date.Before(today))
+		if err != nil {
+			log.Printf("Error using curated color for %s, falling back to random: %v", date.Format("2006-01-02"), err)
+		} else if found {
+			return curatedColor, nil
+		}
+	}
+
+	if s.DeterministicColors {
+		dailyColor := models.DeterministicDailyColor(date)
+		dailyColor.SchemeMode = s.ColorAPI.ModeForDate(date)
+		savedColor, err := s.DailyColorRepo.Create(dailyColor)
+		if err != nil {
+			log.Printf("Error saving daily color to database: %v", err)
+			return models.DailyColor{}, err
+		}
+		return savedColor, nil
+	}
+
+	// Fetch a palette seeded with a random color
+	colorResponse, err := s.pickRandomScheme()
+	if err != nil {
+		log.Printf("Error fetching color from API: %v", err)
+		return models.DailyColor{}, err
 	}
 
 	// Use the seed color (the original random color)
 	seedColor := colorResponse.Seed
-	colorName := seedColor.Name.Value
 
-	// Create daily color entry
 	dailyColor := models.DailyColor{
-		Date:      normalizedToday,
-		ColorName: colorName,
-		R:         seedColor.RGB.R,
-		G:         seedColor.RGB.G,
-		B:         seedColor.RGB.B,
-		CreatedAt: time.Now(),
+		Date:       date,
+		ColorName:  seedColor.Name.Value,
+		R:          seedColor.RGB.R,
+		G:          seedColor.RGB.G,
+		B:          seedColor.RGB.B,
+		Source:     models.DailyColorSourceExternalAPI,
+		SchemeMode: s.ColorAPI.ModeForDate(date),
+		Difficulty: models.ClassifyColorDifficulty(seedColor.RGB.R, seedColor.RGB.G, seedColor.RGB.B),
+		CreatedAt:  time.Now(),
 	}
 
-	// Save to database
 	savedColor, err := s.DailyColorRepo.Create(dailyColor)
 	if err != nil {
 		log.Printf("Error saving daily color to database: %v", err)
-		return err
+		return models.DailyColor{}, err
 	}
 
-	log.Printf("Successfully generated daily color: %s (RGB: %d,%d,%d) for %s",
-		savedColor.ColorName, savedColor.R, savedColor.G, savedColor.B,
-		savedColor.Date.Format("2006-01-02"))
+	return savedColor, nil
+}
 
-	return nil
+// pickRandomScheme samples ColorCandidates random colors and keeps the one whose name fits best:
+// an exact name match if there is one, otherwise the smallest distance to the closest named color.
+// Failed samples are skipped; an error is returned only if every sample fails.
+func (s *Scheduler) pickRandomScheme() (models.ColorAPIResponse, error) {
+	candidates := s.ColorCandidates
+	if candidates < 1 {
+		candidates = 1
+	}
+
+	var best models.ColorAPIResponse
+	var firstErr error
+	found := false
+	for i := 0; i < candidates; i++ {
+		candidate, err := s.ColorAPI.GetRandomScheme()
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		if !found || namesBetter(candidate.Seed.Name, best.Seed.Name) {
+			best = candidate
+			found = true
+		}
+		if best.Seed.Name.ExactMatchName {
+			break
+		}
+	}
+
+	if !found {
+		return models.ColorAPIResponse{}, firstErr
+	}
+	return best, nil
+}
+
+// namesBetter reports whether a is a more recognisable color name than b
+func namesBetter(a, b models.ColorName) bool {
+	if a.ExactMatchName != b.ExactMatchName {
+		return a.ExactMatchName
+	}
+	return a.Distance < b.Distance
+}
+
+// FinalizeLeaderboard freezes yesterday's leaderboard so its final standings never shift
+func (s *Scheduler) FinalizeLeaderboard() {
+	if s.LeaderboardRepo == nil {
+		return
+	}
+
+	now := time.Now()
+	yesterday := time.Date(now.Year(), now.Month(), now.Day()-1, 0, 0, 0, 0, now.Location())
+
+	frozen, finalized, err := s.LeaderboardRepo.FinalizeDay(yesterday)
+	if err != nil {
+		log.Printf("Error finalizing leaderboard for %s: %v", yesterday.Format("2006-01-02"), err)
+		return
+	}
+	if !finalized {
+		log.Printf("Leaderboard for %s was already final", yesterday.Format("2006-01-02"))
+		return
+	}
+
+	log.Printf("Finalized leaderboard for %s with %d entries", yesterday.Format("2006-01-02"), frozen)
+}
+
+// ArchiveOldScores summarises and removes raw score attempts older than the retention window
+func (s *Scheduler) ArchiveOldScores() {
+	if s.ScoreRetentionDays <= 0 {
+		return
+	}
+
+	now := time.Now()
+	cutoff := time.Date(now.Year(), now.Month(), now.Day()-s.ScoreRetentionDays, 0, 0, 0, 0, now.Location())
+
+	archived, err := s.DailyScoreRepo.ArchiveScoresBefore(cutoff)
+	if err != nil {
+		log.Printf("Error archiving daily scores before %s: %v", cutoff.Format("2006-01-02"), err)
+		return
+	}
+
+	log.Printf("Archived %d daily score attempts before %s", archived, cutoff.Format("2006-01-02"))
+}
+
+// PurgeExpiredRevokedTokens drops denylisted tokens that have since expired on their own
+func (s *Scheduler) PurgeExpiredRevokedTokens() {
+	purged, err := s.UserRepo.DeleteExpiredRevokedTokens()
+	if err != nil {
+		log.Printf("Error purging expired revoked tokens: %v", err)
+		return
+	}
+
+	log.Printf("Purged %d expired revoked tokens", purged)
 }
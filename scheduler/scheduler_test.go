@@ -0,0 +1,147 @@
+package scheduler
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/color-game/api/models"
+)
+
+func TestNamesBetter(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b models.ColorName
+		want bool
+	}{
+		{"exact match beats a close one", models.ColorName{ExactMatchName: true, Distance: 0}, models.ColorName{Distance: 1}, true},
+		{"exact match beats any distance", models.ColorName{ExactMatchName: true, Distance: 50}, models.ColorName{Distance: 1}, true},
+		{"a close name loses to an exact match", models.ColorName{Distance: 1}, models.ColorName{ExactMatchName: true}, false},
+		{"smaller distance wins", models.ColorName{Distance: 3}, models.ColorName{Distance: 9}, true},
+		{"larger distance loses", models.ColorName{Distance: 9}, models.ColorName{Distance: 3}, false},
+		{"a tie is not better", models.ColorName{Distance: 4}, models.ColorName{Distance: 4}, false},
+		{"two exact matches tie", models.ColorName{ExactMatchName: true}, models.ColorName{ExactMatchName: true}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := namesBetter(tt.a, tt.b); got != tt.want {
+				t.Errorf("namesBetter(%+v, %+v) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+// fakeColorAPI hands out its responses in order, one per GetRandomScheme call
+type fakeColorAPI struct {
+	responses []models.ColorAPIResponse
+	errs      []error
+	calls     int
+}
+
+func (f *fakeColorAPI) GetRandomScheme() (models.ColorAPIResponse, error) {
+	i := f.calls
+	f.calls++
+	if i < len(f.errs) && f.errs[i] != nil {
+		return models.ColorAPIResponse{}, f.errs[i]
+	}
+	return f.responses[i], nil
+}
+
+func (f *fakeColorAPI) GetScheme(r, g, b int) (models.ColorAPIResponse, error) {
+	return models.ColorAPIResponse{}, errors.New("not used")
+}
+
+func (f *fakeColorAPI) GetSchemeWithMode(r, g, b int, mode string) (models.ColorAPIResponse, error) {
+	return models.ColorAPIResponse{}, errors.New("not used")
+}
+
+func (f *fakeColorAPI) ModeForDate(date time.Time) string {
+	return "monochrome"
+}
+
+func namedSeed(name string, exact bool, distance int) models.ColorAPIResponse {
+	var response models.ColorAPIResponse
+	response.Seed.Name = models.ColorName{Value: name, ExactMatchName: exact, Distance: distance}
+	return response
+}
+
+func TestPickRandomScheme(t *testing.T) {
+	unavailable := errors.New("color API unavailable")
+
+	tests := []struct {
+		name       string
+		candidates int
+		responses  []models.ColorAPIResponse
+		errs       []error
+		want       string
+		wantCalls  int
+		wantErr    bool
+	}{
+		{
+			name:       "a single candidate is used as is",
+			candidates: 1,
+			responses:  []models.ColorAPIResponse{namedSeed("Mud", false, 40)},
+			want:       "Mud",
+			wantCalls:  1,
+		},
+		{
+			name:       "zero candidates still samples once",
+			candidates: 0,
+			responses:  []models.ColorAPIResponse{namedSeed("Mud", false, 40)},
+			want:       "Mud",
+			wantCalls:  1,
+		},
+		{
+			name:       "the closest name wins",
+			candidates: 3,
+			responses:  []models.ColorAPIResponse{namedSeed("Mud", false, 40), namedSeed("Teal", false, 2), namedSeed("Sand", false, 15)},
+			want:       "Teal",
+			wantCalls:  3,
+		},
+		{
+			name:       "an exact match stops sampling early",
+			candidates: 3,
+			responses:  []models.ColorAPIResponse{namedSeed("Mud", false, 40), namedSeed("Red", true, 0), namedSeed("Teal", false, 2)},
+			want:       "Red",
+			wantCalls:  2,
+		},
+		{
+			name:       "failed samples are skipped",
+			candidates: 3,
+			responses:  []models.ColorAPIResponse{{}, namedSeed("Sand", false, 15), {}},
+			errs:       []error{unavailable, nil, unavailable},
+			want:       "Sand",
+			wantCalls:  3,
+		},
+		{
+			name:       "an error is returned only when every sample fails",
+			candidates: 2,
+			responses:  []models.ColorAPIResponse{{}, {}},
+			errs:       []error{unavailable, unavailable},
+			wantCalls:  2,
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := &fakeColorAPI{responses: tt.responses, errs: tt.errs}
+			s := &Scheduler{ColorAPI: api, ColorCandidates: tt.candidates}
+
+			got, err := s.pickRandomScheme()
+			if tt.wantErr {
+				if !errors.Is(err, unavailable) {
+					t.Fatalf("pickRandomScheme() error = %v, want %v", err, unavailable)
+				}
+			} else if err != nil {
+				t.Fatalf("pickRandomScheme() error = %v", err)
+			} else if got.Seed.Name.Value != tt.want {
+				t.Errorf("pickRandomScheme() picked %q, want %q", got.Seed.Name.Value, tt.want)
+			}
+			if api.calls != tt.wantCalls {
+				t.Errorf("color API called %d times, want %d", api.calls, tt.wantCalls)
+			}
+		})
+	}
+}
@@ -0,0 +1,28 @@
+// Package version holds build metadata injected at link time, e.g.
+//
+//	go build -ldflags "-X github.com/color-game/api/version.Commit=$(git rev-parse --short HEAD) -X github.com/color-game/api/version.BuildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+package version
+
+import "runtime"
+
+// Commit and BuildTime are set with -ldflags -X; they stay empty in plain `go build` and `go run` builds
+var (
+	Commit    string
+	BuildTime string
+)
+
+// Info is the build metadata reported by GET /v1/version
+type Info struct {
+	Commit    string `json:"commit"`
+	BuildTime string `json:"buildTime"`
+	GoVersion string `json:"goVersion"`
+}
+
+// Get returns the metadata of the running build
+func Get() Info {
+	return Info{
+		Commit:    Commit,
+		BuildTime: BuildTime,
+		GoVersion: runtime.Version(),
+	}
+}